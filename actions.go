@@ -0,0 +1,248 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"strings"
+)
+
+const (
+	actionEnsureClone   = "ensure-clone"
+	actionEnsureSession = "ensure-session"
+	actionApplyLayout   = "apply-layout"
+	actionRunHooks      = "run-hooks"
+	actionAttach        = "attach"
+	actionPrint         = "print"
+)
+
+// actionState is shared by the actions of a single chain run.
+type actionState struct {
+	cfg     *Config
+	project *Project
+	// created is set when ensure-session started a new session.
+	created bool
+	// existing overrides existing_session for the attach action.
+	existing string
+}
+
+type action func(state *actionState) error
+
+// errCancelled stops an action chain without reporting an error.
+var errCancelled = errors.New("cancelled")
+
+var actions = map[string]action{
+	actionEnsureClone:   ensureCloneAction,
+	actionEnsureSession: ensureSessionAction,
+	actionApplyLayout:   applyLayoutAction,
+	actionRunHooks:      runHooksAction,
+	actionAttach:        attachAction,
+	actionPrint:         printAction,
+}
+
+var defaultActions = []string{
+	actionEnsureClone,
+	actionEnsureSession,
+	actionApplyLayout,
+	actionRunHooks,
+	actionAttach,
+}
+
+// builtinModes are the action chains selectable with --mode. Chains defined
+// under modes in the config take precedence. --print always selects print.
+var builtinModes = map[string][]string{
+	"create": {actionEnsureClone, actionEnsureSession, actionApplyLayout, actionRunHooks},
+	"attach": {actionAttach},
+	"print":  {actionPrint},
+}
+
+// actionChain returns the actions to run after a project has been selected.
+func (cfg *Config) actionChain() ([]string, error) {
+	if *printPath {
+		return builtinModes["print"], nil
+	}
+	if *actionMode != "" {
+		if chain, ok := cfg.Modes[*actionMode]; ok {
+			return chain, nil
+		}
+		if chain, ok := builtinModes[*actionMode]; ok {
+			return chain, nil
+		}
+		return nil, fmt.Errorf("unknown mode %q", *actionMode)
+	}
+
+	if len(cfg.Actions) > 0 {
+		return cfg.Actions, nil
+	}
+	return defaultActions, nil
+}
+
+// runActions runs the configured action chain for project, leaving out the
+// actions listed in skip. With --detach the attach action is left out too.
+func runActions(cfg *Config, project *Project, skip ...string) error {
+	chain, err := cfg.actionChain()
+	if err != nil {
+		return err
+	}
+	if *detach {
+		skip = append(skip, actionAttach)
+	}
+
+	if project.Variant == "" && *variant != "" {
+		if _, ok := cfg.Variants[*variant]; !ok {
+			return fmt.Errorf("variant %q is not defined", *variant)
+		}
+		project.Variant = *variant
+	}
+	if project.Session == "" {
+		project.Session = cfg.sessionName(project)
+		if *forceNew {
+			if project.Session, err = newSessionName(cfg, project.Session); err != nil {
+				return err
+			}
+		} else if project.Variant == "" && cfg.usesTmux() && !withoutTmux() {
+			project.Session = mappedSession(project)
+		}
+	}
+	opensSession := contains(chain, actionEnsureSession) || contains(chain, actionAttach)
+	if opensSession && cfg.usesTmux() {
+		if withoutTmux() {
+			return openWithoutTmux(cfg, project)
+		}
+		if err := checkTmuxVersion(); err != nil {
+			return err
+		}
+	} else if opensSession {
+		name := cfg.backend().Command()
+		if _, err := exec.LookPath(name); err != nil {
+			return fmt.Errorf("%s not found: %w", name, err)
+		}
+	}
+
+	state := &actionState{cfg: cfg, project: project}
+	// nobody can answer without a terminal, such as when a launcher runs
+	// tmuxer --terminal
+	asks := !*spawnTerminal || hasTerminal()
+	if cfg.ExistingSession == existingAsk && asks && cfg.usesTmux() && contains(chain, actionAttach) && !contains(skip, actionAttach) {
+		if err := askExisting(state); errors.Is(err, errCancelled) {
+			return nil
+		} else if err != nil {
+			return err
+		}
+	}
+	for _, name := range chain {
+		if contains(skip, name) {
+			continue
+		}
+
+		fn, ok := actions[name]
+		if !ok {
+			return fmt.Errorf("unknown action %q, expected one of: %s", name, strings.Join(actionNames(), ", "))
+		}
+		if err := fn(state); errors.Is(err, errCancelled) {
+			return nil
+		} else if err != nil {
+			return fmt.Errorf("%s: %w", name, err)
+		}
+	}
+
+	if *detach && state.created {
+		notify(cfg, "Created session %s in the background", project.Session)
+	}
+	return nil
+}
+
+func actionNames() []string {
+	return append(append([]string{}, defaultActions...), actionPrint)
+}
+
+func ensureCloneAction(state *actionState) error {
+	if state.project.Repo == "" {
+		return nil
+	}
+	if state.project.FullPath == state.project.Repo {
+		if err := placeRepo(state.cfg, state.project); err != nil {
+			return err
+		}
+	}
+	if _, err := os.Stat(state.project.FullPath); err == nil {
+		return nil
+	}
+	return cloneRepo(state.project.Repo, state.project.FullPath)
+}
+
+func ensureSessionAction(state *actionState) error {
+	if state.cfg.ConfirmCreate && (!*spawnTerminal || hasTerminal()) {
+		if err := confirmCreate(state.cfg, state.project); err != nil {
+			return err
+		}
+	}
+
+	created, err := ensureSession(state.cfg, state.project)
+	state.created = created
+	if err != nil || !created || state.project.Remote != nil {
+		return err
+	}
+
+	command := state.cfg.defaultCommand(state.project)
+	if command == "" {
+		return nil
+	}
+	// a command given for the first window of the layout wins
+	if layout, _ := state.cfg.layoutFor(state.project); layout != nil && len(layout.Windows) > 0 && (layout.Windows[0].Command != "" || layout.Windows[0].Editor) {
+		return nil
+	}
+	if command, err = renderTemplate(command, state.project); err != nil {
+		return err
+	}
+	return state.cfg.backend().SendCommand(state.project.Session, command)
+}
+
+func applyLayoutAction(state *actionState) error {
+	if !state.created || state.project.Remote != nil {
+		return nil
+	}
+	if _, ok := state.cfg.backend().(layoutBackend); ok {
+		return nil
+	}
+
+	layout, err := state.cfg.layoutFor(state.project)
+	if err != nil || layout == nil {
+		return err
+	}
+	if layout, err = layout.render(state.cfg, state.project); err != nil {
+		return err
+	}
+	return state.cfg.backend().ApplyLayout(state.project, layout)
+}
+
+func runHooksAction(state *actionState) error {
+	if state.project.Remote != nil {
+		return nil
+	}
+	env, err := state.cfg.projectEnv(state.project)
+	if err != nil {
+		return err
+	}
+	if state.created {
+		if err := state.cfg.runHooks(state.cfg.Hooks.OnCreate, state.project, env); err != nil {
+			return err
+		}
+	}
+	return state.cfg.runHooks(state.cfg.Hooks.OnOpen, state.project, env)
+}
+
+func printAction(state *actionState) error {
+	fmt.Println(state.project.FullPath)
+	return nil
+}
+
+func contains(list []string, s string) bool {
+	for _, v := range list {
+		if v == s {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,78 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"path/filepath"
+	"strings"
+)
+
+// runAdoptCommand renames the current tmux session after the project its
+// active pane is in and records it in the history, turning a hand-made
+// session into one tmuxer knows about.
+func runAdoptCommand(cfg *Config, _ []string) error {
+	if !tmuxClient.IsInsideTmux() {
+		return errors.New("adopt must be run inside a tmux session")
+	}
+
+	current, err := tmuxClient.CurrentSession()
+	if err != nil {
+		return err
+	}
+	dir, err := tmuxOutput("display-message", "-p", "#{pane_current_path}")
+	if err != nil {
+		return err
+	}
+
+	projects, err := findProjectDirectories(cfg)
+	if err != nil {
+		return err
+	}
+
+	project := projectContaining(projects, hostPath(dir))
+	if project == nil {
+		return fmt.Errorf("%s is not inside a known project", dir)
+	}
+	project.Session = cfg.sessionName(project)
+
+	if project.Session != current {
+		exists, err := tmuxClient.HasSession(project.Session)
+		if err != nil {
+			return err
+		}
+		if exists {
+			return fmt.Errorf("session %q already exists", project.Session)
+		}
+		if err := runTmuxCommand("rename-session", "-t", current, project.Session); err != nil {
+			return err
+		}
+		fmt.Printf("Renamed session %s to %s\n", current, project.Session)
+	}
+
+	if err := markSession(project); err != nil {
+		return err
+	}
+	if err := recordSession(project); err != nil {
+		return err
+	}
+	return recordHistory(historyEventOpen, project)
+}
+
+// projectContaining returns the innermost project whose directory contains
+// dir.
+func projectContaining(projects []*Project, dir string) *Project {
+	var best *Project
+	for _, project := range projects {
+		if project.Remote != nil {
+			continue
+		}
+		rel, err := filepath.Rel(project.FullPath, dir)
+		if err != nil || rel == ".." || strings.HasPrefix(rel, ".."+string(filepath.Separator)) {
+			continue
+		}
+		if best == nil || len(project.FullPath) > len(best.FullPath) {
+			best = project
+		}
+	}
+	return best
+}
@@ -0,0 +1,45 @@
+package main
+
+import (
+	"log/slog"
+	"os"
+)
+
+// aliasMarker is listed as the marker of projects defined under alias.
+const aliasMarker = "alias"
+
+// aliasProjects returns the directories under alias as projects named after
+// their alias, leaving out directories that do not exist.
+func (cfg *Config) aliasProjects() []*Project {
+	var projects []*Project
+	for _, name := range sortedKeys(cfg.Aliases) {
+		if project := cfg.aliasProject(name); project != nil {
+			projects = append(projects, project)
+		}
+	}
+	return projects
+}
+
+// aliasProject returns the project of the alias name, or nil when there is
+// no such alias or its directory does not exist.
+func (cfg *Config) aliasProject(name string) *Project {
+	dir, ok := cfg.Aliases[name]
+	if !ok {
+		return nil
+	}
+	dir, err := normalizePath(dir)
+	if err != nil {
+		slog.Warn("invalid alias", "alias", name, "err", err)
+		return nil
+	}
+	if _, err := os.Stat(dir); err != nil {
+		slog.Info("skipping alias of missing directory", "alias", name, "path", dir)
+		return nil
+	}
+	project, err := newProject(name, dir)
+	if err != nil {
+		return nil
+	}
+	project.Markers = []string{aliasMarker}
+	return project
+}
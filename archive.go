@@ -0,0 +1,261 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"log/slog"
+	"os"
+	"path/filepath"
+)
+
+const (
+	archivedFile = "archived.json"
+	// archivedMarker is listed as the marker of archived projects found
+	// through archived.json rather than a base, such as those moved to an
+	// archive_dir outside of the bases.
+	archivedMarker = "archived"
+)
+
+// runArchiveCommand kills the session of a project, moves its directory into
+// archive_dir when that is set and marks it as archived, which hides it from
+// the picker unless --include-archived is given.
+func runArchiveCommand(cfg *Config, args []string) error {
+	if len(args) != 1 {
+		return errors.New("usage: tmuxer archive <project>")
+	}
+
+	projects, err := findProjectDirectories(cfg)
+	if err != nil {
+		return err
+	}
+	project := findProjectByName(projects, args[0])
+	if project == nil {
+		return fmt.Errorf("project %q not found", args[0])
+	}
+	if project.Remote != nil || project.Repo != "" {
+		return fmt.Errorf("project %q has no local directory to archive", args[0])
+	}
+
+	if cfg.usesTmux() && !withoutTmux() {
+		project.Session = cfg.sessionName(project)
+		session := mappedSession(project)
+		exists, err := tmuxClient.HasSession(session)
+		if err != nil {
+			return err
+		}
+		if exists {
+			if err := runTmuxCommand("kill-session", "-t", "="+session); err != nil {
+				return err
+			}
+			fmt.Printf("Killed session %s\n", session)
+		}
+	}
+
+	from := ""
+	if cfg.ArchiveDir != "" {
+		dir, err := normalizePath(cfg.ArchiveDir)
+		if err != nil {
+			return err
+		}
+		from = project.FullPath
+		if project, err = moveProject(cfg, project, filepath.Join(dir, filepath.Base(project.FullPath))); err != nil {
+			return err
+		}
+	}
+
+	archived, err := loadArchived()
+	if err != nil {
+		return err
+	}
+	if archivedIndex(archived, project.FullPath) < 0 {
+		archived = append(archived, &archivedProject{Path: project.FullPath, From: from})
+	}
+	if err := saveArchived(archived); err != nil {
+		return err
+	}
+	fmt.Printf("Archived %s\n", project.Name)
+	return nil
+}
+
+// runUnarchiveCommand lists an archived project in the picker again. It
+// stays where it was archived to, unless that is outside of the bases; then
+// it is moved back to where it was archived from.
+func runUnarchiveCommand(cfg *Config, args []string) error {
+	if len(args) != 1 {
+		return errors.New("usage: tmuxer unarchive <project>")
+	}
+
+	projects, err := findProjectDirectories(cfg)
+	if err != nil {
+		return err
+	}
+	project := findProjectByName(projects, args[0])
+	if project == nil {
+		return fmt.Errorf("project %q not found", args[0])
+	}
+
+	archived, err := loadArchived()
+	if err != nil {
+		return err
+	}
+	i := archivedIndex(archived, project.FullPath)
+	if i < 0 {
+		return fmt.Errorf("project %q is not archived", args[0])
+	}
+	entry := archived[i]
+	if err := saveArchived(append(archived[:i:i], archived[i+1:]...)); err != nil {
+		return err
+	}
+	// only archived.json knows about a project no base finds
+	if entry.From != "" && contains(project.Markers, archivedMarker) {
+		if _, err := moveProject(cfg, project, entry.From); err != nil {
+			return err
+		}
+	}
+	fmt.Printf("Unarchived %s\n", project.Name)
+	return nil
+}
+
+// archivedProject is an entry of archived.json.
+type archivedProject struct {
+	Path string `json:"path"`
+	// From is where the project was moved to archive_dir from.
+	From string `json:"from,omitempty"`
+}
+
+// UnmarshalJSON also reads the plain paths archived.json used to hold.
+func (a *archivedProject) UnmarshalJSON(data []byte) error {
+	if err := json.Unmarshal(data, &a.Path); err == nil {
+		return nil
+	}
+	type plain archivedProject
+	return json.Unmarshal(data, (*plain)(a))
+}
+
+// archivedIndex returns the index of the project at dir in archived, or -1.
+func archivedIndex(archived []*archivedProject, dir string) int {
+	for i, a := range archived {
+		if a.Path == dir {
+			return i
+		}
+	}
+	return -1
+}
+
+// archivedFilter returns a function reporting whether a project is to be
+// left out of the picker for being archived.
+func archivedFilter() (func(*Project) bool, error) {
+	if *includeArchived {
+		return func(*Project) bool { return false }, nil
+	}
+	archived, err := loadArchived()
+	if err != nil {
+		return nil, err
+	}
+	set := make(map[string]bool, len(archived))
+	for _, a := range archived {
+		set[a.Path] = true
+	}
+	return func(project *Project) bool {
+		return set[project.FullPath]
+	}, nil
+}
+
+// withoutArchived returns projects without the archived ones, unless
+// --include-archived is given.
+func withoutArchived(projects []*Project) ([]*Project, error) {
+	archived, err := archivedFilter()
+	if err != nil {
+		return nil, err
+	}
+	var res []*Project
+	for _, project := range projects {
+		if !archived(project) {
+			res = append(res, project)
+		}
+	}
+	return res, nil
+}
+
+// archivedProjects returns the archived projects as projects, leaving out
+// directories that no longer exist, so they are found wherever they were
+// archived to.
+func archivedProjects() []*Project {
+	archived, err := loadArchived()
+	if err != nil {
+		slog.Warn("failed to load archived projects", "err", err)
+		return nil
+	}
+
+	var projects []*Project
+	for _, a := range archived {
+		if _, err := os.Stat(a.Path); err != nil {
+			slog.Info("skipping missing archived project", "path", a.Path)
+			continue
+		}
+		project, err := newProject(filepath.Base(a.Path), a.Path)
+		if err != nil {
+			continue
+		}
+		project.Markers = []string{archivedMarker}
+		projects = append(projects, project)
+	}
+	return projects
+}
+
+func archivedPath() (string, error) {
+	dir, err := dataDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, archivedFile), nil
+}
+
+// loadArchived returns the archived projects.
+func loadArchived() ([]*archivedProject, error) {
+	p, err := archivedPath()
+	if err != nil {
+		return nil, err
+	}
+	data, err := os.ReadFile(p)
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	var archived []*archivedProject
+	if err := json.Unmarshal(data, &archived); err != nil {
+		return nil, fmt.Errorf("corrupt archived projects file %s: %w", p, err)
+	}
+	return archived, nil
+}
+
+func saveArchived(archived []*archivedProject) error {
+	p, err := archivedPath()
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(p), 0o755); err != nil {
+		return err
+	}
+	data, err := json.MarshalIndent(archived, "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(p, data, 0o644)
+}
+
+func moveArchived(old, moved *Project) error {
+	archived, err := loadArchived()
+	if err != nil || len(archived) == 0 {
+		return err
+	}
+	for _, a := range archived {
+		a.Path, _ = movedPath(a.Path, old.FullPath, moved.FullPath)
+	}
+	return saveArchived(archived)
+}
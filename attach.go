@@ -0,0 +1,98 @@
+package main
+
+import (
+	"fmt"
+	"log/slog"
+	"os"
+	"strings"
+)
+
+// What the attach action does when the session of a project already
+// existed, see Config.ExistingSession.
+const (
+	existingSwitch    = "switch"
+	existingAttach    = "attach"
+	existingNewWindow = "new-window-in-session"
+	existingAsk       = "ask"
+)
+
+var existingBehaviors = []string{existingSwitch, existingAttach, existingNewWindow, existingAsk}
+
+// askExisting asks what to do about the existing session of the project
+// when existing_session is ask, before any action runs. Creating a new
+// session changes the session name, the other answers set the behavior of
+// the attach action.
+func askExisting(state *actionState) error {
+	project := state.project
+	exists, err := state.cfg.backend().HasSession(project.Session)
+	if err != nil || !exists {
+		return err
+	}
+
+	answer, err := prompt(fmt.Sprintf("Session %s exists: [s]witch to it, open a new [w]indow in it or create a [n]ew session? [S/w/n] ", project.Session))
+	if err != nil {
+		return err
+	}
+	switch strings.ToLower(answer) {
+	case "", "s":
+		state.existing = existingSwitch
+	case "w":
+		state.existing = existingNewWindow
+	case "n":
+		project.Session, err = newSessionName(state.cfg, project.Session)
+		return err
+	default:
+		return errCancelled
+	}
+	return nil
+}
+
+// attachAction attaches to the session of project according to the
+// existing_session setting. Sessions just created, and sessions of backends
+// other than tmux, are always switched to.
+func attachAction(state *actionState) error {
+	cfg, project := state.cfg, state.project
+	behavior := state.existing
+	if behavior == "" {
+		behavior = cfg.ExistingSession
+	}
+	// attach would nest the session in this terminal rather than the new one
+	if state.created || !cfg.usesTmux() || behavior == "" || (*spawnTerminal && behavior == existingAttach) {
+		behavior = existingSwitch
+	}
+
+	if err := recordHistory(historyEventOpen, project); err != nil {
+		slog.Warn("failed to record history", "err", err)
+	}
+	switch behavior {
+	case existingAttach:
+		// attach in this terminal even from inside tmux, nesting the session
+		slog.Debug("running tmux", "args", []string{"attach-session", "-t", project.Session})
+		cmd := tmuxCommand("attach-session", "-t", project.Session)
+		cmd.Env = append(os.Environ(), "TMUX=")
+		cmd.Stdin, cmd.Stdout, cmd.Stderr = os.Stdin, os.Stdout, os.Stderr
+		return runCommand(cmd)
+	case existingNewWindow:
+		dir, command := windowStart(project)
+		args := append([]string{"-t", project.Session + ":", "-c", dir}, command...)
+		if err := runTmuxCommand("new-window", args...); err != nil {
+			return err
+		}
+	}
+	if *spawnTerminal && cfg.usesTmux() {
+		return spawnTerminalWindow(cfg, project)
+	}
+	return cfg.backend().Attach(project.Session)
+}
+
+// newSessionName returns the first of session-2, session-3 and so on that
+// is not taken, for a second independent session of the same project.
+func newSessionName(cfg *Config, session string) (string, error) {
+	for n := 2; ; n++ {
+		name := fmt.Sprintf("%s-%d", session, n)
+		exists, err := cfg.backend().HasSession(name)
+		if err != nil || !exists {
+			return name, err
+		}
+	}
+}
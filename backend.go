@@ -0,0 +1,208 @@
+package main
+
+import (
+	"fmt"
+	"log/slog"
+	"os"
+	"os/exec"
+	"strings"
+)
+
+// Backend is the terminal multiplexer project sessions are opened in. All
+// of them share project discovery, layouts and the action chain. Commands
+// working on running sessions, such as windows, sync or gc, need tmux.
+type Backend interface {
+	// Command is the program the backend drives.
+	Command() string
+	HasSession(name string) (bool, error)
+	// NewSession starts the session of project in dir with the environment
+	// env, running command in its first window when given. It reports false
+	// when the session was created by someone else in the meantime.
+	NewSession(project *Project, dir string, command []string, env []string) (bool, error)
+	// ApplyLayout creates the windows and panes of layout in the session
+	// of project, reusing the first window started by NewSession.
+	ApplyLayout(project *Project, layout *Layout) error
+	// SendCommand runs command in the first window of the session.
+	SendCommand(session, command string) error
+	// Attach attaches to the session, or switches to it.
+	Attach(session string) error
+}
+
+// backends maps the names accepted by the backend option to backends.
+var backends = map[string]Backend{
+	"kitty":   kittyBackend{},
+	"tmux":    tmuxBackend{},
+	"wezterm": weztermBackend{},
+	"zellij":  zellijBackend{},
+}
+
+// layoutBackend is implemented by backends that create a session along
+// with its layout, which ensure-session then does instead of apply-layout.
+type layoutBackend interface {
+	NewLayoutSession(project *Project, dir string, env []string, layout *Layout) (bool, error)
+}
+
+// backend returns the configured backend. Without one it is the multiplexer
+// tmuxer runs in, tmux by default.
+func (cfg *Config) backend() Backend {
+	if b, ok := backends[cfg.Backend]; ok {
+		return b
+	}
+	if !tmuxClient.IsInsideTmux() && os.Getenv("ZELLIJ") != "" {
+		return zellijBackend{}
+	}
+	return tmuxBackend{}
+}
+
+// usesTmux reports whether sessions are opened in tmux.
+func (cfg *Config) usesTmux() bool {
+	_, ok := cfg.backend().(tmuxBackend)
+	return ok
+}
+
+// ensureSession creates a detached session for project unless one exists and
+// reports whether it was created.
+func ensureSession(cfg *Config, project *Project) (bool, error) {
+	unlock, err := lockSession(project.Session)
+	if err != nil {
+		return false, err
+	}
+	defer unlock()
+
+	backend := cfg.backend()
+	exists, err := backend.HasSession(project.Session)
+	if err != nil || exists {
+		return false, err
+	}
+
+	env, err := cfg.projectEnv(project)
+	if err != nil {
+		return false, err
+	}
+	dir, command := windowStart(project)
+	var created bool
+	if lb, ok := backend.(layoutBackend); ok && project.Remote == nil {
+		var layout *Layout
+		if layout, err = cfg.layoutFor(project); err == nil && layout != nil {
+			layout, err = layout.render(cfg, project)
+		}
+		if err != nil {
+			return false, err
+		}
+		created, err = lb.NewLayoutSession(project, dir, env, layout)
+	} else {
+		created, err = backend.NewSession(project, dir, command, env)
+	}
+	if err != nil || !created {
+		return false, err
+	}
+	if err := recordHistory(historyEventCreate, project); err != nil {
+		slog.Warn("failed to record history", "err", err)
+	}
+	if err := recordSession(project); err != nil {
+		slog.Warn("failed to record session", "err", err)
+	}
+	return true, nil
+}
+
+type tmuxBackend struct{}
+
+func (tmuxBackend) Command() string { return "tmux" }
+
+func (tmuxBackend) HasSession(name string) (bool, error) { return tmuxClient.HasSession(name) }
+
+func (tmuxBackend) NewSession(project *Project, dir string, command []string, env []string) (bool, error) {
+	public, secret := splitSecrets(env)
+	args := []string{"-d", "-s", project.Session, "-c", dir}
+	sessionEnv := tmuxSupports(featureSessionEnv)
+	if sessionEnv {
+		for _, kv := range public {
+			args = append(args, "-e", kv)
+		}
+	}
+	// secrets are set from a file once the session exists, as is all of env
+	// before tmux 3.2, which has no new-session -e; the first pane is then
+	// started again to see them rather than running the command twice
+	respawn := len(secret) > 0 || (len(env) > 0 && !sessionEnv)
+	if !respawn {
+		args = append(args, command...)
+	}
+
+	// like new-session -A, a session created by someone else in the
+	// meantime is used as is
+	args = append([]string{"new-session"}, args...)
+	slog.Debug("running tmux", "args", redact(args))
+	output, err := commandCombinedOutput(tmuxCommand(args...))
+	if err != nil {
+		if exists, _ := tmuxClient.HasSession(project.Session); exists {
+			slog.Debug("session already exists", "session", project.Session)
+			return false, nil
+		}
+		return false, fmt.Errorf("failed to create session: %s: %w", strings.TrimSpace(string(output)), err)
+	}
+	if err := markSession(project); err != nil {
+		return true, err
+	}
+
+	// set-environment makes the variables available to windows and panes
+	// created later on as well
+	if err := setSessionEnv(project.Session, env); err != nil {
+		return true, err
+	}
+	if respawn {
+		args := append([]string{"-k", "-t", project.Session + ":", "-c", dir}, command...)
+		if err := runTmuxCommand("respawn-pane", args...); err != nil {
+			return true, err
+		}
+	}
+	return true, nil
+}
+
+// setSessionEnv sets the NAME=value pairs of env in the environment of
+// session through a file sourced by tmux, keeping secrets out of the
+// arguments of tmux.
+func setSessionEnv(session string, env []string) error {
+	if len(env) == 0 {
+		return nil
+	}
+	var b strings.Builder
+	for _, kv := range env {
+		name, value, _ := strings.Cut(kv, "=")
+		fmt.Fprintf(&b, "set-environment -t %s %s %s\n", tmuxQuote("="+session), tmuxQuote(name), tmuxQuote(value))
+	}
+	file, err := writeSecretFile(b.String())
+	if err != nil {
+		return fmt.Errorf("failed to set the session environment: %w", err)
+	}
+	defer os.Remove(file)
+	return runTmuxCommand("source-file", tmuxPath(file))
+}
+
+// tmuxQuoteReplacer escapes what tmux interprets in double-quoted strings
+// of its configuration.
+var tmuxQuoteReplacer = strings.NewReplacer(`\`, `\\`, `"`, `\"`, `$`, `\$`, "\n", `\n`, "\r", `\r`, "\t", `\t`)
+
+// tmuxQuote quotes s as a string of the tmux configuration.
+func tmuxQuote(s string) string {
+	return `"` + tmuxQuoteReplacer.Replace(s) + `"`
+}
+
+func (tmuxBackend) ApplyLayout(project *Project, layout *Layout) error { return layout.Apply(project) }
+
+func (tmuxBackend) SendCommand(session, command string) error { return sendKeys(session+":", command) }
+
+func (tmuxBackend) Attach(session string) error { return attachSession(session) }
+
+// runBackend runs a command of a backend's program and returns its trimmed
+// standard output.
+func runBackend(name string, args ...string) (string, error) {
+	slog.Debug("running "+name, "args", redact(args))
+	output, err := commandOutput(exec.Command(name, args...))
+	if err != nil {
+		if exit, ok := err.(*exec.ExitError); ok && len(exit.Stderr) > 0 {
+			return "", fmt.Errorf("%s %s: %s", name, args[0], strings.TrimSpace(string(exit.Stderr)))
+		}
+		return "", fmt.Errorf("%s %s: %w", name, args[0], err)
+	}
+	return strings.TrimSpace(string(output)), nil
+}
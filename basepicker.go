@@ -0,0 +1,122 @@
+package main
+
+import (
+	"fmt"
+	"log/slog"
+	"strings"
+
+	"github.com/bmatcuk/doublestar/v4"
+	"github.com/k1ng440/tmuxer/third_party/go-fuzzyfinder"
+)
+
+// baseGroup is an entry of the first stage of the picker with pick_base: a
+// base and the projects found in it.
+type baseGroup struct {
+	base     *Base
+	projects int
+}
+
+func (g *baseGroup) String() string {
+	if g.base == nil {
+		return "other"
+	}
+	root := g.base.Path
+	if !isRemoteBase(root) {
+		root, _ = doublestar.SplitPattern(root)
+		root = tildePath(root)
+	}
+	if g.base.Prefix != "" {
+		root += "  " + g.base.Prefix
+	}
+	return root
+}
+
+// pickBaseFirst lets the user pick a base when pick_base is set and returns
+// only the projects and stale projects of that base. Projects of no base,
+// such as bookmarks, are grouped under other. Nothing is asked with a
+// single base, --select or without a terminal.
+func (cfg *Config) pickBaseFirst(projects, stale []*Project) ([]*Project, []*Project, error) {
+	if !cfg.PickBase || *selectName != "" || !hasTerminal() {
+		return projects, stale, nil
+	}
+
+	byBase := make(map[*Base]*baseGroup)
+	var groups []*baseGroup
+	add := func(b *Base) *baseGroup {
+		if g, ok := byBase[b]; ok {
+			return g
+		}
+		g := &baseGroup{base: b}
+		byBase[b] = g
+		groups = append(groups, g)
+		return g
+	}
+	// in the order of the config, with other last
+	for _, b := range cfg.ProjectBase {
+		add(b)
+	}
+	var other *baseGroup
+	for _, p := range append(append([]*Project{}, projects...), stale...) {
+		if p.Base == nil {
+			if other == nil {
+				other = &baseGroup{}
+			}
+			other.projects++
+			continue
+		}
+		add(p.Base).projects++
+	}
+	if other != nil {
+		groups = append(groups, other)
+	}
+
+	var shown []*baseGroup
+	for _, g := range groups {
+		if g.projects > 0 {
+			shown = append(shown, g)
+		}
+	}
+	if len(shown) < 2 {
+		return projects, stale, nil
+	}
+
+	idx, err := fuzzyfinder.Find(
+		shown,
+		func(i int) string {
+			return fmt.Sprintf("%s  (%d projects)", shown[i], shown[i].projects)
+		},
+		fuzzyfinder.WithPromptString("base> "),
+		fuzzyfinder.WithPreviewWindow(func(i, _, _ int) string {
+			if i < 0 {
+				return ""
+			}
+			var names []string
+			for _, p := range projects {
+				if p.Base == shown[i].base {
+					names = append(names, cfg.projectLabel(p))
+				}
+			}
+			return strings.Join(names, "\n")
+		}),
+	)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	chosen := shown[idx].base
+	slog.Debug("picked base", "base", shown[idx].String())
+	inBase := func(list []*Project) []*Project {
+		var res []*Project
+		for _, p := range list {
+			if p.Base == chosen {
+				res = append(res, p)
+			}
+		}
+		return res
+	}
+	projects, stale = inBase(projects), inBase(stale)
+	if len(projects) == 0 {
+		projects, stale = stale, nil
+	}
+	return projects, stale, nil
+}
@@ -0,0 +1,152 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"os"
+	"path/filepath"
+
+	"github.com/bmatcuk/doublestar/v4"
+	"github.com/k1ng440/tmuxer/third_party/go-fuzzyfinder"
+)
+
+const lastBasesFile = "bases.json"
+
+// baseChoice is a directory new projects can be created or cloned into.
+type baseChoice struct {
+	Root     string
+	Projects int
+	Free     uint64
+	// FreeKnown is false when the free space could not be determined.
+	FreeKnown bool
+}
+
+// chooseBase returns the directory new projects go into. With more than one
+// local base the user picks one; the previous choice for the same context
+// (such as "clone") is listed first so it is selected by default.
+func chooseBase(cfg *Config, context string, projects []*Project) (string, error) {
+	var (
+		choices []*baseChoice
+		byRoot  = make(map[string]*baseChoice)
+	)
+	for _, b := range cfg.ProjectBase {
+		if isRemoteBase(b.Path) {
+			continue
+		}
+		root, _ := doublestar.SplitPattern(b.Path)
+		if _, ok := byRoot[root]; ok {
+			continue
+		}
+		choice := &baseChoice{Root: root}
+		free, err := diskFree(root)
+		if err != nil {
+			slog.Debug("failed to get free space", "root", root, "err", err)
+		}
+		choice.Free, choice.FreeKnown = free, err == nil
+		byRoot[root] = choice
+		choices = append(choices, choice)
+	}
+
+	switch len(choices) {
+	case 0:
+		return "", fmt.Errorf("no local project base to %s into", context)
+	case 1:
+		return choices[0].Root, nil
+	}
+
+	for _, project := range projects {
+		if project.Base == nil {
+			continue
+		}
+		root, _ := doublestar.SplitPattern(project.Base.Path)
+		if choice, ok := byRoot[root]; ok {
+			choice.Projects++
+		}
+	}
+
+	last := loadLastBases()
+	for i, choice := range choices {
+		if choice.Root == last[context] {
+			choices[0], choices[i] = choices[i], choices[0]
+			break
+		}
+	}
+
+	if !hasTerminal() {
+		slog.Info("no terminal for the picker, using the last base", "context", context, "base", choices[0].Root)
+		return choices[0].Root, nil
+	}
+	idx, err := fuzzyfinder.Find(
+		choices,
+		func(i int) string {
+			free := "unknown"
+			if choices[i].FreeKnown {
+				free = formatBytes(choices[i].Free)
+			}
+			return fmt.Sprintf("%s  (%d projects, %s free)", choices[i].Root, choices[i].Projects, free)
+		},
+		fuzzyfinder.WithPromptString(fmt.Sprintf("%s into> ", context)),
+	)
+	if err != nil {
+		return "", err
+	}
+
+	last[context] = choices[idx].Root
+	if err := saveLastBases(last); err != nil {
+		slog.Warn("failed to remember base", "err", err)
+	}
+	return choices[idx].Root, nil
+}
+
+func formatBytes(n uint64) string {
+	const unit = 1024
+	if n < unit {
+		return fmt.Sprintf("%d B", n)
+	}
+	div, exp := uint64(unit), 0
+	for m := n / unit; m >= unit; m /= unit {
+		div *= unit
+		exp++
+	}
+	return fmt.Sprintf("%.1f %ciB", float64(n)/float64(div), "KMGTPE"[exp])
+}
+
+func lastBasesPath() (string, error) {
+	dir, err := dataDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, lastBasesFile), nil
+}
+
+func loadLastBases() map[string]string {
+	last := make(map[string]string)
+	p, err := lastBasesPath()
+	if err != nil {
+		return last
+	}
+	data, err := os.ReadFile(p)
+	if err != nil {
+		return last
+	}
+	if err := json.Unmarshal(data, &last); err != nil {
+		slog.Warn("ignoring corrupt base choices", "err", err)
+	}
+	return last
+}
+
+func saveLastBases(last map[string]string) error {
+	p, err := lastBasesPath()
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(p), 0o755); err != nil {
+		return err
+	}
+	data, err := json.Marshal(last)
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(p, data, 0o644)
+}
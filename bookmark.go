@@ -0,0 +1,157 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"text/tabwriter"
+)
+
+const (
+	bookmarksFile = "bookmarks.json"
+	// bookmarkMarker is listed as the marker of bookmarked projects.
+	bookmarkMarker = "bookmark"
+)
+
+// Bookmark is a directory shown in the picker regardless of the bases.
+type Bookmark struct {
+	Name string `json:"name"`
+	Path string `json:"path"`
+}
+
+func bookmarksPath() (string, error) {
+	dir, err := dataDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, bookmarksFile), nil
+}
+
+func loadBookmarks() ([]Bookmark, error) {
+	p, err := bookmarksPath()
+	if err != nil {
+		return nil, err
+	}
+	data, err := os.ReadFile(p)
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	var bookmarks []Bookmark
+	if err := json.Unmarshal(data, &bookmarks); err != nil {
+		return nil, fmt.Errorf("corrupt bookmarks file %s: %w", p, err)
+	}
+	return bookmarks, nil
+}
+
+func saveBookmarks(bookmarks []Bookmark) error {
+	p, err := bookmarksPath()
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(p), 0o755); err != nil {
+		return err
+	}
+	data, err := json.MarshalIndent(bookmarks, "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(p, data, 0o644)
+}
+
+// bookmarkedProjects returns the bookmarks as projects, leaving out
+// directories that no longer exist.
+func bookmarkedProjects() []*Project {
+	bookmarks, err := loadBookmarks()
+	if err != nil {
+		slog.Warn("failed to load bookmarks", "err", err)
+		return nil
+	}
+
+	var projects []*Project
+	for _, b := range bookmarks {
+		if _, err := os.Stat(b.Path); err != nil {
+			slog.Info("skipping missing bookmark", "path", b.Path)
+			continue
+		}
+		project, err := newProject(b.Name, b.Path)
+		if err != nil {
+			continue
+		}
+		project.Markers = []string{bookmarkMarker}
+		projects = append(projects, project)
+	}
+	return projects
+}
+
+// runBookmarkCommand pins directories outside of the bases into the picker:
+// add [dir] [name], remove <dir|name> and list.
+func runBookmarkCommand(_ *Config, args []string) error {
+	if len(args) == 0 {
+		args = []string{"list"}
+	}
+
+	bookmarks, err := loadBookmarks()
+	if err != nil {
+		return err
+	}
+
+	switch args[0] {
+	case "list":
+		w := tabwriter.NewWriter(os.Stdout, 0, 4, 2, ' ', 0)
+		for _, b := range bookmarks {
+			fmt.Fprintf(w, "%s\t%s\n", b.Name, b.Path)
+		}
+		return w.Flush()
+
+	case "add":
+		dir := "."
+		if len(args) > 1 {
+			dir = args[1]
+		}
+		dir, err := normalizePath(dir)
+		if err != nil {
+			return err
+		}
+		if info, err := os.Stat(dir); err != nil || !info.IsDir() {
+			return fmt.Errorf("%s is not a directory", dir)
+		}
+		name := filepath.Base(dir)
+		if len(args) > 2 {
+			name = args[2]
+		}
+
+		for _, b := range bookmarks {
+			if b.Path == dir {
+				return fmt.Errorf("%s is already bookmarked as %s", dir, b.Name)
+			}
+		}
+		if err := saveBookmarks(append(bookmarks, Bookmark{Name: name, Path: dir})); err != nil {
+			return err
+		}
+		fmt.Printf("Bookmarked %s as %s\n", dir, name)
+		return nil
+
+	case "remove":
+		if len(args) != 2 {
+			return errors.New("usage: tmuxer bookmark remove <dir|name>")
+		}
+		dir, _ := normalizePath(args[1])
+		for i, b := range bookmarks {
+			if b.Name == args[1] || b.Path == dir {
+				return saveBookmarks(append(bookmarks[:i], bookmarks[i+1:]...))
+			}
+		}
+		return fmt.Errorf("no bookmark %q", args[1])
+
+	default:
+		return fmt.Errorf("unknown bookmark command %q, expected add, remove or list", args[0])
+	}
+}
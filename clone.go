@@ -0,0 +1,56 @@
+package main
+
+import "path/filepath"
+
+// repoMarker is listed as the marker of configured repos not cloned yet.
+const repoMarker = "repo"
+
+// repoProjects returns the repos configured under repos that have no
+// checkout among the discovered projects yet. Until a base is chosen for
+// them their FullPath is the clone url.
+func (cfg *Config) repoProjects(discovered map[string]*Project) []*Project {
+	cloned := clonedNames(discovered)
+
+	var projects []*Project
+	for _, name := range sortedKeys(cfg.Repos) {
+		if cloned[name] {
+			continue
+		}
+		url := cfg.Repos[name]
+		projects = append(projects, &Project{
+			Name:     name,
+			FullPath: url,
+			HomePath: url,
+			Repo:     url,
+			Markers:  []string{repoMarker},
+		})
+	}
+	return projects
+}
+
+// clonedNames returns the directory names of the local checkouts among
+// projects.
+func clonedNames(projects map[string]*Project) map[string]bool {
+	names := make(map[string]bool)
+	for _, p := range projects {
+		if p.Remote == nil && p.FullPath != p.Repo {
+			names[filepath.Base(p.FullPath)] = true
+		}
+	}
+	return names
+}
+
+// placeRepo points a project that is not cloned yet into a base chosen by
+// the user.
+func placeRepo(cfg *Config, project *Project) error {
+	base, err := chooseBase(cfg, "clone", nil)
+	if err != nil {
+		return err
+	}
+	placed, err := newProject(project.Name, filepath.Join(base, project.Name))
+	if err != nil {
+		return err
+	}
+	project.FullPath, project.HomePath = placed.FullPath, placed.HomePath
+	return nil
+}
@@ -0,0 +1,89 @@
+package main
+
+import (
+	"fmt"
+	"os/exec"
+	"strings"
+
+	"github.com/k1ng440/tmuxer/third_party/go-fuzzyfinder"
+)
+
+// mergeClones folds checkouts of the same repository, those with the same
+// origin url, into the first of them, listing the others as its Clones.
+// Linked worktrees are left alone, they are listed on their own on purpose.
+func mergeClones(projects []*Project) []*Project {
+	first := make(map[string]*Project)
+	var res []*Project
+	for _, project := range projects {
+		var origin string
+		if project.Remote == nil && project.Repo == "" {
+			origin = normalizeRemoteURL(originURL(project.FullPath))
+		}
+		if origin == "" {
+			res = append(res, project)
+			continue
+		}
+		if p, ok := first[origin]; ok {
+			p.Clones = append(p.Clones, project)
+			continue
+		}
+		first[origin] = project
+		res = append(res, project)
+	}
+	return res
+}
+
+// originURL returns the url of the origin remote of the repository in dir,
+// empty when there is none.
+func originURL(dir string) string {
+	output, err := commandOutput(exec.Command("git", "-C", dir, "remote", "get-url", "origin"))
+	if err != nil {
+		return ""
+	}
+	return strings.TrimSpace(string(output))
+}
+
+// normalizeRemoteURL reduces the forms of a clone url to host/path, so
+// git@github.com:k1ng440/tmuxer.git and https://github.com/k1ng440/tmuxer
+// compare equal.
+func normalizeRemoteURL(u string) string {
+	u = strings.TrimSuffix(strings.TrimRight(strings.TrimSpace(u), "/"), ".git")
+	if _, rest, ok := strings.Cut(u, "://"); ok {
+		u = rest
+	} else if i := strings.Index(u, ":"); i > 0 && !strings.Contains(u[:i], "/") {
+		// scp-like syntax, user@host:path
+		u = u[:i] + "/" + u[i+1:]
+	}
+	host, _, _ := strings.Cut(u, "/")
+	if at := strings.LastIndex(host, "@"); at >= 0 {
+		u = u[at+1:]
+	}
+	return strings.ToLower(u)
+}
+
+// pickClone lets the user pick which checkout of project to open when
+// merge_clones found several.
+func pickClone(cfg *Config, project *Project) (*Project, error) {
+	if len(project.Clones) == 0 || *selectName != "" || !hasTerminal() {
+		return project, nil
+	}
+
+	checkouts := append([]*Project{project}, project.Clones...)
+	cfg.annotateSessions(checkouts)
+	idx, err := fuzzyfinder.Find(
+		checkouts,
+		func(i int) string {
+			label := "  " + tildePath(checkouts[i].FullPath)
+			if checkouts[i].Tmux != nil {
+				label = "● " + tildePath(checkouts[i].FullPath)
+			}
+			return label
+		},
+		fuzzyfinder.WithPromptString(fmt.Sprintf("%s checkout> ", project.Name)),
+		projectPreview(cfg, checkouts),
+	)
+	if err != nil {
+		return nil, err
+	}
+	return checkouts[idx], nil
+}
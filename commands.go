@@ -0,0 +1,234 @@
+package main
+
+// command is a subcommand of tmuxer along with its help.
+type command struct {
+	run func(cfg *Config, args []string) error
+	// usage lists the arguments following the command name.
+	usage    string
+	summary  string
+	examples []string
+	// anyConfig runs the command with an empty config when the config
+	// fails to load, so it can report the problem itself.
+	anyConfig bool
+}
+
+// commands maps subcommand names to their handlers. Running tmuxer without a
+// subcommand opens the project picker.
+var commands = map[string]*command{
+	"adopt": {
+		run:     runAdoptCommand,
+		summary: "Rename the current session after its project and record it in the history",
+	},
+	"archive": {
+		run:      runArchiveCommand,
+		usage:    "<project>",
+		summary:  "Kill the session of a project, move it to archive_dir and hide it from the picker",
+		examples: []string{"tmuxer archive old-api", "tmuxer --include-archived"},
+	},
+	"bookmark": {
+		run:      runBookmarkCommand,
+		usage:    "add [dir] [name] | remove <dir|name> | list",
+		summary:  "Pin directories outside of the bases into the picker",
+		examples: []string{"tmuxer bookmark add ~/notes", "tmuxer bookmark remove notes"},
+	},
+	"cache": {
+		run:     runCacheCommand,
+		usage:   "status",
+		summary: "Show what is cached and how the last scan went",
+	},
+	"clean": {
+		run:     runCleanCommand,
+		summary: "Remove cached data of projects that no longer exist",
+	},
+	"config": {
+		run:       runConfigCommand,
+		usage:     "validate [file] | migrate | edit | get <key> | set <key> [+] <value>",
+		summary:   "Validate, edit, query or change the config file, or move it to its XDG location",
+		examples:  []string{"tmuxer config validate", "tmuxer config get hooks.on_open", "tmuxer config set base + '~/src/**'", "tmuxer help config"},
+		anyConfig: true,
+	},
+	"daemon": {
+		run:     runDaemonCommand,
+		summary: "Run the tasks under schedule and watch bases until interrupted",
+	},
+	"doctor": {
+		run:       runDoctorCommand,
+		summary:   "Check the tmux installation, config, bases, cache and hooks and suggest fixes",
+		anyConfig: true,
+	},
+	"edit": {
+		run:      runEditCommand,
+		usage:    "<project>",
+		summary:  "Open a project and switch to its editor window, creating it when missing",
+		examples: []string{"tmuxer edit tmuxer"},
+	},
+	"event": {
+		run:     runEventCommand,
+		usage:   "<session-closed|client-detached> <session>",
+		summary: "Record a session event reported by a tmux hook",
+	},
+	"gc": {
+		run:     runGCCommand,
+		summary: "Kill detached project sessions idle for longer than idle_timeout",
+	},
+	"grep": {
+		run:      runGrepCommand,
+		usage:    "<pattern> [--content]",
+		summary:  "Pick from the projects whose name, path or with --content README title or module matches",
+		examples: []string{"tmuxer grep api", "tmuxer grep --content 'github.com/acme/'"},
+	},
+	"here": {
+		run:      runHereCommand,
+		summary:  "Open the project containing the working directory without the picker",
+		examples: []string{"alias t='tmuxer here'"},
+	},
+	"history": {
+		run:      runHistoryCommand,
+		usage:    "[days] [project]",
+		summary:  "Print the history log, or how long projects were used per day",
+		examples: []string{"tmuxer history days", "tmuxer history tmuxer"},
+	},
+	"import": {
+		run:      runImportCommand,
+		usage:    "<tmuxinator-or-tmuxp-file> [--launch]",
+		summary:  "Convert a tmuxinator or tmuxp project into a layout",
+		examples: []string{"tmuxer import ~/.config/tmuxinator/api.yml >> config.yaml"},
+	},
+	"init": {
+		run:     runInitCommand,
+		summary: "Write a commented config file after asking a few questions",
+	},
+	"keybind": {
+		run:      runKeybindCommand,
+		usage:    "[key] [--hooks]",
+		summary:  "Print tmux configuration binding a key to a tmuxer popup",
+		examples: []string{"tmuxer keybind --hooks >> ~/.config/tmux/tmux.conf"},
+	},
+	"last": {
+		run:     runLastCommand,
+		summary: "Switch back to the previously used project",
+	},
+	"layout": {
+		run:      runLayoutCommand,
+		usage:    "edit <name> [session]",
+		summary:  "Save the windows and panes of a session as a layout in the config",
+		examples: []string{"tmuxer layout edit dev"},
+	},
+	"list": {
+		run:      runListCommand,
+		summary:  "Print the discovered projects and their markers",
+		examples: []string{"tmuxer list -m go.mod"},
+	},
+	"mv": {
+		run:      runMvCommand,
+		usage:    "<project> <new-path-or-name>",
+		summary:  "Rename or move a project directory along with its session, history and bookmarks",
+		examples: []string{"tmuxer mv api api-v1", "tmuxer mv api ~/src/archive/api"},
+	},
+	"new": {
+		run:      runNewCommand,
+		usage:    "<name> [--template <name>]",
+		summary:  "Create a project in a base, scaffold it and open a session for it",
+		examples: []string{"tmuxer new billing --template go-service"},
+	},
+	"open": {
+		run:      runOpenCommand,
+		usage:    "<project> [--variant <name>]",
+		summary:  "Open a project by name without the picker",
+		examples: []string{"tmuxer open tmuxer --variant review", "tmuxer open tmuxer --terminal"},
+	},
+	"pin": {
+		run:      runPinCommand,
+		usage:    "[project]",
+		summary:  "Keep the sessions of a project from tmuxer gc and list it first, or list the pinned projects",
+		examples: []string{"tmuxer pin api", "tmuxer unpin api"},
+	},
+	"plugin": {
+		run:     runPluginCommand,
+		usage:   "install | script",
+		summary: "Integrate tmuxer as a TPM plugin",
+	},
+	"recent": {
+		run:      runRecentCommand,
+		usage:    "[n]",
+		summary:  "Pick from the most recently opened projects",
+		examples: []string{"tmuxer recent 5"},
+	},
+	"restore": {
+		run:     runRestoreCommand,
+		usage:   "<file> [session]",
+		summary: "Recreate a session from a snapshot",
+	},
+	"scan": {
+		run:      runScanCommand,
+		usage:    "[--stats]",
+		summary:  "Discover all projects and refresh the cached git information",
+		examples: []string{"tmuxer scan --stats"},
+	},
+	"snapshot": {
+		run:      runSnapshotCommand,
+		usage:    "<session> [file]",
+		summary:  "Save the windows, panes and commands of a session as YAML",
+		examples: []string{"tmuxer snapshot api api.yaml"},
+	},
+	"status": {
+		run:      runStatusCommand,
+		usage:    "[session]",
+		summary:  "Print the project, git branch and session count of a session for the tmux status bar",
+		examples: []string{"set -g status-right '#(tmuxer status #{session_name})'"},
+	},
+	"sync": {
+		run:     runSyncCommand,
+		usage:   "[session]",
+		summary: "Add the layout windows and panes missing from a session",
+	},
+	"unarchive": {
+		run:      runUnarchiveCommand,
+		usage:    "<project>",
+		summary:  "List an archived project in the picker again",
+		examples: []string{"tmuxer unarchive old-api"},
+	},
+	"trust": {
+		run:      runTrustCommand,
+		usage:    "[dir]",
+		summary:  "Use the .tmuxer.yaml and .tmuxer/config.yaml of a project, which are ignored until trusted",
+		examples: []string{"tmuxer trust ~/code/api"},
+	},
+	"unpin": {
+		run:     runUnpinCommand,
+		usage:   "<project>",
+		summary: "Unpin a project pinned with tmuxer pin",
+	},
+	"untrust": {
+		run:     runUntrustCommand,
+		usage:   "[dir]",
+		summary: "Ignore the project config trusted with tmuxer trust again",
+	},
+	"url": {
+		run:     runURLCommand,
+		usage:   "<tmuxer://open?path=...|tmuxer://open?repo=...> | register",
+		summary: "Open a project from a tmuxer:// link, or register the link handler",
+	},
+	"wait": {
+		run:     runWaitCommand,
+		usage:   "[port=<port>] [file=<path>] [command=<command>] [timeout=<duration>]",
+		summary: "Block until the conditions of a layout's wait_for hold",
+	},
+	"windows": {
+		run:     runWindowsCommand,
+		usage:   "[session]",
+		summary: "Pick a window of any session and switch to it",
+	},
+	"workspace": {
+		run:      runWorkspaceCommand,
+		usage:    "[name]",
+		summary:  "Open every project of a workspace, or list the workspaces",
+		examples: []string{"tmuxer workspace backend"},
+	},
+	"worktree": {
+		run:      runWorktreeCommand,
+		usage:    "<project> <branch>",
+		summary:  "Create a git worktree of a project for a branch and open a session for it",
+		examples: []string{"tmuxer worktree api fix/login", "tmuxer worktree api release/2.0 --detach"},
+	},
+}
@@ -0,0 +1,403 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"reflect"
+	"runtime"
+	"strings"
+	"text/template"
+
+	"github.com/spf13/pflag"
+	"gopkg.in/yaml.v3"
+)
+
+// Base is a directory pattern projects are discovered in. In the config it
+// is either a plain pattern or a mapping with additional settings.
+type Base struct {
+	Path string            `yaml:"path"`
+	Env  map[string]string `yaml:"env"`
+	// Prefix is put in front of the session names and picker entries of
+	// the projects of the base, such as work/.
+	Prefix string `yaml:"prefix"`
+	// Watch has tmuxer daemon create sessions for projects appearing in
+	// the base, see watchBases.
+	Watch bool `yaml:"watch"`
+	// Weight ranks the projects of the base above those of bases with a
+	// lower weight sharing their name, and boosts them in frecency order.
+	Weight int `yaml:"weight"`
+}
+
+func (b *Base) UnmarshalYAML(value *yaml.Node) error {
+	if value.Kind == yaml.ScalarNode {
+		return value.Decode(&b.Path)
+	}
+
+	type plain Base
+	return value.Decode((*plain)(b))
+}
+
+// ProjectConfig holds settings for a single project, keyed by project name.
+// The same settings can be given in a .tmuxer.yaml in the project directory.
+type ProjectConfig struct {
+	Env            map[string]string `yaml:"env"`
+	DefaultCommand string            `yaml:"default_command"`
+	Editor         string            `yaml:"editor"`
+}
+
+type Config struct {
+	// Include lists config files merged into this one, see mergeIncludes.
+	Include []string `yaml:"include"`
+	// Profiles are sets of settings overriding the rest of the config when
+	// selected, see applyProfile.
+	Profiles map[string]*Config `yaml:"profiles"`
+	// Profile is the profile used unless --profile or TMUXER_PROFILE
+	// select another.
+	Profile     string              `yaml:"profile"`
+	ProjectBase []*Base             `yaml:"base"`
+	Workspaces  map[string][]string `yaml:"workspaces"`
+	Actions     []string            `yaml:"actions"`
+	Modes       map[string][]string `yaml:"modes"`
+	Layout      string              `yaml:"layout"`
+	Layouts     map[string]*Layout  `yaml:"layouts"`
+	Hooks       Hooks               `yaml:"hooks"`
+	// SessionPrefix is prepended to the names of sessions created by tmuxer.
+	SessionPrefix string                    `yaml:"session_prefix"`
+	Env           map[string]string         `yaml:"env"`
+	Projects      map[string]*ProjectConfig `yaml:"projects"`
+	// Terminal is the command template used by --terminal, or one of
+	// terminalPresets.
+	Terminal string `yaml:"terminal"`
+	// SmartWindows creates windows based on the project contents when no
+	// layout is configured.
+	SmartWindows bool                `yaml:"smart_windows"`
+	Variants     map[string]*Variant `yaml:"variants"`
+	// DefaultCommand runs in the initial window of new sessions.
+	DefaultCommand string `yaml:"default_command"`
+	// Schedule maps daemon tasks to how often they run.
+	Schedule map[string]string `yaml:"schedule"`
+	// TypeLayouts maps detected project types, such as go or node, to the
+	// layout used for them instead of layout.
+	TypeLayouts map[string]string `yaml:"type_layouts"`
+	// Repos maps project names to clone urls. Repos without a checkout are
+	// listed in the picker and cloned into a base when selected.
+	Repos map[string]string `yaml:"repos"`
+	// Sources list the repositories of GitHub and GitLab organizations
+	// or users, see Source.
+	Sources []*Source `yaml:"sources"`
+	// ConfirmCreate describes new sessions and asks before creating them.
+	ConfirmCreate bool `yaml:"confirm_create"`
+	// IdleTimeout is how long a detached session without activity is kept
+	// before tmuxer gc kills it, such as 8h.
+	IdleTimeout string `yaml:"idle_timeout"`
+	// HideStale hides projects without commits or changes to their
+	// directory for longer than this, such as 180d.
+	HideStale string `yaml:"hide_stale"`
+	// Sort is the order projects are listed in, see sortStrategies.
+	Sort string `yaml:"sort"`
+	// Strict fails the scan when a base is missing or has unreadable
+	// directories instead of warning about it.
+	Strict bool `yaml:"strict"`
+	// Discovery lists the project sources to run, see projectSources.
+	Discovery []string `yaml:"discovery"`
+	// Theme colors the preview and list output, see defaultTheme.
+	Theme Theme `yaml:"theme"`
+	// Monorepo configures the monorepo project source.
+	Monorepo Monorepo `yaml:"monorepo"`
+	// Display is the template of picker entries, such as {{.HomePath}},
+	// see displayData, or columns, see columnsDisplay.
+	Display string `yaml:"display"`
+	// Backend is the terminal multiplexer sessions are opened in, see
+	// backends.
+	Backend string `yaml:"backend"`
+	// Symlinks is either follow, the default, to walk into symlinked
+	// directories of bases or skip to leave them out.
+	Symlinks string `yaml:"symlinks"`
+	// ExistingSession is what opening a project with a running session
+	// does, see existingBehaviors. The default is switch.
+	ExistingSession string `yaml:"existing_session"`
+	// MaxResults ends the scan once that many projects are found, zero
+	// means no limit.
+	MaxResults int `yaml:"max_results"`
+	// Editor is the command of editor windows, $EDITOR by default.
+	Editor string `yaml:"editor"`
+	// Secrets configures resolving secret: env values.
+	Secrets Secrets `yaml:"secrets"`
+	// Ignore lists directories to leave out of the scan in gitignore
+	// syntax, in addition to the ignore file and --ignore.
+	Ignore []string `yaml:"ignore"`
+	// Stream opens the picker right away and fills it in as the scan finds
+	// projects, instead of waiting for the full scan.
+	Stream bool `yaml:"stream"`
+	// WSL runs tmux inside the default WSL distribution from a windows
+	// build of tmuxer, see useWSL.
+	WSL bool `yaml:"wsl"`
+	// PickerKeys binds keys of the picker, such as ctrl-k, to actions on
+	// the highlighted project, see pickerActions.
+	PickerKeys map[string]string `yaml:"picker_keys"`
+	// WorktreeDir is where tmuxer worktree puts worktrees instead of next
+	// to their project.
+	WorktreeDir string `yaml:"worktree_dir"`
+	// ArchiveDir is where tmuxer archive moves projects to. They stay in
+	// place when it is empty.
+	ArchiveDir string `yaml:"archive_dir"`
+	// StatusFormat is the template of the line printed by tmuxer status,
+	// see statusData.
+	StatusFormat string `yaml:"status_format"`
+	// PickBase has the picker ask for the base first and then for a project
+	// of that base, see pickBaseFirst.
+	PickBase bool `yaml:"pick_base"`
+	// Aliases name directories, such as dot: ~/dotfiles, which are listed
+	// in the picker whatever the discovery and opened by tmuxer open.
+	Aliases map[string]string `yaml:"alias"`
+	// MergeClones lists checkouts of the same repository as one picker
+	// entry, see mergeClones.
+	MergeClones bool `yaml:"merge_clones"`
+	// Scaffold is run in the directory of projects created from the
+	// picker, such as git init.
+	Scaffold string `yaml:"scaffold"`
+	// Templates scaffold projects created with tmuxer new --template.
+	Templates map[string]*ProjectTemplate `yaml:"templates"`
+	// SessionNameTemplate names sessions instead of the project name, see
+	// sessionNameData.
+	SessionNameTemplate string `yaml:"session_name_template"`
+
+	display             *template.Template
+	sessionNameTemplate *template.Template
+}
+
+// sessionNameReplacer replaces the characters tmux does not allow in
+// session names the way tmux itself does, so the name tmuxer looks for is
+// the name the session gets.
+var sessionNameReplacer = strings.NewReplacer(".", "_", ":", "_")
+
+func (cfg *Config) sessionName(project *Project) string {
+	name := cfg.SessionPrefix + cfg.baseSessionName(project)
+	if project.Variant != "" {
+		name += "@" + project.Variant
+	}
+	return sessionNameReplacer.Replace(name)
+}
+
+func (cfg *Config) NormalizePaths() error {
+	for _, base := range cfg.ProjectBase {
+		if isRemoteBase(base.Path) {
+			continue
+		}
+		p, err := normalizePath(base.Path)
+		if err != nil {
+			return err
+		}
+		// glob patterns separate directories with slashes, a backslash
+		// escapes the next character
+		if runtime.GOOS == "windows" {
+			p = filepath.ToSlash(p)
+		}
+		base.Path = p
+	}
+
+	return nil
+}
+
+// legacyConfigPath is where the config was read from before tmuxer followed
+// the XDG base directory specification. It is still read when the new
+// location does not exist; tmuxer config migrate moves it.
+const legacyConfigPath = "~/.config/tmux/tmuxer.yaml"
+
+// configDir is where tmuxer looks for its config file.
+func configDir() (string, error) {
+	if dir := os.Getenv("XDG_CONFIG_HOME"); dir != "" {
+		return filepath.Join(dir, "tmuxer"), nil
+	}
+	return normalizePath("~/.config/tmuxer")
+}
+
+func defaultConfigPath() (string, error) {
+	dir, err := configDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, "config.yaml"), nil
+}
+
+// configFile returns the config file given with --config, or the default
+// one, falling back to the legacy location when only that exists.
+func configFile() (string, error) {
+	if pflag.CommandLine.Changed("config") {
+		return resolveConfigPath(*configPath)
+	}
+
+	p, err := defaultConfigPath()
+	if err != nil {
+		return "", err
+	}
+	if _, err := os.Stat(p); !errors.Is(err, fs.ErrNotExist) {
+		return p, nil
+	}
+
+	legacy, err := normalizePath(legacyConfigPath)
+	if err != nil {
+		return "", err
+	}
+	if _, err := os.Stat(legacy); err == nil {
+		slog.Warn("using config from its old location, run tmuxer config migrate to move it", "path", legacy)
+		return legacy, nil
+	}
+	return p, nil
+}
+
+// migrateConfig moves the config from its legacy location to the default
+// one.
+func migrateConfig() error {
+	legacy, err := normalizePath(legacyConfigPath)
+	if err != nil {
+		return err
+	}
+	p, err := defaultConfigPath()
+	if err != nil {
+		return err
+	}
+
+	if _, err := os.Stat(legacy); errors.Is(err, fs.ErrNotExist) {
+		fmt.Printf("nothing to migrate, %s does not exist\n", legacy)
+		return nil
+	}
+	if _, err := os.Stat(p); err == nil {
+		return fmt.Errorf("%s already exists, remove %s once merged into it", p, legacy)
+	}
+
+	if err := os.MkdirAll(filepath.Dir(p), 0o755); err != nil {
+		return err
+	}
+	if err := os.Rename(legacy, p); err != nil {
+		return fmt.Errorf("failed to move config: %w", err)
+	}
+	fmt.Printf("moved %s to %s\n", legacy, p)
+	return nil
+}
+
+// loadedConfigPath is the config file setupConfig read, or tried to read.
+var loadedConfigPath string
+
+func setupConfig() (*Config, error) {
+	cfgPath, err := configFile()
+	if err != nil {
+		return nil, err
+	}
+	loadedConfigPath = cfgPath
+
+	config, err := loadConfig(cfgPath)
+	// a missing default config is fine for commands that do not need one,
+	// such as those run from tmux hooks
+	if errors.Is(err, fs.ErrNotExist) && !pflag.CommandLine.Changed("config") {
+		config, err = &Config{}, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	if err := mergeFlagsWithConfig(config); err != nil {
+		return nil, err
+	}
+
+	if err := config.NormalizePaths(); err != nil {
+		return nil, fmt.Errorf("failed to normalize config path: %w", err)
+	}
+	tmuxInWSL = useWSL(config)
+
+	return config, nil
+}
+
+// resolveConfigPath expands p unless it is empty or "-", which both mean
+// running without a config file.
+func resolveConfigPath(p string) (string, error) {
+	if p == "" || p == "-" {
+		return p, nil
+	}
+	return normalizePath(p)
+}
+
+func loadConfig(configPath string) (*Config, error) {
+	config := &Config{}
+
+	if configPath == "" || configPath == "-" {
+		return config, nil
+	}
+
+	data, err := os.ReadFile(configPath)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := decodeStrict(configPath, data, config); err != nil {
+		return nil, err
+	}
+
+	return config, nil
+}
+
+// decodeStrict decodes the yaml document data into v, reporting unknown
+// fields and type errors as a *ConfigError. A *Config is also checked for
+// references to undefined layouts, actions and so on.
+func decodeStrict(path string, data []byte, v any) error {
+	var root yaml.Node
+	if err := yaml.Unmarshal(data, &root); err != nil {
+		return &ConfigError{Path: path, Problems: []string{err.Error()}}
+	}
+	if len(root.Content) == 0 {
+		// empty file
+		return nil
+	}
+
+	doc := root.Content[0]
+	problems := checkKnownFields(doc, reflect.TypeOf(v).Elem(), "")
+	if _, ok := v.(*Config); ok && len(problems) == 0 {
+		var err error
+		if doc, err = mergeIncludes(path, doc, nil); err != nil {
+			return err
+		}
+		if doc, err = applyProfile(path, doc); err != nil {
+			return err
+		}
+	}
+	expandConfig(doc)
+	if err := doc.Decode(v); err != nil {
+		var typeErr *yaml.TypeError
+		if !errors.As(err, &typeErr) {
+			return &ConfigError{Path: path, Problems: []string{err.Error()}}
+		}
+		problems = append(problems, typeErr.Errors...)
+	}
+	if cfg, ok := v.(*Config); ok && len(problems) == 0 {
+		problems = cfg.validate(doc)
+	}
+	if len(problems) > 0 {
+		return &ConfigError{Path: path, Problems: problems}
+	}
+	return nil
+}
+
+func mergeFlagsWithConfig(config *Config) error {
+	if len(*projectBase) > 0 {
+		for _, p := range *projectBase {
+			config.ProjectBase = append(config.ProjectBase, &Base{Path: p})
+		}
+	}
+	if *sortOrder != "" {
+		config.Sort = *sortOrder
+	}
+	if *strict {
+		config.Strict = true
+	}
+	if *pickBase {
+		config.PickBase = true
+	}
+	if *readStdin != "" && *readStdin != "replace" && *readStdin != "merge" {
+		return fmt.Errorf("invalid --stdin %q, expected replace or merge", *readStdin)
+	}
+	return nil
+}
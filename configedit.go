@@ -0,0 +1,213 @@
+package main
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// updateConfig loads the config file at path as a yaml document, lets fn
+// modify it and writes it back, keeping comments and the order of keys. The
+// result is validated before the file is replaced.
+func updateConfig(path string, fn func(root *yaml.Node) error) error {
+	if path == "" || path == "-" {
+		return errors.New("no config file to update")
+	}
+
+	var doc yaml.Node
+	data, err := os.ReadFile(path)
+	if err != nil && !errors.Is(err, fs.ErrNotExist) {
+		return err
+	}
+	if err := yaml.Unmarshal(data, &doc); err != nil {
+		return fmt.Errorf("failed to parse %s: %w", path, err)
+	}
+	if len(doc.Content) == 0 {
+		doc = yaml.Node{Kind: yaml.DocumentNode, Content: []*yaml.Node{{Kind: yaml.MappingNode}}}
+	}
+
+	if err := fn(doc.Content[0]); err != nil {
+		return err
+	}
+
+	var buf bytes.Buffer
+	enc := yaml.NewEncoder(&buf)
+	enc.SetIndent(2)
+	if err := enc.Encode(&doc); err != nil {
+		return err
+	}
+	out := buf.Bytes()
+	if err := decodeStrict(path, out, &Config{}); err != nil {
+		return err
+	}
+
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return err
+	}
+	tmp := path + ".tmp"
+	if err := os.WriteFile(tmp, out, 0o644); err != nil {
+		return err
+	}
+	return os.Rename(tmp, path)
+}
+
+// setNode sets the value at the given mapping keys below root, creating
+// intermediate mappings as needed.
+func setNode(root *yaml.Node, value *yaml.Node, keys ...string) error {
+	node := root
+	for i, key := range keys {
+		if node.Kind != yaml.MappingNode {
+			return fmt.Errorf("%s is not a mapping", key)
+		}
+
+		var next *yaml.Node
+		for j := 0; j+1 < len(node.Content); j += 2 {
+			if node.Content[j].Value == key {
+				next = node.Content[j+1]
+				if i == len(keys)-1 {
+					node.Content[j+1] = value
+				}
+				break
+			}
+		}
+		if next == nil {
+			next = &yaml.Node{Kind: yaml.MappingNode}
+			if i == len(keys)-1 {
+				next = value
+			}
+			node.Content = append(node.Content, &yaml.Node{Kind: yaml.ScalarNode, Value: key}, next)
+		}
+		node = next
+	}
+	return nil
+}
+
+// editConfig opens a copy of the config file at path in $EDITOR and
+// replaces the file with it once it is valid. When it is not, the problems
+// are shown and the copy can be edited again.
+func editConfig(path string) error {
+	if path == "" || path == "-" {
+		return errors.New("no config file to edit")
+	}
+	data, err := os.ReadFile(path)
+	if err != nil && !errors.Is(err, fs.ErrNotExist) {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return err
+	}
+	f, err := os.CreateTemp(filepath.Dir(path), ".config-*.yaml")
+	if err != nil {
+		return err
+	}
+	tmp := f.Name()
+	defer os.Remove(tmp)
+	_, err = f.Write(data)
+	if err2 := f.Close(); err == nil {
+		err = err2
+	}
+	if err != nil {
+		return err
+	}
+
+	for {
+		cmd := shellCommand(systemEditor(), tmp)
+		cmd.Stdin, cmd.Stdout, cmd.Stderr = os.Stdin, os.Stdout, os.Stderr
+		if err := runCommand(cmd); err != nil {
+			return fmt.Errorf("editor failed: %w", err)
+		}
+		edited, err := os.ReadFile(tmp)
+		if err != nil {
+			return err
+		}
+		if bytes.Equal(edited, data) {
+			fmt.Println("No changes")
+			return nil
+		}
+
+		err = decodeStrict(path, edited, &Config{})
+		if err == nil {
+			if err := os.Rename(tmp, path); err != nil {
+				return err
+			}
+			fmt.Printf("Saved %s\n", path)
+			return nil
+		}
+		fmt.Fprintln(os.Stderr, err)
+		answer, err := prompt("Edit again? [Y/n] ")
+		if err != nil {
+			return err
+		}
+		if a := strings.ToLower(answer); a != "" && a != "y" {
+			return errors.New("config not saved")
+		}
+	}
+}
+
+// configKeys splits a key such as hooks.on_open into its mapping keys.
+func configKeys(key string) []string {
+	return strings.Split(key, ".")
+}
+
+// printConfigValue prints the value of key in the config file at path,
+// scalars as they are and everything else as yaml.
+func printConfigValue(path, key string) error {
+	if path == "" || path == "-" {
+		return errors.New("no config file to read")
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return err
+	}
+	var doc yaml.Node
+	if err := yaml.Unmarshal(data, &doc); err != nil {
+		return fmt.Errorf("failed to parse %s: %w", path, err)
+	}
+	var node *yaml.Node
+	if len(doc.Content) > 0 {
+		node = findNode(doc.Content[0], configKeys(key)...)
+	}
+	if node == nil {
+		return fmt.Errorf("%s is not set in %s", key, path)
+	}
+
+	if node.Kind == yaml.ScalarNode {
+		fmt.Println(node.Value)
+		return nil
+	}
+	enc := yaml.NewEncoder(os.Stdout)
+	enc.SetIndent(2)
+	return enc.Encode(node)
+}
+
+// setConfigValue sets key in the config file at path to value, parsed as
+// yaml, or with add appends value to the list at key.
+func setConfigValue(path, key, value string, add bool) error {
+	var doc yaml.Node
+	v := &yaml.Node{Kind: yaml.ScalarNode, Tag: "!!str", Value: value}
+	if err := yaml.Unmarshal([]byte(value), &doc); err == nil && len(doc.Content) > 0 {
+		v = doc.Content[0]
+	}
+
+	keys := configKeys(key)
+	return updateConfig(path, func(root *yaml.Node) error {
+		if !add {
+			return setNode(root, v, keys...)
+		}
+		list := findNode(root, keys...)
+		if list == nil {
+			return setNode(root, &yaml.Node{Kind: yaml.SequenceNode, Content: []*yaml.Node{v}}, keys...)
+		}
+		if list.Kind != yaml.SequenceNode {
+			return fmt.Errorf("%s is not a list", key)
+		}
+		list.Content = append(list.Content, v)
+		return nil
+	})
+}
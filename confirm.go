@@ -0,0 +1,62 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+)
+
+// confirmCreate describes the session about to be created for project and
+// asks whether to go ahead, returning errCancelled when not. Nothing is
+// asked when the session already exists.
+func confirmCreate(cfg *Config, project *Project) error {
+	exists, err := tmuxClient.HasSession(project.Session)
+	if err != nil || exists {
+		return err
+	}
+
+	var b strings.Builder
+	fmt.Fprintf(&b, "Session: %s\nDirectory: %s\n", project.Session, project.FullPath)
+	if command := cfg.defaultCommand(project); command != "" {
+		fmt.Fprintf(&b, "Command: %s\n", command)
+	}
+
+	layout, err := cfg.layoutFor(project)
+	if err != nil {
+		return err
+	}
+	if layout != nil {
+		if layout, err = layout.render(cfg, project); err != nil {
+			return err
+		}
+		for i, w := range layout.Windows {
+			fmt.Fprintf(&b, "Window %d: %s\n", i+1, describeCommand(w.Name, w.Command))
+			for _, p := range w.Panes {
+				fmt.Fprintf(&b, "  Pane: %s\n", describeCommand(p.Dir, p.Command))
+			}
+		}
+	}
+	for _, hook := range cfg.Hooks.OnCreate {
+		fmt.Fprintf(&b, "Hook: %s\n", hook.Run)
+	}
+	fmt.Print(b.String())
+
+	answer, err := prompt("Create it? [Y/n] ")
+	if err != nil {
+		return err
+	}
+	if !isYes(answer, true) {
+		return errCancelled
+	}
+	return nil
+}
+
+func describeCommand(label, command string) string {
+	switch {
+	case command == "":
+		return label
+	case label == "":
+		return command
+	default:
+		return label + " (" + command + ")"
+	}
+}
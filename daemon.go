@@ -0,0 +1,157 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+const daemonStateFile = "daemon.json"
+
+// daemonTick is how often the daemon checks for due tasks.
+const daemonTick = time.Minute
+
+// daemonTasks are the tasks that can be scheduled in the config.
+var daemonTasks = map[string]func(cfg *Config) error{
+	"scan": func(cfg *Config) error {
+		_, _, err := scanProjects(cfg)
+		return err
+	},
+	"clean": func(_ *Config) error {
+		_, err := cleanCaches()
+		return err
+	},
+	"gc": func(cfg *Config) error {
+		killed, err := reapIdleSessions(cfg)
+		if len(killed) > 0 {
+			notify(cfg, "Killed idle sessions %s", strings.Join(killed, ", "))
+		}
+		return err
+	},
+}
+
+var scheduleAliases = map[string]time.Duration{
+	"hourly": time.Hour,
+	"daily":  24 * time.Hour,
+	"weekly": 7 * 24 * time.Hour,
+}
+
+// parseSchedule accepts hourly, daily, weekly (optionally prefixed with @
+// like in crontabs) or a Go duration such as 30m.
+func parseSchedule(s string) (time.Duration, error) {
+	if d, ok := scheduleAliases[strings.TrimPrefix(s, "@")]; ok {
+		return d, nil
+	}
+
+	d, err := time.ParseDuration(s)
+	if err != nil {
+		return 0, fmt.Errorf("invalid schedule %q, expected hourly, daily, weekly or a duration", s)
+	}
+	if d < daemonTick {
+		return 0, fmt.Errorf("schedule %q is shorter than %s", s, daemonTick)
+	}
+	return d, nil
+}
+
+// runDaemonCommand runs the scheduled tasks, and checks the watched bases
+// for new projects every tick, until interrupted. The time of the last run
+// of each task is persisted, so long intervals such as weekly survive
+// restarts.
+func runDaemonCommand(cfg *Config, _ []string) error {
+	if len(cfg.Schedule) == 0 && len(cfg.watchedBases()) == 0 {
+		return errors.New("nothing to do, no tasks are scheduled and no bases watched in the config")
+	}
+
+	intervals := make(map[string]time.Duration, len(cfg.Schedule))
+	for _, task := range sortedKeys(cfg.Schedule) {
+		if _, ok := daemonTasks[task]; !ok {
+			return fmt.Errorf("unknown scheduled task %q", task)
+		}
+		d, err := parseSchedule(cfg.Schedule[task])
+		if err != nil {
+			return err
+		}
+		intervals[task] = d
+	}
+
+	state := loadDaemonState()
+	pending := make(map[string]string)
+	ticker := time.NewTicker(daemonTick)
+	defer ticker.Stop()
+
+	slog.Info("daemon started", "tasks", len(intervals), "watched", len(cfg.watchedBases()))
+	for {
+		runDueTasks(cfg, intervals, state)
+		if err := watchBases(cfg, pending); err != nil {
+			slog.Error("failed to check watched bases", "err", err)
+		}
+
+		select {
+		case <-ticker.C:
+		case <-appContext.Done():
+			slog.Info("daemon stopped")
+			return nil
+		}
+	}
+}
+
+func runDueTasks(cfg *Config, intervals map[string]time.Duration, state map[string]time.Time) {
+	for _, task := range sortedKeys(intervals) {
+		if time.Since(state[task]) < intervals[task] {
+			continue
+		}
+
+		slog.Info("running scheduled task", "task", task)
+		if err := daemonTasks[task](cfg); err != nil {
+			slog.Error("scheduled task failed", "task", task, "err", err)
+		}
+		state[task] = time.Now()
+		if err := saveDaemonState(state); err != nil {
+			slog.Warn("failed to save daemon state", "err", err)
+		}
+	}
+}
+
+func daemonStatePath() (string, error) {
+	dir, err := dataDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, daemonStateFile), nil
+}
+
+func loadDaemonState() map[string]time.Time {
+	state := make(map[string]time.Time)
+	p, err := daemonStatePath()
+	if err != nil {
+		return state
+	}
+	data, err := os.ReadFile(p)
+	if err != nil {
+		return state
+	}
+	if err := json.Unmarshal(data, &state); err != nil {
+		slog.Warn("ignoring corrupt daemon state", "err", err)
+	}
+	return state
+}
+
+func saveDaemonState(state map[string]time.Time) error {
+	p, err := daemonStatePath()
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(p), 0o755); err != nil {
+		return err
+	}
+	data, err := json.Marshal(state)
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(p, data, 0o644)
+}
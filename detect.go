@@ -0,0 +1,59 @@
+package main
+
+import (
+	"path/filepath"
+	"strings"
+)
+
+// projectType classifies projects containing any of its marker files.
+// Markers may be glob patterns.
+type projectType struct {
+	Name    string
+	Markers []string
+}
+
+// projectTypes are checked in order, which is also the order type_layouts
+// are considered in for projects of several types.
+var projectTypes = []projectType{
+	{Name: "go", Markers: []string{"go.mod"}},
+	{Name: "rust", Markers: []string{"Cargo.toml"}},
+	{Name: "node", Markers: []string{"package.json"}},
+	{Name: "python", Markers: []string{"pyproject.toml", "setup.py", "requirements.txt", "Pipfile"}},
+	{Name: "terraform", Markers: []string{"*.tf"}},
+	{Name: "docker-compose", Markers: []string{"compose.yaml", "compose.yml", "docker-compose.yaml", "docker-compose.yml"}},
+}
+
+func projectTypeNames() []string {
+	names := make([]string, len(projectTypes))
+	for i, t := range projectTypes {
+		names[i] = t.Name
+	}
+	return names
+}
+
+// detectTypes returns the types of project, detecting them on first use.
+// Remote projects have no type.
+func detectTypes(project *Project) []string {
+	if project.Types != nil {
+		return project.Types
+	}
+
+	project.Types = []string{}
+	if project.Remote != nil {
+		return project.Types
+	}
+	for _, t := range projectTypes {
+		for _, marker := range t.Markers {
+			if matches, _ := filepath.Glob(filepath.Join(project.FullPath, marker)); len(matches) > 0 {
+				project.Types = append(project.Types, t.Name)
+				break
+			}
+		}
+	}
+	return project.Types
+}
+
+// typeList returns the types of project separated by commas.
+func typeList(project *Project) string {
+	return strings.Join(detectTypes(project), ",")
+}
@@ -0,0 +1,386 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"log/slog"
+	"os"
+	"os/exec"
+	"path"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/bmatcuk/doublestar/v4"
+)
+
+// ProjectSource discovers projects. Sources run in the order listed under
+// discovery in the config and see the projects found by those before them.
+type ProjectSource interface {
+	Discover(d *discovery) ([]*Project, error)
+}
+
+// sourceFunc adapts a function to ProjectSource.
+type sourceFunc func(d *discovery) ([]*Project, error)
+
+func (f sourceFunc) Discover(d *discovery) ([]*Project, error) {
+	return f(d)
+}
+
+// discovery is the state of a single scan shared by the sources.
+type discovery struct {
+	cfg *Config
+	// projects are those found so far, keyed by their real path.
+	projects map[string]*Project
+	stats    []*BaseStats
+	// found, when set, is passed every project as soon as it is found.
+	// Returning errEnough ends the scan.
+	found func(*Project) error
+}
+
+// errEnough is returned by discovery.found to end a scan early.
+var errEnough = errors.New("enough projects found")
+
+// add adds project to the projects found so far and passes it on to found
+// unless it was found before. It ends the scan once max_results projects
+// are found.
+func (d *discovery) add(project *Project) error {
+	if !addProject(d.projects, project) {
+		return nil
+	}
+	if d.found != nil {
+		if err := d.found(project); err != nil {
+			return err
+		}
+	}
+	if limit := d.cfg.MaxResults; limit > 0 && len(d.projects) >= limit {
+		slog.Info("stopped the scan at max_results", "max_results", limit)
+		return errEnough
+	}
+	return nil
+}
+
+// projectSources are the sources that can be listed under discovery.
+var projectSources = map[string]ProjectSource{
+	"bases":     sourceFunc(discoverBases),
+	"bookmarks": sourceFunc(discoverBookmarks),
+	"monorepo":  sourceFunc(discoverMonorepos),
+	"repos":     sourceFunc(discoverRepos),
+	"sources":   sourceFunc(discoverHosted),
+	"stdin":     sourceFunc(discoverStdin),
+	"worktrees": sourceFunc(discoverWorktrees),
+	"zoxide":    sourceFunc(discoverZoxide),
+}
+
+var defaultDiscovery = []string{"bases", "bookmarks", "repos", "sources"}
+
+func (cfg *Config) discovery() []string {
+	names := defaultDiscovery
+	if len(cfg.Discovery) > 0 {
+		names = cfg.Discovery
+	}
+	switch *readStdin {
+	case "replace":
+		return []string{"stdin"}
+	case "merge":
+		if !contains(names, "stdin") {
+			return append(append([]string{}, names...), "stdin")
+		}
+	}
+	return names
+}
+
+// discoverProjects runs the configured sources and reports statistics about
+// the scan of each base.
+func discoverProjects(cfg *Config) ([]*Project, []*BaseStats, error) {
+	return streamProjects(cfg, nil)
+}
+
+// streamProjects is discoverProjects passing every project to found as soon
+// as it is found, in no particular order. When found returns errEnough the
+// scan ends early without an error, like it does at max_results.
+func streamProjects(cfg *Config, found func(*Project) error) ([]*Project, []*BaseStats, error) {
+	d := &discovery{cfg: cfg, projects: make(map[string]*Project), found: found}
+	// aliases go first so their name wins over the one found by a scan
+	if *readStdin != "replace" {
+		for _, project := range cfg.aliasProjects() {
+			if err := d.add(project); err != nil && !errors.Is(err, errEnough) {
+				return nil, d.stats, err
+			}
+		}
+	}
+	enough := false
+	for _, name := range cfg.discovery() {
+		source, ok := projectSources[name]
+		if !ok {
+			return nil, d.stats, fmt.Errorf("unknown project source %q, expected one of: %s", name, strings.Join(sortedKeys(projectSources), ", "))
+		}
+		if err := appContext.Err(); err != nil {
+			return nil, d.stats, err
+		}
+		start := time.Now()
+		projects, err := source.Discover(d)
+		for _, project := range projects {
+			if err == nil {
+				err = d.add(project)
+			}
+		}
+		if errors.Is(err, errEnough) {
+			enough = true
+			break
+		}
+		if err != nil {
+			return nil, d.stats, err
+		}
+		slog.Debug("ran project source", "source", name, "projects", len(projects), "duration", time.Since(start))
+	}
+	// archived projects go last, so those still in a base keep what the
+	// scan found about them
+	if !enough && *readStdin != "replace" {
+		for _, project := range archivedProjects() {
+			if err := d.add(project); err != nil {
+				if errors.Is(err, errEnough) {
+					break
+				}
+				return nil, d.stats, err
+			}
+		}
+	}
+
+	res := make([]*Project, 0, len(d.projects))
+	for _, v := range d.projects {
+		res = append(res, v)
+	}
+	if err := cfg.sortProjects(res); err != nil {
+		return nil, d.stats, err
+	}
+	return res, d.stats, nil
+}
+
+// How the walk of a base treats symlinked directories, see Config.Symlinks.
+const (
+	symlinksFollow = "follow"
+	symlinksSkip   = "skip"
+)
+
+// discoverBases walks the globs of the bases, and lists remote bases over
+// ssh.
+func discoverBases(d *discovery) ([]*Project, error) {
+	ignoreRules, err := globalIgnoreRules(d.cfg)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read ignore rules: %w", err)
+	}
+
+	var ret []*Project
+	for _, b := range d.cfg.ProjectBase {
+		start := time.Now()
+		st := &BaseStats{Base: b.Path}
+		d.stats = append(d.stats, st)
+
+		if isRemoteBase(b.Path) {
+			projects, visited, err := findRemoteProjects(b.Path)
+			if err != nil {
+				return nil, err
+			}
+			for _, project := range projects {
+				project.Base = b
+				ret = append(ret, project)
+			}
+			st.Visited, st.Projects, st.Duration = visited, len(projects), time.Since(start)
+			slog.Info("scanned remote base", "base", b.Path, "projects", st.Projects, "duration", st.Duration)
+			continue
+		}
+
+		base, pattern := doublestar.SplitPattern(b.Path)
+		patternUsed := len(globRegex.FindStringIndex(path.Base(pattern))) > 0
+		ignore, err := baseIgnoreMatcher(ignoreRules, base)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read ignore rules of %s: %w", b.Path, err)
+		}
+		if info, err := os.Stat(base); err != nil || !info.IsDir() {
+			st.Errors = append(st.Errors, fmt.Sprintf("base directory %s does not exist", base))
+			if err := d.cfg.walkFailed(st); err != nil {
+				return nil, err
+			}
+			continue
+		}
+		fsys := &countingFS{FS: os.DirFS(base), ignore: ignore}
+		opts := []doublestar.GlobOption{doublestar.WithFailOnPatternNotExist()}
+		if d.cfg.Symlinks == symlinksSkip {
+			opts = append(opts, doublestar.WithNoFollow())
+		} else {
+			fsys.root = base
+		}
+		err = doublestar.GlobWalk(fsys, pattern, func(p string, _ fs.DirEntry) error {
+			name := projectName(base, p, patternUsed)
+			project, err := newProject(name, path.Join(base, name))
+			if err != nil {
+				return err
+			}
+			project.Base = b
+			if patternUsed {
+				project.Markers = []string{path.Base(p)}
+			}
+			ret = append(ret, project)
+			st.Projects++
+			return d.add(project)
+		}, opts...)
+		if errors.Is(err, errEnough) {
+			return ret, err
+		}
+		if err := appContext.Err(); err != nil {
+			return nil, err
+		}
+		if errors.Is(err, doublestar.ErrPatternNotExist) {
+			err = fmt.Errorf("base directory %s does not exist", b.Path)
+		}
+		for _, e := range fsys.errs {
+			st.Errors = append(st.Errors, e.Error())
+		}
+		if err != nil {
+			st.Errors = append(st.Errors, err.Error())
+		}
+		st.Visited, st.Ignored, st.Duration = fsys.dirs, fsys.ignored, time.Since(start)
+		slog.Info("scanned base", "base", b.Path, "projects", st.Projects, "visited", st.Visited, "duration", st.Duration)
+		if err := d.cfg.walkFailed(st); err != nil {
+			return nil, err
+		}
+	}
+	return ret, nil
+}
+
+// walkFailed warns about the errors met while scanning a base, such as
+// unreadable directories or a bad pattern. In strict mode they fail the
+// scan instead.
+func (cfg *Config) walkFailed(st *BaseStats) error {
+	if len(st.Errors) == 0 {
+		return nil
+	}
+	if cfg.Strict {
+		return fmt.Errorf("failed to scan %s: %s", st.Base, strings.Join(st.Errors, "; "))
+	}
+	slog.Warn("errors while scanning base", "base", st.Base, "errors", len(st.Errors), "first", st.Errors[0])
+	for _, e := range st.Errors[1:] {
+		slog.Debug("error while scanning base", "base", st.Base, "err", e)
+	}
+	return nil
+}
+
+func discoverBookmarks(*discovery) ([]*Project, error) {
+	return bookmarkedProjects(), nil
+}
+
+func discoverRepos(d *discovery) ([]*Project, error) {
+	return d.cfg.repoProjects(d.projects), nil
+}
+
+func discoverHosted(d *discovery) ([]*Project, error) {
+	return d.cfg.sourceProjects(d.projects), nil
+}
+
+// worktreeMarker is listed as the marker of linked git worktrees.
+const worktreeMarker = "worktree"
+
+// discoverWorktrees adds the linked worktrees of the git repositories found
+// so far. They are read from .git/worktrees, without running git.
+func discoverWorktrees(d *discovery) ([]*Project, error) {
+	var ret []*Project
+	for _, project := range d.projects {
+		if project.Remote != nil {
+			continue
+		}
+		dir := filepath.Join(project.FullPath, ".git", "worktrees")
+		entries, err := os.ReadDir(dir)
+		if err != nil {
+			continue
+		}
+		for _, e := range entries {
+			// gitdir holds the path of the .git file in the worktree
+			data, err := os.ReadFile(filepath.Join(dir, e.Name(), "gitdir"))
+			if err != nil {
+				continue
+			}
+			wt := filepath.Dir(strings.TrimSpace(string(data)))
+			if _, err := os.Stat(wt); err != nil {
+				slog.Debug("skipping missing worktree", "path", wt)
+				continue
+			}
+			worktree, err := newProject(filepath.Base(wt), wt)
+			if err != nil {
+				continue
+			}
+			worktree.Base = project.Base
+			worktree.Parent = project
+			worktree.Markers = []string{worktreeMarker}
+			ret = append(ret, worktree)
+		}
+	}
+	return ret, nil
+}
+
+// stdinMarker is listed as the marker of projects read with --stdin.
+const stdinMarker = "stdin"
+
+// discoverStdin reads newline-separated project paths from stdin, such as
+// the output of fd. Relative paths are taken from the working directory.
+func discoverStdin(*discovery) ([]*Project, error) {
+	var ret []*Project
+	for {
+		line, err := stdin.ReadString('\n')
+		if errors.Is(err, io.EOF) && line == "" {
+			return ret, nil
+		}
+		if err != nil && !errors.Is(err, io.EOF) {
+			return nil, fmt.Errorf("failed to read stdin: %w", err)
+		}
+		if project := pathProject(strings.TrimSpace(line)); project != nil {
+			project.Markers = []string{stdinMarker}
+			ret = append(ret, project)
+		}
+	}
+}
+
+// pathProject returns the directory p as a project, or nil when it is not
+// a directory.
+func pathProject(p string) *Project {
+	if p == "" {
+		return nil
+	}
+	dir, err := normalizePath(p)
+	if err != nil {
+		return nil
+	}
+	if info, err := os.Stat(dir); err != nil || !info.IsDir() {
+		slog.Info("skipping path that is not a directory", "path", p)
+		return nil
+	}
+	project, _ := newProject(filepath.Base(dir), dir)
+	return project
+}
+
+// zoxideMarker is listed as the marker of directories known to zoxide.
+const zoxideMarker = "zoxide"
+
+// discoverZoxide adds the directories in the zoxide database.
+func discoverZoxide(*discovery) ([]*Project, error) {
+	output, err := commandOutput(exec.CommandContext(appContext, "zoxide", "query", "--list"))
+	if err != nil {
+		slog.Warn("failed to query zoxide", "err", err)
+		return nil, nil
+	}
+
+	homedir, _ := os.UserHomeDir()
+	var ret []*Project
+	for _, dir := range strings.Split(strings.TrimSpace(string(output)), "\n") {
+		if dir == homedir || dir == "/" {
+			continue
+		}
+		if project := pathProject(dir); project != nil {
+			project.Markers = []string{zoxideMarker}
+			ret = append(ret, project)
+		}
+	}
+	return ret, nil
+}
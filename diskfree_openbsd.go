@@ -0,0 +1,15 @@
+//go:build openbsd
+
+package main
+
+import "golang.org/x/sys/unix"
+
+// diskFree returns the space available to unprivileged users on the
+// filesystem containing path.
+func diskFree(path string) (uint64, error) {
+	var stat unix.Statfs_t
+	if err := unix.Statfs(path, &stat); err != nil {
+		return 0, err
+	}
+	return uint64(stat.F_bavail) * uint64(stat.F_bsize), nil
+}
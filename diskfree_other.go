@@ -0,0 +1,11 @@
+//go:build !linux && !darwin && !freebsd && !dragonfly && !openbsd && !netbsd && !solaris && !windows
+
+package main
+
+import "errors"
+
+// diskFree is not implemented on this system, the free space of bases is
+// shown as unknown.
+func diskFree(string) (uint64, error) {
+	return 0, errors.New("free space unknown on this system")
+}
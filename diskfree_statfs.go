@@ -0,0 +1,16 @@
+//go:build linux || darwin || freebsd || dragonfly
+
+package main
+
+import "golang.org/x/sys/unix"
+
+// diskFree returns the space available to unprivileged users on the
+// filesystem containing path.
+func diskFree(path string) (uint64, error) {
+	var stat unix.Statfs_t
+	if err := unix.Statfs(path, &stat); err != nil {
+		return 0, err
+	}
+	// the field types differ between systems and architectures
+	return uint64(stat.Bavail) * uint64(stat.Bsize), nil
+}
@@ -0,0 +1,15 @@
+//go:build netbsd || solaris
+
+package main
+
+import "golang.org/x/sys/unix"
+
+// diskFree returns the space available to unprivileged users on the
+// filesystem containing path.
+func diskFree(path string) (uint64, error) {
+	var stat unix.Statvfs_t
+	if err := unix.Statvfs(path, &stat); err != nil {
+		return 0, err
+	}
+	return uint64(stat.Bavail) * uint64(stat.Frsize), nil
+}
@@ -0,0 +1,149 @@
+package main
+
+import (
+	"fmt"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"strings"
+	"text/template"
+
+	"github.com/bmatcuk/doublestar/v4"
+	"github.com/mattn/go-runewidth"
+)
+
+// displayData is available to the display template of picker entries.
+type displayData struct {
+	Name     string
+	FullPath string
+	// HomePath is FullPath relative to $HOME, or FullPath when the project
+	// is not below it.
+	HomePath string
+	// Base is the directory the base of the project starts from, or its
+	// parent directory when it was not found in a base.
+	Base    string
+	Markers string
+	Branch  string
+	// Prefix is the prefix of the project's base.
+	Prefix string
+}
+
+func newDisplayData(project *Project) displayData {
+	data := displayData{
+		Name:     project.Name,
+		FullPath: project.FullPath,
+		HomePath: project.HomePath,
+		Base:     filepath.Dir(project.FullPath),
+		Markers:  strings.Join(project.Markers, ","),
+		Prefix:   project.basePrefix(),
+	}
+	if project.Base != nil && !isRemoteBase(project.Base.Path) {
+		data.Base, _ = doublestar.SplitPattern(project.Base.Path)
+	}
+	data.Base = tildePath(data.Base)
+	if project.Git != nil {
+		data.Branch = project.Git.Branch
+	}
+	return data
+}
+
+// parseDisplay parses the display template of the config.
+func parseDisplay(text string) (*template.Template, error) {
+	tmpl, err := template.New("display").Option("missingkey=error").Parse(text)
+	if err != nil {
+		return nil, fmt.Errorf("invalid display template %q: %w", text, err)
+	}
+	// catch unknown fields before the picker starts
+	if err := tmpl.Execute(&strings.Builder{}, displayData{}); err != nil {
+		return nil, fmt.Errorf("invalid display template %q: %w", text, err)
+	}
+	return tmpl, nil
+}
+
+// displayColumns is the display setting laying picker entries out in
+// columns, see columnsDisplay.
+const displayColumns = "columns"
+
+// pathColumn is the width the directory column of picker entries is padded
+// to.
+const pathColumn = 28
+
+// displayName is how project is shown in the picker, its name unless a
+// display template or columns are configured, behind the prefix of its base.
+func (cfg *Config) displayName(project *Project) string {
+	// picker entries such as the stale toggle have no path
+	if cfg.Display == "" || project.FullPath == "" {
+		return project.basePrefix() + project.Name
+	}
+	if cfg.Display == displayColumns {
+		return columnsDisplay(project)
+	}
+	if cfg.display == nil {
+		tmpl, err := parseDisplay(cfg.Display)
+		if err != nil {
+			slog.Warn("ignoring display template", "err", err)
+			cfg.Display = ""
+			return project.basePrefix() + project.Name
+		}
+		cfg.display = tmpl
+	}
+
+	var b strings.Builder
+	if err := cfg.display.Execute(&b, newDisplayData(project)); err != nil {
+		slog.Debug("failed to render display template", "project", project.Name, "err", err)
+		return project.Name
+	}
+	return b.String()
+}
+
+// columnsDisplay lays out the picker entry of project in columns: its
+// parent directory, its name and the detected project types. The picker
+// matches typed text against the whole entry, so typing work api finds
+// ~/work/platform/api.
+func columnsDisplay(project *Project) string {
+	dir := project.FullPath
+	if _, rest, ok := strings.Cut(dir, "://"); ok {
+		// remote and not yet cloned projects
+		dir = rest
+	} else {
+		dir = filepath.ToSlash(tildePath(dir))
+	}
+	if i := strings.LastIndex(dir, "/"); i >= 0 {
+		dir = dir[:i+1]
+	}
+
+	// padded by the width on screen, which wide characters take two of
+	pad := max(pathColumn-runewidth.StringWidth(dir), 0)
+	label := dir + strings.Repeat(" ", pad) + "  " + project.basePrefix() + project.Name
+	if types := detectTypes(project); len(types) > 0 {
+		label += "  " + strings.Join(types, " ")
+	}
+	return label
+}
+
+// homePath returns p relative to $HOME, or p itself when it is not below
+// $HOME.
+func homePath(p string) string {
+	homedir, err := os.UserHomeDir()
+	if err != nil {
+		return p
+	}
+	rel, err := filepath.Rel(homedir, p)
+	if err != nil || rel == ".." || strings.HasPrefix(rel, "../") {
+		return p
+	}
+	return rel
+}
+
+// tildePath abbreviates $HOME in p to ~.
+func tildePath(p string) string {
+	rel := homePath(p)
+	switch {
+	case rel == p:
+		return p
+	case rel == ".":
+		return "~"
+	default:
+		return "~/" + rel
+	}
+}
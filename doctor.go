@@ -0,0 +1,167 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"os/exec"
+	"strings"
+
+	"github.com/bmatcuk/doublestar/v4"
+)
+
+// doctor collects the results of the checks of tmuxer doctor.
+type doctor struct {
+	problems int
+}
+
+func (d *doctor) ok(format string, args ...any) {
+	fmt.Printf("ok    "+format+"\n", args...)
+}
+
+// fail reports a failed check along with how to fix it.
+func (d *doctor) fail(fix string, format string, args ...any) {
+	d.problems++
+	fmt.Printf("FAIL  %s\n", strings.ReplaceAll(fmt.Sprintf(format, args...), "\n", "\n      "))
+	fmt.Printf("      fix: %s\n", fix)
+}
+
+// runDoctorCommand checks the installation and configuration of tmuxer and
+// suggests fixes for the problems found. It runs even when the config does
+// not load.
+func runDoctorCommand(cfg *Config, args []string) error {
+	if len(args) != 0 {
+		return errors.New("usage: tmuxer doctor")
+	}
+	d := &doctor{}
+
+	d.checkConfig()
+	d.checkBackend(cfg)
+	for _, b := range cfg.ProjectBase {
+		d.checkBase(b)
+	}
+	if dir, err := cacheDir(); err == nil {
+		d.checkWritable("cache", dir)
+	}
+	if dir, err := dataDir(); err == nil {
+		d.checkWritable("data", dir)
+	}
+	for _, hook := range append(append([]Hook{}, cfg.Hooks.OnCreate...), cfg.Hooks.OnOpen...) {
+		d.checkHook(hook.Run)
+	}
+
+	if d.problems > 0 {
+		return fmt.Errorf("found %d problem(s)", d.problems)
+	}
+	return nil
+}
+
+func (d *doctor) checkConfig() {
+	switch _, err := loadConfig(loadedConfigPath); {
+	case loadedConfigPath == "" || loadedConfigPath == "-":
+		d.ok("running without a config file")
+	case errors.Is(err, fs.ErrNotExist):
+		d.ok("no config file at %s, using defaults", loadedConfigPath)
+	case err != nil:
+		d.fail("correct the config file, tmuxer help config lists the keys it supports", "config: %s", err)
+	default:
+		d.ok("config %s", loadedConfigPath)
+	}
+}
+
+func (d *doctor) checkBackend(cfg *Config) {
+	name := cfg.backend().Command()
+	if cfg.usesTmux() && tmuxInWSL {
+		// tmux is looked for inside WSL by tmux -V below
+		name = "wsl.exe"
+	}
+	if _, err := exec.LookPath(name); err != nil {
+		if cfg.usesTmux() {
+			d.fail("install tmux, or use --no-tmux to open projects in a shell", "tmux is not installed")
+		} else {
+			d.fail(fmt.Sprintf("install %s or change backend", name), "%s is not installed", name)
+		}
+		return
+	}
+	if !cfg.usesTmux() {
+		d.ok("%s is installed", name)
+		return
+	}
+
+	v, err := currentTmuxVersion()
+	if err != nil && tmuxInWSL {
+		d.fail("install tmux in the default WSL distribution", "%s", err)
+		return
+	}
+	if err != nil {
+		d.fail("check that tmux -V works", "%s", err)
+		return
+	}
+	if !v.atLeast(minTmuxVersion) {
+		d.fail(fmt.Sprintf("upgrade tmux to %s or newer", minTmuxVersion), "tmux %s is too old", v)
+		return
+	}
+	d.ok("tmux %s", v)
+}
+
+func (d *doctor) checkBase(b *Base) {
+	if isRemoteBase(b.Path) {
+		d.ok("base %s is remote and checked when scanned", b.Path)
+		return
+	}
+	root, _ := doublestar.SplitPattern(b.Path)
+	info, err := os.Stat(root)
+	switch {
+	case err != nil:
+		d.fail(fmt.Sprintf("create %s or remove the base from the config", root), "base %s: %s does not exist", b.Path, root)
+	case !info.IsDir():
+		d.fail("point the base at a directory", "base %s: %s is not a directory", b.Path, root)
+	default:
+		if _, err := os.ReadDir(root); err != nil {
+			d.fail(fmt.Sprintf("make %s readable, e.g. chmod u+rx %s", root, shellQuote(root)), "base %s: %s", b.Path, err)
+			return
+		}
+		d.ok("base %s", b.Path)
+	}
+}
+
+// checkWritable checks that files can be created in dir, creating it when
+// needed.
+func (d *doctor) checkWritable(what, dir string) {
+	fix := fmt.Sprintf("make %s writable, e.g. chmod u+rwx %s", dir, shellQuote(dir))
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		d.fail(fix, "%s directory: %s", what, err)
+		return
+	}
+	f, err := os.CreateTemp(dir, "doctor-*")
+	if err != nil {
+		d.fail(fix, "%s directory: %s", what, err)
+		return
+	}
+	f.Close()
+	os.Remove(f.Name())
+	d.ok("%s directory %s is writable", what, dir)
+}
+
+// checkHook checks that the program a hook starts with can be run. Hooks
+// starting with a template cannot be checked.
+func (d *doctor) checkHook(hook string) {
+	fields := strings.Fields(hook)
+	if len(fields) == 0 || strings.Contains(fields[0], "{{") {
+		return
+	}
+	program := fields[0]
+	// command -v also finds functions and builtins, cmd has no equivalent
+	var err error
+	if hasSh() {
+		err = shellCommand("command -v " + shellQuote(program)).Run()
+	} else {
+		_, err = exec.LookPath(program)
+	}
+	if err != nil {
+		d.fail(fmt.Sprintf("install %s, or make it executable with chmod +x when it is a script", program), "hook %q: %s is not an executable command", hook, program)
+		return
+	}
+	d.ok("hook %q", hook)
+}
@@ -0,0 +1,97 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+)
+
+// editorWindow fills in the name and command of an editor window of project
+// that leaves them out.
+func editorWindow(cfg *Config, project *Project, w Window) Window {
+	if w.Name == "" {
+		w.Name = "editor"
+	}
+	if w.Command == "" {
+		w.Command = cfg.editorCommand(project)
+	}
+	return w
+}
+
+// runEditCommand opens the session of the project with the given name and
+// switches to its editor window, creating the window when the session has
+// none.
+func runEditCommand(cfg *Config, args []string) error {
+	if len(args) != 1 {
+		return errors.New("usage: tmuxer edit <project>")
+	}
+	if !cfg.usesTmux() || withoutTmux() {
+		return errors.New("tmuxer edit needs tmux")
+	}
+
+	projects, err := findProjectDirectories(cfg)
+	if err != nil {
+		return err
+	}
+	project := findProjectByName(projects, args[0])
+	if project == nil {
+		return fmt.Errorf("project %q not found", args[0])
+	}
+	if project.Remote != nil {
+		return fmt.Errorf("project %q is remote, tmuxer edit only opens local projects", args[0])
+	}
+
+	if err := runActions(cfg, project, actionAttach); err != nil {
+		return err
+	}
+	if err := focusEditor(cfg, project); err != nil {
+		return err
+	}
+	if *detach {
+		return nil
+	}
+	return attachAction(&actionState{cfg: cfg, project: project, existing: existingSwitch})
+}
+
+// focusEditor selects the editor window of the project session: the window
+// of the layout marked as editor, or a window named editor. It is created
+// when missing.
+func focusEditor(cfg *Config, project *Project) error {
+	layout, err := cfg.layoutFor(project)
+	if err != nil {
+		return err
+	}
+	if layout == nil {
+		layout = &Layout{}
+	}
+	editor := Window{Editor: true}
+	for _, w := range layout.Windows {
+		if w.Editor {
+			editor = w
+			break
+		}
+	}
+	rendered, err := (&Layout{Windows: []Window{editor}}).render(cfg, project)
+	if err != nil {
+		return err
+	}
+	w := rendered.Windows[0]
+
+	windows, err := listWindows(project.Session)
+	if err != nil {
+		return err
+	}
+	for _, existing := range windows {
+		if existing.Name == w.Name {
+			return runTmuxCommand("select-window", "-t", existing.ID)
+		}
+	}
+
+	target, err := newLayoutWindow(project, w)
+	if err != nil {
+		return err
+	}
+	if err := fillWindow(project, w, target, true, 0); err != nil {
+		return err
+	}
+	return runTmuxCommand("select-window", "-t", target)
+}
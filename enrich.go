@@ -0,0 +1,179 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"log/slog"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	enrichmentFile = "enrichment.json"
+	// enrichmentTTL is how long git information about a project is reused
+	// before asking git again.
+	enrichmentTTL = 2 * time.Minute
+	// enrichmentWorkers bounds the number of concurrent git processes.
+	enrichmentWorkers = 8
+)
+
+// Enrichment is git information about a project, cached between runs.
+type Enrichment struct {
+	Branch string `json:"branch"`
+	Dirty  bool   `json:"dirty"`
+	// LastCommit is the commit time of HEAD, zero without commits.
+	LastCommit time.Time `json:"last_commit"`
+	UpdatedAt  time.Time `json:"updated_at"`
+}
+
+// cacheDir is where tmuxer keeps data that can be recomputed.
+func cacheDir() (string, error) {
+	if dir := os.Getenv("XDG_CACHE_HOME"); dir != "" {
+		return filepath.Join(dir, "tmuxer"), nil
+	}
+	return normalizePath("~/.cache/tmuxer")
+}
+
+// enrichProjects sets Git on every local project that is a git repository,
+// using cached information when it is fresh enough. It returns how many
+// projects were served from the cache and how many asked git.
+func enrichProjects(projects []*Project) (hits, misses int) {
+	cache := loadEnrichmentCache()
+
+	var (
+		mu   sync.Mutex
+		wg   sync.WaitGroup
+		work = make(chan *Project)
+	)
+	for i := 0; i < enrichmentWorkers; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for project := range work {
+				e, err := gitEnrichment(project.FullPath)
+				if err != nil {
+					slog.Debug("failed to read git status", "project", project.Name, "err", err)
+					continue
+				}
+				mu.Lock()
+				cache[project.FullPath] = e
+				mu.Unlock()
+				project.Git = e
+			}
+		}()
+	}
+
+	for _, project := range projects {
+		// entries such as the stale toggle have no directory
+		if project.Remote != nil || project.FullPath == "" {
+			continue
+		}
+		if e, ok := cache[project.FullPath]; ok && time.Since(e.UpdatedAt) < enrichmentTTL {
+			project.Git = e
+			hits++
+			continue
+		}
+		work <- project
+		misses++
+	}
+	close(work)
+	wg.Wait()
+
+	slog.Debug("enriched projects", "projects", len(projects), "cache_hits", hits)
+	if err := saveEnrichmentCache(cache); err != nil {
+		slog.Warn("failed to save enrichment cache", "err", err)
+	}
+	return hits, misses
+}
+
+func gitEnrichment(dir string) (*Enrichment, error) {
+	output, err := commandOutput(exec.CommandContext(appContext, "git", "-C", dir, "status", "--porcelain=v1", "--branch"))
+	if err != nil {
+		return nil, err
+	}
+
+	lines := strings.Split(strings.TrimRight(string(output), "\n"), "\n")
+	e := &Enrichment{UpdatedAt: time.Now()}
+	if len(lines) > 0 && strings.HasPrefix(lines[0], "## ") {
+		branch := strings.TrimPrefix(lines[0], "## ")
+		branch, _, _ = strings.Cut(branch, "...")
+		e.Branch = strings.TrimPrefix(branch, "No commits yet on ")
+		lines = lines[1:]
+	}
+	e.Dirty = len(lines) > 0 && lines[0] != ""
+
+	if output, err := commandOutput(exec.CommandContext(appContext, "git", "-C", dir, "log", "-1", "--format=%ct")); err == nil {
+		if sec, err := strconv.ParseInt(strings.TrimSpace(string(output)), 10, 64); err == nil {
+			e.LastCommit = time.Unix(sec, 0)
+		}
+	}
+	return e, nil
+}
+
+func enrichmentCachePath() (string, error) {
+	dir, err := cacheDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, enrichmentFile), nil
+}
+
+func loadEnrichmentCache() map[string]*Enrichment {
+	cache := make(map[string]*Enrichment)
+
+	p, err := enrichmentCachePath()
+	if err != nil {
+		return cache
+	}
+	data, err := os.ReadFile(p)
+	if err != nil {
+		if !errors.Is(err, os.ErrNotExist) {
+			slog.Warn("failed to read enrichment cache", "err", err)
+		}
+		return cache
+	}
+	if err := json.Unmarshal(data, &cache); err != nil {
+		slog.Warn("ignoring corrupt enrichment cache", "err", err)
+		return make(map[string]*Enrichment)
+	}
+	return cache
+}
+
+func saveEnrichmentCache(cache map[string]*Enrichment) error {
+	p, err := enrichmentCachePath()
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(p), 0o755); err != nil {
+		return err
+	}
+
+	data, err := json.Marshal(cache)
+	if err != nil {
+		return err
+	}
+
+	// write to a temporary file first so concurrent runs never read a
+	// partially written cache
+	tmp := p + ".tmp"
+	if err := os.WriteFile(tmp, data, 0o644); err != nil {
+		return err
+	}
+	return os.Rename(tmp, p)
+}
+
+// filterDirty returns the projects with uncommitted changes.
+func filterDirty(projects []*Project) []*Project {
+	var dirty []*Project
+	for _, project := range projects {
+		if project.Git != nil && project.Git.Dirty {
+			dirty = append(dirty, project)
+		}
+	}
+	return dirty
+}
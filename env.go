@@ -0,0 +1,45 @@
+package main
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// projectEnv returns the environment configured for project as sorted
+// NAME=value pairs. Variant variables override per-project ones (from the
+// config or .tmuxer.yaml), which override per-base ones, which in turn
+// override the global env. Values starting with secret: are resolved, see
+// resolveSecret.
+func (cfg *Config) projectEnv(project *Project) ([]string, error) {
+	merged := make(map[string]string)
+	for k, v := range cfg.Env {
+		merged[k] = v
+	}
+	if project.Base != nil {
+		for k, v := range project.Base.Env {
+			merged[k] = v
+		}
+	}
+	for k, v := range cfg.projectConfig(project).Env {
+		merged[k] = v
+	}
+	if v := cfg.Variants[project.Variant]; v != nil {
+		for k, v := range v.Env {
+			merged[k] = v
+		}
+	}
+
+	env := make([]string, 0, len(merged))
+	for k, v := range merged {
+		if ref, ok := strings.CutPrefix(v, secretPrefix); ok {
+			var err error
+			if v, err = cfg.resolveSecret(ref); err != nil {
+				return nil, fmt.Errorf("env %s: %w", k, err)
+			}
+		}
+		env = append(env, k+"="+v)
+	}
+	sort.Strings(env)
+	return env, nil
+}
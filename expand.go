@@ -0,0 +1,110 @@
+package main
+
+import (
+	"os"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// expandConfig expands environment variables and ~ in the string values of
+// the config document node, see expandString. Keys are left as they are.
+func expandConfig(node *yaml.Node) {
+	home, _ := os.UserHomeDir()
+	expanded := make(map[*yaml.Node]bool)
+	var walk func(n *yaml.Node)
+	walk = func(n *yaml.Node) {
+		// aliases share the node of their anchor, which is expanded once
+		if n == nil || expanded[n] {
+			return
+		}
+		expanded[n] = true
+		switch n.Kind {
+		case yaml.ScalarNode:
+			if n.ShortTag() == "!!str" {
+				n.Value = expandString(n.Value, os.LookupEnv, home)
+			}
+		case yaml.MappingNode:
+			for i := 1; i < len(n.Content); i += 2 {
+				walk(n.Content[i])
+			}
+		case yaml.DocumentNode, yaml.SequenceNode:
+			for _, c := range n.Content {
+				walk(c)
+			}
+		}
+	}
+	walk(node)
+}
+
+// expandString replaces ${VAR} in s with the value of the environment
+// variable VAR and ${VAR:-default} with default when VAR is unset or empty.
+// A ~ at the start of the result followed by a slash or nothing else
+// becomes home.
+//
+// Variables that are not set and have no default are kept, as are the
+// TMUXER_ variables set when hooks and commands run and $VAR without
+// braces, so the shell running them expands them, which is also how $${VAR}
+// keeps a literal ${VAR}. A TMUXER_ variable set while loading the config,
+// such as in a hook of tmuxer itself, would be stale by then.
+func expandString(s string, lookup func(string) (string, bool), home string) string {
+	s = expandVars(s, lookup)
+	if home != "" && (s == "~" || strings.HasPrefix(s, "~/") || strings.HasPrefix(s, `~\`)) {
+		s = home + s[1:]
+	}
+	return s
+}
+
+// expandVars expands the variables in s for expandString.
+func expandVars(s string, lookup func(string) (string, bool)) string {
+	if !strings.Contains(s, "${") {
+		return s
+	}
+
+	var b strings.Builder
+	for {
+		i := strings.Index(s, "${")
+		if i < 0 {
+			b.WriteString(s)
+			return b.String()
+		}
+		if i > 0 && s[i-1] == '$' {
+			// $${VAR} is an escaped ${VAR}
+			b.WriteString(s[:i-1] + "${")
+			s = s[i+2:]
+			continue
+		}
+		end := strings.IndexByte(s[i:], '}')
+		if end < 0 {
+			b.WriteString(s)
+			return b.String()
+		}
+		end += i
+		name, fallback, hasDefault := strings.Cut(s[i+2:end], ":-")
+		value, set := lookup(name)
+		switch {
+		case !isEnvName(name), strings.HasPrefix(name, "TMUXER_"):
+			// shell syntax such as ${#list} or ${1}, or tmuxer's own
+			value = s[i : end+1]
+		case hasDefault && value == "":
+			value = fallback
+		case !set:
+			value = s[i : end+1]
+		}
+		b.WriteString(s[:i] + value)
+		s = s[end+1:]
+	}
+}
+
+// isEnvName reports whether name is a valid environment variable name.
+func isEnvName(name string) bool {
+	if name == "" {
+		return false
+	}
+	for i, c := range name {
+		if c != '_' && (c < 'a' || c > 'z') && (c < 'A' || c > 'Z') && (i == 0 || c < '0' || c > '9') {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,167 @@
+package main
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestExpandString(t *testing.T) {
+	env := map[string]string{
+		"SRC":   "/src",
+		"USER":  "me",
+		"EMPTY": "",
+		// set when tmuxer runs from a hook of another session
+		"TMUXER_PROJECT_PATH": "/src/other",
+	}
+	lookup := func(name string) (string, bool) {
+		v, ok := env[name]
+		return v, ok
+	}
+
+	tests := []struct {
+		in, want string
+	}{
+		{"${SRC}/api", "/src/api"},
+		{"${SRC}/${USER}", "/src/me"},
+		{"prefix-${USER}-suffix", "prefix-me-suffix"},
+		{"~", "/home/me"},
+		{"~/src", "/home/me/src"},
+		{"~other/src", "~other/src"},
+		{"cd ~/src", "cd ~/src"},
+		{"${MISSING}/api", "${MISSING}/api"},
+		{"${MISSING:-/opt}/api", "/opt/api"},
+		{"${MISSING:-~/src}/api", "/home/me/src/api"},
+		{"${EMPTY:-fallback}", "fallback"},
+		{"${EMPTY}", ""},
+		{"${SRC:-/opt}", "/src"},
+		{"$${SRC}", "${SRC}"},
+		{"echo $$${SRC}", "echo $${SRC}"},
+		{"$SRC/api", "$SRC/api"},
+		{"${1} ${#list} ${a-b}", "${1} ${#list} ${a-b}"},
+		{"cd ${TMUXER_PROJECT_PATH}", "cd ${TMUXER_PROJECT_PATH}"},
+		{"${TMUXER_PROJECT_PATH:-/src}", "${TMUXER_PROJECT_PATH:-/src}"},
+		{"${SRC", "${SRC"},
+		{"no variables", "no variables"},
+	}
+	for _, tt := range tests {
+		if got := expandString(tt.in, lookup, "/home/me"); got != tt.want {
+			t.Errorf("expandString(%q) = %q, want %q", tt.in, got, tt.want)
+		}
+	}
+}
+
+func TestDecodeExpandsConfig(t *testing.T) {
+	t.Setenv("EXPAND_TEST_SRC", "/src")
+	t.Setenv("HOME", "/home/me")
+	config := `
+base:
+  - path: ${EXPAND_TEST_SRC}/*
+  - ~/work/*
+hooks:
+  on_create: ["echo ${EXPAND_TEST_SRC} $${TMUXER_PROJECT_NAME}"]
+env:
+  GOPATH: ~/go
+layouts:
+  ${EXPAND_TEST_SRC}:
+    windows:
+      - command: ${EXPAND_TEST_UNSET:-make} run
+`
+	cfg := &Config{}
+	if err := decodeStrict("config.yaml", []byte(config), cfg); err != nil {
+		t.Fatal(err)
+	}
+
+	var bases []string
+	for _, b := range cfg.ProjectBase {
+		bases = append(bases, b.Path)
+	}
+	if want := []string{"/src/*", "/home/me/work/*"}; !reflect.DeepEqual(bases, want) {
+		t.Errorf("bases = %q, want %q", bases, want)
+	}
+	if got, want := cfg.Hooks.OnCreate[0].Run, "echo /src ${TMUXER_PROJECT_NAME}"; got != want {
+		t.Errorf("hook = %q, want %q", got, want)
+	}
+	if got := cfg.Env["GOPATH"]; got != "/home/me/go" {
+		t.Errorf("env GOPATH = %q, want /home/me/go", got)
+	}
+	layout, ok := cfg.Layouts["${EXPAND_TEST_SRC}"]
+	if !ok {
+		t.Fatalf("layout names were expanded: %v", cfg.Layouts)
+	}
+	if got := layout.Windows[0].Command; got != "make run" {
+		t.Errorf("window command = %q, want make run", got)
+	}
+}
+
+func TestDecodeAnchors(t *testing.T) {
+	t.Setenv("EXPAND_TEST_EDITOR", "nvim")
+	config := `
+x-windows:
+  editor: &editor
+    name: editor
+    command: ${EXPAND_TEST_EDITOR}
+  shell: &shell
+    name: shell
+layouts:
+  go:
+    windows:
+      - *editor
+      - <<: *shell
+        command: go test ./...
+  node:
+    windows:
+      - *editor
+      - <<: [*shell, *editor]
+        name: server
+`
+	cfg := &Config{}
+	if err := decodeStrict("config.yaml", []byte(config), cfg); err != nil {
+		t.Fatal(err)
+	}
+
+	want := map[string][]Window{
+		"go": {
+			{Name: "editor", Command: "nvim"},
+			{Name: "shell", Command: "go test ./..."},
+		},
+		"node": {
+			{Name: "editor", Command: "nvim"},
+			{Name: "server", Command: "nvim"},
+		},
+	}
+	for name, windows := range want {
+		if got := cfg.Layouts[name].Windows; !reflect.DeepEqual(got, windows) {
+			t.Errorf("layout %s windows = %+v, want %+v", name, got, windows)
+		}
+	}
+}
+
+func TestDecodeAnchorsReportsUnknownFields(t *testing.T) {
+	config := `
+x-window: &window
+  name: editor
+  comand: nvim
+layouts:
+  go:
+    windows:
+      - *window
+      - <<: *window
+        nam: shell
+  node:
+    windows:
+      - *window
+`
+	err := decodeStrict("config.yaml", []byte(config), &Config{})
+	if err == nil {
+		t.Fatal("decodeStrict() succeeded")
+	}
+	problems := err.(*ConfigError).Problems
+	want := []string{
+		`line 4: unknown field "comand" in layouts.go.windows[0], did you mean "command"?`,
+		`line 10: unknown field "nam" in layouts.go.windows[1], did you mean "name"?`,
+	}
+	if !reflect.DeepEqual(problems, want) {
+		t.Errorf("problems =\n%s\nwant\n%s", strings.Join(problems, "\n"), strings.Join(want, "\n"))
+	}
+}
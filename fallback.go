@@ -0,0 +1,86 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"log/slog"
+	"os"
+	"os/exec"
+	"runtime"
+	"strings"
+)
+
+// withoutTmux reports whether projects are opened without tmux, either
+// because --no-tmux was given or tmux is not installed.
+func withoutTmux() bool {
+	if *noTmux {
+		return true
+	}
+	if tmuxInWSL {
+		return false
+	}
+	if _, err := exec.LookPath("tmux"); err != nil {
+		slog.Info("tmux not found, opening projects without it")
+		return true
+	}
+	return false
+}
+
+// openWithoutTmux is used instead of the action chain when tmux is not
+// available. When stdout is a terminal it starts $SHELL in the project
+// directory, otherwise it prints the path so a shell function can cd there:
+//
+//	t() { cd "$(tmuxer --no-tmux)"; }
+func openWithoutTmux(cfg *Config, project *Project) error {
+	if err := ensureCloneAction(&actionState{cfg: cfg, project: project}); err != nil {
+		return err
+	}
+	if err := recordHistory(historyEventOpen, project); err != nil {
+		slog.Warn("failed to record history", "err", err)
+	}
+
+	if !isTerminal(os.Stdout) {
+		fmt.Println(project.FullPath)
+		return nil
+	}
+
+	var cmd *exec.Cmd
+	if project.Remote != nil {
+		remote := "cd " + remotePathArg(project.Remote.Path) + " && exec $SHELL -l"
+		args := append([]string{"-t"}, project.Remote.sshArgs()...)
+		cmd = exec.Command("ssh", append(args, remote)...)
+	} else {
+		cmd = exec.Command(loginShell())
+		cmd.Dir = project.FullPath
+	}
+	cmd.Env = append(os.Environ(), "TMUXER_PROJECT_NAME="+project.Name, "TMUXER_PROJECT_PATH="+project.FullPath)
+	cmd.Stdin, cmd.Stdout, cmd.Stderr = os.Stdin, os.Stdout, os.Stderr
+
+	slog.Debug("starting shell", "args", strings.Join(cmd.Args, " "), "dir", cmd.Dir)
+	if err := runCommand(cmd); err != nil {
+		// the exit status of the last command run in the shell
+		if _, ok := err.(*exec.ExitError); ok {
+			return nil
+		}
+		return fmt.Errorf("failed to start shell: %w", err)
+	}
+	return nil
+}
+
+// errNoTerminal is returned by pickers that cannot fall back to anything
+// when there is no terminal.
+var errNoTerminal = errors.New("no terminal to show the picker on")
+
+// hasTerminal reports whether the picker can be shown. It draws on the
+// controlling terminal rather than stdin and stdout, which may be pipes.
+func hasTerminal() bool {
+	if runtime.GOOS == "windows" {
+		return isTerminal(os.Stdin)
+	}
+	tty, err := os.Open("/dev/tty")
+	if err != nil {
+		return false
+	}
+	tty.Close()
+	return true
+}
@@ -0,0 +1,10 @@
+//go:build windows
+
+package main
+
+import "os"
+
+// flock does nothing on windows, where sessions are created without a lock.
+func flock(*os.File) error {
+	return nil
+}
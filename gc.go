@@ -0,0 +1,75 @@
+package main
+
+import (
+	"errors"
+	"log/slog"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// runGCCommand kills the idle project sessions, see reapIdleSessions.
+func runGCCommand(cfg *Config, _ []string) error {
+	killed, err := reapIdleSessions(cfg)
+	if err != nil {
+		return err
+	}
+	if len(killed) > 0 {
+		notify(cfg, "Killed idle sessions %s", strings.Join(killed, ", "))
+	}
+	return nil
+}
+
+// reapIdleSessions kills the sessions created or adopted by tmuxer that
+// have no client attached and no activity for longer than idle_timeout,
+// returning their names. Sessions of pinned projects are kept.
+func reapIdleSessions(cfg *Config) ([]string, error) {
+	if cfg.IdleTimeout == "" {
+		return nil, errors.New("idle_timeout is not set in the config")
+	}
+	timeout, err := parseAge(cfg.IdleTimeout)
+	if err != nil {
+		return nil, err
+	}
+
+	sessions, err := tmuxClient.ListSessions(projectOption)
+	if err != nil {
+		return nil, err
+	}
+
+	entries, err := readHistory()
+	if err != nil {
+		slog.Warn("failed to read history", "err", err)
+	}
+
+	pinned, err := loadPinned()
+	if err != nil {
+		return nil, err
+	}
+
+	var killed []string
+	for _, s := range sessions {
+		name, path := s.Name, s.Options[projectOption]
+		if path == "" || s.Activity.IsZero() || s.Attached() || contains(pinned, path) {
+			continue
+		}
+
+		idle := time.Since(s.Activity)
+		if idle < timeout {
+			continue
+		}
+		slog.Info("killing idle session", "session", name, "idle", idle.Round(time.Second))
+		if err := runTmuxCommand("kill-session", "-t", "="+name); err != nil {
+			return killed, err
+		}
+		killed = append(killed, name)
+		project, ok := sessionProject(entries, name)
+		if !ok {
+			project = &Project{Name: filepath.Base(path), FullPath: path, Session: name}
+		}
+		if err := recordHistory(historyEventKill, project); err != nil {
+			slog.Warn("failed to record history", "err", err)
+		}
+	}
+	return killed, nil
+}
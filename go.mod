@@ -1,9 +1,8 @@
 module github.com/k1ng440/tmuxer
 
-go 1.20
+go 1.21
 
 require (
-	github.com/ktr0731/go-fuzzyfinder v0.7.0
 	github.com/spf13/pflag v1.0.5
 	gopkg.in/yaml.v3 v3.0.1
 )
@@ -11,14 +10,14 @@ require (
 require (
 	github.com/bmatcuk/doublestar/v4 v4.6.1
 	github.com/gdamore/encoding v1.0.0 // indirect
-	github.com/gdamore/tcell/v2 v2.5.3 // indirect
-	github.com/ktr0731/go-ansisgr v0.1.0 // indirect
+	github.com/gdamore/tcell/v2 v2.5.3
+	github.com/ktr0731/go-ansisgr v0.1.0
 	github.com/lucasb-eyer/go-colorful v1.2.0 // indirect
-	github.com/mattn/go-runewidth v0.0.14 // indirect
-	github.com/nsf/termbox-go v1.1.1 // indirect
-	github.com/pkg/errors v0.9.1 // indirect
+	github.com/mattn/go-runewidth v0.0.14
+	github.com/nsf/termbox-go v1.1.1
+	github.com/pkg/errors v0.9.1
 	github.com/rivo/uniseg v0.4.2 // indirect
-	golang.org/x/sys v0.1.0 // indirect
+	golang.org/x/sys v0.1.0
 	golang.org/x/term v0.1.0 // indirect
 	golang.org/x/text v0.4.0 // indirect
 )
@@ -0,0 +1,113 @@
+package main
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+)
+
+// readmeNames are checked in order for the title of a project.
+var readmeNames = []string{"README.md", "README.markdown", "README.rst", "README.txt", "README"}
+
+// runGrepCommand shows the picker with only the projects whose name or path
+// matches pattern, a case-insensitive regular expression. With --content the
+// README title and go.mod module name of projects are searched too. A single
+// match is opened without the picker.
+func runGrepCommand(cfg *Config, args []string) error {
+	if len(args) != 1 {
+		return errors.New("usage: tmuxer grep <pattern> [--content]")
+	}
+	re, err := regexp.Compile("(?i)" + args[0])
+	if err != nil {
+		return fmt.Errorf("invalid pattern %q: %w", args[0], err)
+	}
+
+	projects, err := findProjectDirectories(cfg)
+	if err != nil {
+		return err
+	}
+
+	var matches []*Project
+	for _, project := range projects {
+		if projectMatches(project, re, *grepContent) {
+			matches = append(matches, project)
+		}
+	}
+	switch len(matches) {
+	case 0:
+		return fmt.Errorf("no project matches %q", args[0])
+	case 1:
+		return runActions(cfg, matches[0])
+	}
+
+	cfg.annotateSessions(matches)
+	project, err := selectProjectDirectory(cfg, matches)
+	if err != nil {
+		return err
+	}
+	return runActions(cfg, project)
+}
+
+// projectMatches reports whether the name or path of project, or with
+// content its README title or Go module name, matches re.
+func projectMatches(project *Project, re *regexp.Regexp, content bool) bool {
+	if re.MatchString(project.basePrefix()+project.Name) || re.MatchString(project.FullPath) {
+		return true
+	}
+	if !content || project.Remote != nil || project.Repo != "" {
+		return false
+	}
+	for _, s := range []string{readmeTitle(project.FullPath), goModule(project.FullPath)} {
+		if s != "" && re.MatchString(s) {
+			return true
+		}
+	}
+	return false
+}
+
+// readmeTitle returns the first heading of the README in dir, or its first
+// non-empty line when it has no markdown heading.
+func readmeTitle(dir string) string {
+	for _, name := range readmeNames {
+		f, err := os.Open(filepath.Join(dir, name))
+		if err != nil {
+			continue
+		}
+		defer f.Close()
+
+		first := ""
+		scanner := bufio.NewScanner(f)
+		for scanner.Scan() {
+			line := strings.TrimSpace(scanner.Text())
+			if title, ok := strings.CutPrefix(line, "#"); ok {
+				return strings.TrimSpace(strings.TrimLeft(title, "#"))
+			}
+			if first == "" {
+				first = line
+			}
+		}
+		return first
+	}
+	return ""
+}
+
+// goModule returns the module path declared in the go.mod of dir.
+func goModule(dir string) string {
+	f, err := os.Open(filepath.Join(dir, "go.mod"))
+	if err != nil {
+		return ""
+	}
+	defer f.Close()
+
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		if module, ok := strings.CutPrefix(strings.TrimSpace(scanner.Text()), "module "); ok {
+			return strings.Trim(strings.TrimSpace(module), `"`)
+		}
+	}
+	return ""
+}
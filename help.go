@@ -0,0 +1,198 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"reflect"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/spf13/pflag"
+)
+
+const description = "tmuxer opens a tmux session for a project picked from the directories found below the configured bases, creating it with the configured layout when it does not exist yet."
+
+const configPathHelp = "$XDG_CONFIG_HOME/tmuxer/config.yaml"
+
+// examples are shown by --help and in the man page.
+var examples = []string{
+	"tmuxer -b '~/src/*/{.git}'  # pick a project below ~/src and open its session",
+	"cd \"$(tmuxer -p)\"          # pick a project and change into it",
+	"tmuxer --multi             # open several projects at once",
+	"tmuxer api                 # pick among the projects matching api, or open the only one",
+	"fd -t d . ~/work | tmuxer --stdin",
+}
+
+func init() {
+	commands["help"] = &command{
+		run:       runHelpCommand,
+		usage:     "[command | config | man]",
+		summary:   "Show help for a command or the config file, or print the man page",
+		examples:  []string{"tmuxer help open", "tmuxer help man > tmuxer.1"},
+		anyConfig: true,
+	}
+	pflag.Usage = func() {
+		printUsage(os.Stderr)
+	}
+}
+
+// runHelpCommand prints the overview, the help of a command, the config
+// schema or the man page.
+func runHelpCommand(_ *Config, args []string) error {
+	if len(args) == 0 {
+		printUsage(os.Stdout)
+		return nil
+	}
+	switch args[0] {
+	case "config":
+		fmt.Printf("The config file (%s) supports these keys:\n\n", configPathHelp)
+		printSchema(os.Stdout, reflect.TypeOf(Config{}))
+		return nil
+	case "man":
+		return writeManPage(os.Stdout)
+	}
+
+	cmd, ok := commands[args[0]]
+	if !ok {
+		problem := fmt.Sprintf("unknown command %q", args[0])
+		if suggestion := didYouMean(args[0], sortedKeys(commands)); suggestion != "" {
+			problem += fmt.Sprintf(", did you mean %q?", suggestion)
+		}
+		return fmt.Errorf("%s", problem)
+	}
+	fmt.Printf("Usage: tmuxer %s\n\n%s.\n", strings.TrimSpace(args[0]+" "+cmd.usage), cmd.summary)
+	if len(cmd.examples) > 0 {
+		fmt.Println("\nExamples:")
+		for _, e := range cmd.examples {
+			fmt.Println("  " + e)
+		}
+	}
+	return nil
+}
+
+func printUsage(w io.Writer) {
+	fmt.Fprintf(w, "Usage: tmuxer [flags] [command [args] | query]\n\n%s\n\nCommands:\n", description)
+	tw := tabwriter.NewWriter(w, 0, 4, 2, ' ', 0)
+	for _, name := range sortedKeys(commands) {
+		fmt.Fprintf(tw, "  %s\t%s\n", name, commands[name].summary)
+	}
+	tw.Flush()
+
+	fmt.Fprintf(w, "\nFlags:\n%s\nExamples:\n", pflag.CommandLine.FlagUsages())
+	for _, e := range examples {
+		fmt.Fprintln(w, "  "+e)
+	}
+	fmt.Fprintln(w, "\nRun 'tmuxer help <command>' for the usage of a command and 'tmuxer help config' for the config keys.")
+}
+
+// printSchema lists the yaml keys of the struct t and their types, with
+// the keys of nested objects indented below them.
+func printSchema(w io.Writer, t reflect.Type) {
+	tw := tabwriter.NewWriter(w, 0, 4, 2, ' ', 0)
+	var walk func(t reflect.Type, depth int)
+	walk = func(t reflect.Type, depth int) {
+		fields := yamlFields(t)
+		for _, name := range sortedKeys(fields) {
+			fmt.Fprintf(tw, "%s%s\t%s\n", strings.Repeat("  ", depth), name, schemaType(fields[name]))
+			// profiles hold the same keys as the config itself
+			if s := schemaStruct(fields[name]); s != nil && s != reflect.TypeOf(Config{}) {
+				walk(s, depth+1)
+			}
+		}
+	}
+	walk(t, 0)
+	tw.Flush()
+}
+
+// schemaStruct returns the struct described by t, either directly or as
+// the elements of a list or map.
+func schemaStruct(t reflect.Type) reflect.Type {
+	for {
+		switch t.Kind() {
+		case reflect.Pointer, reflect.Slice, reflect.Map:
+			t = t.Elem()
+		case reflect.Struct:
+			return t
+		default:
+			return nil
+		}
+	}
+}
+
+func schemaType(t reflect.Type) string {
+	switch t.Kind() {
+	case reflect.Pointer:
+		return schemaType(t.Elem())
+	case reflect.Slice:
+		return "list of " + schemaType(t.Elem())
+	case reflect.Map:
+		return "map of " + schemaType(t.Elem())
+	case reflect.Struct:
+		if t == reflect.TypeOf(Base{}) {
+			return "object or path"
+		}
+		if t == reflect.TypeOf(Config{}) {
+			return "config keys"
+		}
+		return "object"
+	case reflect.Int:
+		return "integer"
+	default:
+		return t.Kind().String()
+	}
+}
+
+// writeManPage writes the man page of tmuxer in roff.
+func writeManPage(w io.Writer) error {
+	esc := func(s string) string {
+		return strings.NewReplacer(`\`, `\\`, "-", `\-`, "'", `\(aq`).Replace(s)
+	}
+
+	var b strings.Builder
+	b.WriteString(".TH TMUXER 1 \"\" tmuxer\n")
+	b.WriteString(".SH NAME\ntmuxer \\- project tmux session manager\n")
+	b.WriteString(".SH SYNOPSIS\n.B tmuxer\n[\\fIflags\\fR] [\\fIcommand\\fR [\\fIargs\\fR] | \\fIquery\\fR]\n")
+	fmt.Fprintf(&b, ".SH DESCRIPTION\n%s\n", esc(description))
+
+	b.WriteString(".SH COMMANDS\n")
+	for _, name := range sortedKeys(commands) {
+		cmd := commands[name]
+		fmt.Fprintf(&b, ".TP\n.B %s", esc(name))
+		if cmd.usage != "" {
+			fmt.Fprintf(&b, " \\fI%s\\fR", esc(cmd.usage))
+		}
+		fmt.Fprintf(&b, "\n%s.\n", esc(cmd.summary))
+	}
+
+	b.WriteString(".SH OPTIONS\n")
+	pflag.CommandLine.VisitAll(func(f *pflag.Flag) {
+		b.WriteString(".TP\n")
+		if f.Shorthand != "" {
+			fmt.Fprintf(&b, "\\fB\\-%s\\fR, ", esc(f.Shorthand))
+		}
+		fmt.Fprintf(&b, "\\fB\\-\\-%s\\fR", esc(f.Name))
+		if name, _ := pflag.UnquoteUsage(f); name != "" {
+			fmt.Fprintf(&b, " \\fI%s\\fR", esc(name))
+		}
+		_, usage := pflag.UnquoteUsage(f)
+		fmt.Fprintf(&b, "\n%s\n", esc(usage))
+	})
+
+	b.WriteString(".SH EXAMPLES\n")
+	for _, e := range examples {
+		fmt.Fprintf(&b, ".nf\n%s\n.fi\n", esc(e))
+	}
+	for _, name := range sortedKeys(commands) {
+		for _, e := range commands[name].examples {
+			fmt.Fprintf(&b, ".nf\n%s\n.fi\n", esc(e))
+		}
+	}
+
+	fmt.Fprintf(&b, ".SH FILES\n.TP\n.I %s\nThe config file, see \\fBtmuxer help config\\fR.\n", esc(configPathHelp))
+	b.WriteString(".TP\n.I ~/.local/share/tmuxer/history.jsonl\nThe history of opened sessions.\n")
+	b.WriteString(".TP\n.I ~/.cache/tmuxer\nCached git information and repository listings.\n")
+
+	_, err := io.WriteString(w, b.String())
+	return err
+}
@@ -0,0 +1,86 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+
+	"github.com/bmatcuk/doublestar/v4"
+)
+
+// runHereCommand opens the project containing the working directory
+// without the picker. The project is the closest directory above it with
+// one of the markers, .git by default, or a .tmuxer directory.
+func runHereCommand(cfg *Config, args []string) error {
+	if len(args) != 0 {
+		return errors.New("usage: tmuxer here")
+	}
+	wd, err := os.Getwd()
+	if err != nil {
+		return err
+	}
+
+	markers := append([]string{localDir}, *projectMarkers...)
+	dir, marker, ok := findProjectRoot(wd, markers)
+	if !ok {
+		return fmt.Errorf("no project around %s, looked for %s", wd, strings.Join(markers, ", "))
+	}
+	if marker == localDir {
+		return openLocalProject(cfg, dir)
+	}
+	return runActions(cfg, hereProject(cfg, dir, marker))
+}
+
+// findProjectRoot walks up from dir to the first directory below the home
+// directory containing one of markers, and returns it along with the
+// marker found.
+func findProjectRoot(dir string, markers []string) (string, string, bool) {
+	home, _ := os.UserHomeDir()
+	for {
+		if dir == home || dir == filepath.Dir(dir) {
+			return "", "", false
+		}
+		for _, m := range markers {
+			if _, err := os.Stat(filepath.Join(dir, m)); err == nil {
+				return dir, m, true
+			}
+		}
+		dir = filepath.Dir(dir)
+	}
+}
+
+// hereProject returns the project at dir found through marker, named like
+// the scan of its base would name it so both open the same session.
+func hereProject(cfg *Config, dir, marker string) *Project {
+	for _, b := range cfg.ProjectBase {
+		if isRemoteBase(b.Path) {
+			continue
+		}
+		root, pattern := doublestar.SplitPattern(b.Path)
+		rel, err := filepath.Rel(root, dir)
+		if err != nil || rel == ".." || strings.HasPrefix(rel, "../") {
+			continue
+		}
+
+		p := filepath.ToSlash(rel)
+		patternUsed := len(globRegex.FindStringIndex(path.Base(pattern))) > 0
+		if patternUsed {
+			p = path.Join(p, marker)
+		}
+		if ok, _ := doublestar.Match(pattern, p); !ok {
+			continue
+		}
+		name := projectName(root, p, patternUsed)
+		project, _ := newProject(name, path.Join(root, name))
+		project.Base = b
+		project.Markers = []string{marker}
+		return project
+	}
+
+	project, _ := newProject(filepath.Base(dir), dir)
+	project.Markers = []string{marker}
+	return project
+}
@@ -0,0 +1,324 @@
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+	"time"
+)
+
+const historyFile = "history.jsonl"
+
+// HistoryEntry is a single line of the append-only history log.
+type HistoryEntry struct {
+	Time    time.Time `json:"time"`
+	Event   string    `json:"event"`
+	Name    string    `json:"name"`
+	Path    string    `json:"path"`
+	Session string    `json:"session,omitempty"`
+}
+
+const (
+	historyEventOpen   = "open"
+	historyEventCreate = "create"
+	// historyEventKill is recorded when tmuxer gc kills an idle session.
+	historyEventKill = "kill"
+	// events reported by tmux hooks, see tmuxer keybind --hooks
+	historyEventSessionClosed  = "session-closed"
+	historyEventClientDetached = "client-detached"
+)
+
+// dataDir is where tmuxer keeps state such as the history log.
+func dataDir() (string, error) {
+	if dir := os.Getenv("XDG_DATA_HOME"); dir != "" {
+		return filepath.Join(dir, "tmuxer"), nil
+	}
+	return normalizePath("~/.local/share/tmuxer")
+}
+
+func historyPath() (string, error) {
+	dir, err := dataDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, historyFile), nil
+}
+
+func recordHistory(event string, project *Project) error {
+	if *dryRun {
+		return nil
+	}
+	p, err := historyPath()
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(p), 0o755); err != nil {
+		return err
+	}
+
+	file, err := os.OpenFile(p, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0o644)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	return json.NewEncoder(file).Encode(HistoryEntry{
+		Time:    time.Now(),
+		Event:   event,
+		Name:    project.Name,
+		Path:    project.FullPath,
+		Session: project.Session,
+	})
+}
+
+// readHistory returns all history entries, oldest first.
+func readHistory() ([]HistoryEntry, error) {
+	p, err := historyPath()
+	if err != nil {
+		return nil, err
+	}
+
+	file, err := os.Open(p)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	var entries []HistoryEntry
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		var entry HistoryEntry
+		if err := json.Unmarshal(scanner.Bytes(), &entry); err != nil {
+			// skip lines truncated by concurrent writers
+			continue
+		}
+		entries = append(entries, entry)
+	}
+	return entries, scanner.Err()
+}
+
+// recentProjects returns up to limit distinct projects from the history,
+// most recently opened first.
+func recentProjects(limit int) ([]*Project, error) {
+	entries, err := readHistory()
+	if err != nil {
+		return nil, err
+	}
+
+	var (
+		projects []*Project
+		seen     = make(map[string]bool)
+	)
+	for i := len(entries) - 1; i >= 0 && len(projects) < limit; i-- {
+		entry := entries[i]
+		if entry.Event != historyEventOpen || seen[entry.Path] {
+			continue
+		}
+		seen[entry.Path] = true
+
+		project, err := projectFromHistory(entry)
+		if err != nil {
+			continue
+		}
+		projects = append(projects, project)
+	}
+	return projects, nil
+}
+
+// sessionProject returns the project last recorded with session, if any.
+func sessionProject(entries []HistoryEntry, session string) (*Project, bool) {
+	for i := len(entries) - 1; i >= 0; i-- {
+		if entries[i].Session == session {
+			return &Project{Name: entries[i].Name, FullPath: entries[i].Path, Session: session}, true
+		}
+	}
+	return nil, false
+}
+
+func projectFromHistory(entry HistoryEntry) (*Project, error) {
+	if isRemoteBase(entry.Path) {
+		remote, err := parseRemoteBase(entry.Path)
+		if err != nil {
+			return nil, err
+		}
+		return &Project{Name: entry.Name, FullPath: entry.Path, HomePath: entry.Path, Remote: remote}, nil
+	}
+
+	if _, err := os.Stat(entry.Path); err != nil {
+		return nil, err
+	}
+	return newProject(entry.Name, entry.Path)
+}
+
+// runLastCommand switches to the project used before the current one.
+func runLastCommand(cfg *Config, _ []string) error {
+	current, _ := tmuxClient.CurrentSession()
+
+	projects, err := recentProjects(2)
+	if err != nil {
+		return err
+	}
+
+	for _, project := range projects {
+		if cfg.sessionName(project) != current {
+			return runActions(cfg, project)
+		}
+	}
+	return errors.New("no previous project in history")
+}
+
+// runRecentCommand opens the picker with the most recently used projects.
+func runRecentCommand(cfg *Config, args []string) error {
+	limit := 10
+	if len(args) > 0 {
+		n, err := strconv.Atoi(args[0])
+		if err != nil || n <= 0 {
+			return fmt.Errorf("invalid number of projects %q", strings.Join(args, " "))
+		}
+		limit = n
+	}
+
+	projects, err := recentProjects(limit)
+	if err != nil {
+		return err
+	}
+	if len(projects) == 0 {
+		return errors.New("no projects in history")
+	}
+	cfg.annotateSessions(projects)
+
+	project, err := selectProjectDirectory(cfg, projects)
+	if err != nil {
+		return err
+	}
+	return runActions(cfg, project)
+}
+
+// maxTrackedGap caps the time counted for a project between two events,
+// so a session left attached overnight does not count.
+const maxTrackedGap = 4 * time.Hour
+
+// runHistoryCommand prints the history log, or with days a summary of how
+// often and how long each project was used per day. Both can be limited to
+// a single project.
+func runHistoryCommand(_ *Config, args []string) error {
+	days := len(args) > 0 && args[0] == "days"
+	if days {
+		args = args[1:]
+	}
+	if len(args) > 1 {
+		return errors.New("usage: tmuxer history [days] [project]")
+	}
+	project := ""
+	if len(args) == 1 {
+		project = args[0]
+	}
+
+	entries, err := readHistory()
+	if err != nil {
+		return err
+	}
+
+	w := tabwriter.NewWriter(os.Stdout, 0, 4, 2, ' ', 0)
+	if days {
+		fmt.Fprintln(w, "DAY\tPROJECT\tOPENED\tCREATED\tTIME")
+		for _, d := range historyDays(entries, time.Now()) {
+			if project == "" || d.Name == project {
+				fmt.Fprintf(w, "%s\t%s\t%d\t%d\t%s\n", d.Day, d.Name, d.Opened, d.Created, d.Time.Round(time.Minute))
+			}
+		}
+		return w.Flush()
+	}
+
+	for _, entry := range entries {
+		if project == "" || entry.Name == project {
+			fmt.Fprintf(w, "%s\t%s\t%s\t%s\n", entry.Time.Local().Format("2006-01-02 15:04"), entry.Event, entry.Name, entry.Session)
+		}
+	}
+	return w.Flush()
+}
+
+// historyDay is the use of a project on one day.
+type historyDay struct {
+	Day     string
+	Name    string
+	Opened  int
+	Created int
+	// Time is counted from opening the project until the next event ends
+	// its use: another project being opened, or its session being
+	// detached, closed or killed.
+	Time time.Duration
+}
+
+// historyDays summarizes entries per day and project, in the order the
+// projects were first used each day.
+func historyDays(entries []HistoryEntry, now time.Time) []*historyDay {
+	var (
+		days   []*historyDay
+		byKey  = make(map[string]*historyDay)
+		active *HistoryEntry
+	)
+	day := func(entry *HistoryEntry) *historyDay {
+		d := entry.Time.Local().Format("2006-01-02")
+		key := d + "\x00" + entry.Name
+		if byKey[key] == nil {
+			byKey[key] = &historyDay{Day: d, Name: entry.Name}
+			days = append(days, byKey[key])
+		}
+		return byKey[key]
+	}
+	track := func(until time.Time) {
+		if active != nil {
+			day(active).Time += min(until.Sub(active.Time), maxTrackedGap)
+		}
+	}
+
+	for i := range entries {
+		entry := &entries[i]
+		switch entry.Event {
+		case historyEventOpen:
+			track(entry.Time)
+			day(entry).Opened++
+			active = entry
+		case historyEventCreate:
+			day(entry).Created++
+		case historyEventClientDetached, historyEventSessionClosed, historyEventKill:
+			if active != nil && active.Session == entry.Session {
+				track(entry.Time)
+				active = nil
+			}
+		}
+	}
+	track(now)
+	return days
+}
+
+// writeHistory replaces the history log with entries.
+func writeHistory(entries []HistoryEntry) error {
+	p, err := historyPath()
+	if err != nil {
+		return err
+	}
+	var b bytes.Buffer
+	enc := json.NewEncoder(&b)
+	for _, entry := range entries {
+		if err := enc.Encode(entry); err != nil {
+			return err
+		}
+	}
+	tmp := p + ".tmp"
+	if err := os.WriteFile(tmp, b.Bytes(), 0o644); err != nil {
+		return err
+	}
+	return os.Rename(tmp, p)
+}
@@ -0,0 +1,133 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Hooks are shell commands run in the project directory by the run-hooks
+// action. They are templates, see templateData.
+type Hooks struct {
+	// OnCreate runs after a new session has been created.
+	OnCreate []Hook `yaml:"on_create"`
+	// OnOpen runs every time a project is opened.
+	OnOpen []Hook `yaml:"on_open"`
+	// Timeout stops hooks running for longer, 5m by default.
+	Timeout string `yaml:"timeout"`
+}
+
+// Hook is a hook command, given as a string or as a mapping with options.
+type Hook struct {
+	Run string `yaml:"run"`
+	// Timeout overrides the timeout of hooks for this hook.
+	Timeout string `yaml:"timeout,omitempty"`
+	// Async runs the hook in the background instead of waiting for it
+	// before attaching. Failures are shown in tmux.
+	Async bool `yaml:"async,omitempty"`
+}
+
+func (h *Hook) UnmarshalYAML(value *yaml.Node) error {
+	if value.Kind == yaml.ScalarNode {
+		return value.Decode(&h.Run)
+	}
+
+	type plain Hook
+	return value.Decode((*plain)(h))
+}
+
+const defaultHookTimeout = 5 * time.Minute
+
+// asyncHooks are the async hooks still running, main waits for them before
+// exiting.
+var asyncHooks sync.WaitGroup
+
+// timeout returns how long hook may run.
+func (h Hooks) timeout(hook Hook) time.Duration {
+	for _, s := range []string{hook.Timeout, h.Timeout} {
+		if d, err := time.ParseDuration(s); err == nil {
+			return d
+		}
+	}
+	return defaultHookTimeout
+}
+
+func (cfg *Config) runHooks(hooks []Hook, project *Project, env []string) error {
+	for _, hook := range hooks {
+		command, err := renderTemplate(hook.Run, project)
+		if err != nil {
+			return err
+		}
+		timeout := cfg.Hooks.timeout(hook)
+		if !hook.Async {
+			if _, err := runHook(command, timeout, project, env, os.Stdout); err != nil {
+				return err
+			}
+			continue
+		}
+
+		asyncHooks.Add(1)
+		go func() {
+			defer asyncHooks.Done()
+			output, err := runHook(command, timeout, project, env, nil)
+			if err == nil {
+				return
+			}
+			slog.Warn("async hook failed", "err", err, "output", output)
+			if cfg.usesTmux() {
+				if err := displayMessage(project.Session, "tmuxer: "+err.Error()); err != nil {
+					slog.Debug("failed to show message", "err", err)
+				}
+			}
+		}()
+	}
+	return nil
+}
+
+// runHook runs the hook command for project and returns its output, which
+// is logged and also copied to out when given.
+func runHook(command string, timeout time.Duration, project *Project, env []string, out io.Writer) (string, error) {
+	ctx, cancel := context.WithTimeout(appContext, timeout)
+	defer cancel()
+
+	slog.Debug("running hook", "hook", command, "project", project.Name)
+	start := time.Now()
+	cmd := shellCommandContext(ctx, command)
+	cmd.Dir = project.FullPath
+	cmd.Env = append(os.Environ(),
+		"TMUXER_PROJECT_NAME="+project.Name,
+		"TMUXER_PROJECT_PATH="+project.FullPath,
+		"TMUXER_PROJECT_TYPE="+typeList(project),
+		"TMUXER_SESSION="+project.Session,
+	)
+	cmd.Env = append(cmd.Env, env...)
+	// commands started by the hook may keep the output open after it was
+	// killed
+	cmd.WaitDelay = time.Second
+
+	var output bytes.Buffer
+	var w io.Writer = &output
+	if out != nil {
+		w = io.MultiWriter(&output, out)
+	}
+	cmd.Stdout, cmd.Stderr = w, w
+	err := runCommand(cmd)
+	slog.Info("ran hook", "hook", command, "project", project.Name, "duration", time.Since(start), "output", strings.TrimSpace(output.String()), "err", err)
+
+	switch {
+	case errors.Is(ctx.Err(), context.DeadlineExceeded):
+		return output.String(), fmt.Errorf("hook %q timed out after %s", command, timeout)
+	case err != nil:
+		return output.String(), fmt.Errorf("hook %q failed: %w", command, err)
+	}
+	return output.String(), nil
+}
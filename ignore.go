@@ -0,0 +1,131 @@
+package main
+
+import (
+	"bufio"
+	"errors"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/bmatcuk/doublestar/v4"
+)
+
+// baseIgnoreFile holds ignore rules for a single base, in the directory the
+// base pattern starts from.
+const baseIgnoreFile = ".tmuxerignore"
+
+// ignoreRule is a line of an ignore file, in gitignore syntax.
+type ignoreRule struct {
+	// pattern is a doublestar pattern relative to the base directory.
+	pattern string
+	negate  bool
+	dirOnly bool
+}
+
+// ignoreMatcher decides which directories are left out of the walk of a
+// base. Like in gitignore, the last matching rule wins, and nothing below
+// an ignored directory can be included again.
+type ignoreMatcher struct {
+	rules []ignoreRule
+}
+
+func (m *ignoreMatcher) ignored(p string, isDir bool) bool {
+	if m == nil {
+		return false
+	}
+	ignored := false
+	for _, rule := range m.rules {
+		if rule.dirOnly && !isDir {
+			continue
+		}
+		if ok, _ := doublestar.Match(rule.pattern, p); ok {
+			ignored = !rule.negate
+		}
+	}
+	return ignored
+}
+
+// parseIgnoreRule converts a gitignore line into a rule. ok is false for
+// blank lines and comments.
+func parseIgnoreRule(line string) (rule ignoreRule, ok bool) {
+	line = strings.TrimRight(line, " \t")
+	if line == "" || strings.HasPrefix(line, "#") {
+		return rule, false
+	}
+
+	if strings.HasPrefix(line, "!") {
+		rule.negate = true
+		line = line[1:]
+	} else if strings.HasPrefix(line, `\`) {
+		// escaped leading # or !
+		line = line[1:]
+	}
+	if strings.HasSuffix(line, "/") {
+		rule.dirOnly = true
+		line = strings.TrimRight(line, "/")
+	}
+	if line == "" {
+		return rule, false
+	}
+
+	// patterns without a slash match at any depth
+	if strings.Contains(line, "/") {
+		rule.pattern = strings.TrimPrefix(line, "/")
+	} else {
+		rule.pattern = "**/" + line
+	}
+	return rule, true
+}
+
+func loadIgnoreFile(p string) ([]ignoreRule, error) {
+	file, err := os.Open(p)
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	var rules []ignoreRule
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		if rule, ok := parseIgnoreRule(scanner.Text()); ok {
+			rules = append(rules, rule)
+		}
+	}
+	return rules, scanner.Err()
+}
+
+// globalIgnoreRules returns the rules of the ignore file next to the config
+// followed by those under ignore in the config and given with --ignore.
+func globalIgnoreRules(cfg *Config) ([]ignoreRule, error) {
+	dir, err := configDir()
+	if err != nil {
+		return nil, err
+	}
+	rules, err := loadIgnoreFile(filepath.Join(dir, "ignore"))
+	if err != nil {
+		return nil, err
+	}
+	for _, p := range append(append([]string{}, cfg.Ignore...), *ignorePatterns...) {
+		if rule, ok := parseIgnoreRule(p); ok {
+			rules = append(rules, rule)
+		}
+	}
+	return rules, nil
+}
+
+// baseIgnoreMatcher combines the global rules with those of the
+// .tmuxerignore file in dir, which take precedence.
+func baseIgnoreMatcher(global []ignoreRule, dir string) (*ignoreMatcher, error) {
+	rules, err := loadIgnoreFile(filepath.Join(dir, baseIgnoreFile))
+	if err != nil {
+		return nil, err
+	}
+	if len(global) == 0 && len(rules) == 0 {
+		return nil, nil
+	}
+	return &ignoreMatcher{rules: append(append([]ignoreRule{}, global...), rules...)}, nil
+}
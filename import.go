@@ -0,0 +1,247 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// importedSession is a session definition read from a tmuxinator or tmuxp
+// project file.
+type importedSession struct {
+	Name   string
+	Root   string
+	Layout *Layout
+}
+
+// runImportCommand converts a tmuxinator or tmuxp project file into a tmuxer
+// layout and prints it, or with --launch opens the session right away.
+func runImportCommand(cfg *Config, args []string) error {
+	if len(args) != 1 {
+		return errors.New("usage: tmuxer import <tmuxinator-or-tmuxp-file> [--launch]")
+	}
+
+	data, err := os.ReadFile(args[0])
+	if err != nil {
+		return err
+	}
+
+	session, err := parseImportedSession(data)
+	if err != nil {
+		return fmt.Errorf("failed to import %s: %w", args[0], err)
+	}
+	if session.Name == "" {
+		session.Name = strings.TrimSuffix(filepath.Base(args[0]), filepath.Ext(args[0]))
+	}
+
+	if !*launchImport {
+		fmt.Printf("# root: %s\n", session.Root)
+		enc := yaml.NewEncoder(os.Stdout)
+		enc.SetIndent(2)
+		if err := enc.Encode(map[string]any{
+			"layouts": map[string]*Layout{session.Name: session.Layout},
+		}); err != nil {
+			return err
+		}
+		return enc.Close()
+	}
+
+	root := session.Root
+	if root == "" {
+		root = "."
+	}
+	root, err = normalizePath(root)
+	if err != nil {
+		return err
+	}
+
+	project, err := newProject(session.Name, root)
+	if err != nil {
+		return err
+	}
+
+	if cfg.Layouts == nil {
+		cfg.Layouts = make(map[string]*Layout)
+	}
+	cfg.Layouts[session.Name] = session.Layout
+	cfg.Layout = session.Name
+	return runActions(cfg, project)
+}
+
+func parseImportedSession(data []byte) (*importedSession, error) {
+	var doc yaml.Node
+	if err := yaml.Unmarshal(data, &doc); err != nil {
+		return nil, err
+	}
+	if len(doc.Content) == 0 || doc.Content[0].Kind != yaml.MappingNode {
+		return nil, errors.New("not a project file")
+	}
+	root := doc.Content[0]
+
+	if findNode(root, "session_name") != nil {
+		return parseTmuxp(root)
+	}
+	return parseTmuxinator(root)
+}
+
+// parseTmuxinator reads the tmuxinator format:
+//
+//	name: app
+//	root: ~/code/app
+//	windows:
+//	  - editor: vim
+//	  - server:
+//	      layout: main-vertical
+//	      panes:
+//	        - rails s
+//	        - [cd web, npm start]
+func parseTmuxinator(root *yaml.Node) (*importedSession, error) {
+	session := &importedSession{
+		Name:   scalar(findNode(root, "name")),
+		Root:   scalar(findNode(root, "root")),
+		Layout: &Layout{},
+	}
+	if session.Root == "" {
+		session.Root = scalar(findNode(root, "project_root"))
+	}
+
+	windows := findNode(root, "windows")
+	if windows == nil {
+		windows = findNode(root, "tabs")
+	}
+	if windows == nil || windows.Kind != yaml.SequenceNode {
+		return nil, errors.New("no windows defined")
+	}
+
+	for _, item := range windows.Content {
+		if item.Kind != yaml.MappingNode || len(item.Content) < 2 {
+			continue
+		}
+		w := Window{Name: item.Content[0].Value}
+		value := item.Content[1]
+
+		var commands []string
+		switch value.Kind {
+		case yaml.MappingNode:
+			w.Arrangement = scalar(findNode(value, "layout"))
+			w.Dir = scalar(findNode(value, "root"))
+			if panes := findNode(value, "panes"); panes != nil {
+				for _, pane := range panes.Content {
+					commands = append(commands, paneCommand(pane))
+				}
+			}
+		default:
+			commands = append(commands, paneCommand(value))
+		}
+		setWindowCommands(&w, commands)
+		session.Layout.Windows = append(session.Layout.Windows, w)
+	}
+
+	return session, nil
+}
+
+// parseTmuxp reads the tmuxp format:
+//
+//	session_name: app
+//	start_directory: ~/code/app
+//	windows:
+//	  - window_name: editor
+//	    layout: main-vertical
+//	    panes:
+//	      - vim
+//	      - shell_command: [cd web, npm start]
+func parseTmuxp(root *yaml.Node) (*importedSession, error) {
+	session := &importedSession{
+		Name:   scalar(findNode(root, "session_name")),
+		Root:   scalar(findNode(root, "start_directory")),
+		Layout: &Layout{},
+	}
+
+	windows := findNode(root, "windows")
+	if windows == nil || windows.Kind != yaml.SequenceNode {
+		return nil, errors.New("no windows defined")
+	}
+
+	for _, item := range windows.Content {
+		w := Window{
+			Name:        scalar(findNode(item, "window_name")),
+			Dir:         scalar(findNode(item, "start_directory")),
+			Arrangement: scalar(findNode(item, "layout")),
+		}
+
+		before := paneCommand(findNode(item, "shell_command_before"))
+		var commands []string
+		if panes := findNode(item, "panes"); panes != nil {
+			for _, pane := range panes.Content {
+				command := paneCommand(pane)
+				if before != "" {
+					command = joinCommands([]string{before, command})
+				}
+				commands = append(commands, command)
+			}
+		}
+		setWindowCommands(&w, commands)
+		session.Layout.Windows = append(session.Layout.Windows, w)
+	}
+
+	return session, nil
+}
+
+// paneCommand flattens the many ways both tools describe the commands of a
+// pane into a single shell command.
+func paneCommand(node *yaml.Node) string {
+	if node == nil {
+		return ""
+	}
+
+	switch node.Kind {
+	case yaml.ScalarNode:
+		return node.Value
+	case yaml.SequenceNode:
+		var commands []string
+		for _, c := range node.Content {
+			commands = append(commands, paneCommand(c))
+		}
+		return joinCommands(commands)
+	case yaml.MappingNode:
+		if c := findNode(node, "shell_command"); c != nil {
+			return paneCommand(c)
+		}
+		// tmuxinator named panes: - logs: tail -f log/development.log
+		if len(node.Content) == 2 {
+			return paneCommand(node.Content[1])
+		}
+	}
+	return ""
+}
+
+func setWindowCommands(w *Window, commands []string) {
+	if len(commands) == 0 {
+		return
+	}
+	w.Command = commands[0]
+	for _, c := range commands[1:] {
+		w.Panes = append(w.Panes, Pane{Command: c})
+	}
+}
+
+func joinCommands(commands []string) string {
+	var nonEmpty []string
+	for _, c := range commands {
+		if c != "" {
+			nonEmpty = append(nonEmpty, c)
+		}
+	}
+	return strings.Join(nonEmpty, " && ")
+}
+
+func scalar(node *yaml.Node) string {
+	if node == nil || node.Kind != yaml.ScalarNode {
+		return ""
+	}
+	return node.Value
+}
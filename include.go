@@ -0,0 +1,131 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// mergeIncludes returns the root mapping doc of the config at path merged
+// with the files listed under its include key. Included files are merged in
+// order, each overriding the ones before, and doc overrides them all.
+// Mappings are merged key by key, other values such as lists are replaced.
+// Included files may include others; seen are the files including path.
+func mergeIncludes(path string, doc *yaml.Node, seen []string) (*yaml.Node, error) {
+	includes := findNode(doc, "include")
+	if includes == nil {
+		return doc, nil
+	}
+	patterns := []*yaml.Node{includes}
+	if includes.Kind == yaml.SequenceNode {
+		patterns = includes.Content
+	}
+
+	merged := &yaml.Node{Kind: yaml.MappingNode, Line: doc.Line, Column: doc.Column}
+	for _, pattern := range patterns {
+		files, err := includedFiles(path, pattern.Value)
+		if err != nil {
+			return nil, &ConfigError{Path: path, Problems: []string{fmt.Sprintf("line %d: %s", pattern.Line, err)}}
+		}
+		for _, file := range files {
+			if contains(seen, file) || file == path {
+				return nil, &ConfigError{Path: path, Problems: []string{fmt.Sprintf("line %d: include cycle through %s", pattern.Line, file)}}
+			}
+			fragment, err := loadFragment(file)
+			if err != nil || fragment == nil {
+				return nil, err
+			}
+			if fragment, err = mergeIncludes(file, fragment, append(seen, path)); err != nil {
+				return nil, err
+			}
+			mergeNode(merged, fragment)
+		}
+	}
+
+	mergeNode(merged, doc)
+	for i := 0; i+1 < len(merged.Content); i += 2 {
+		if merged.Content[i].Value == "include" {
+			merged.Content = append(merged.Content[:i], merged.Content[i+2:]...)
+			break
+		}
+	}
+	return merged, nil
+}
+
+// includedFiles returns the files matched by the include pattern, relative
+// to the directory of the including file at path. A pattern without glob
+// characters must name an existing file, a glob may match nothing.
+func includedFiles(path, pattern string) ([]string, error) {
+	if pattern == "" {
+		return nil, errors.New("empty include")
+	}
+	if strings.HasPrefix(pattern, "~") || strings.HasPrefix(pattern, "$HOME") {
+		var err error
+		if pattern, err = normalizePath(pattern); err != nil {
+			return nil, err
+		}
+	} else if !filepath.IsAbs(pattern) {
+		pattern = filepath.Join(filepath.Dir(path), pattern)
+	}
+
+	if !strings.ContainsAny(pattern, "*?[") {
+		if _, err := os.Stat(pattern); err != nil {
+			return nil, fmt.Errorf("included file %s does not exist", pattern)
+		}
+		return []string{pattern}, nil
+	}
+	files, err := filepath.Glob(pattern)
+	if err != nil {
+		return nil, fmt.Errorf("invalid include %q: %w", pattern, err)
+	}
+	return files, nil
+}
+
+// loadFragment reads the included config file at path, checking its keys
+// on its own so problems point at the right file.
+func loadFragment(path string) (*yaml.Node, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	var root yaml.Node
+	if err := yaml.Unmarshal(data, &root); err != nil {
+		return nil, &ConfigError{Path: path, Problems: []string{err.Error()}}
+	}
+	if len(root.Content) == 0 {
+		return nil, nil
+	}
+	if problems := checkKnownFields(root.Content[0], reflect.TypeOf(Config{}), ""); len(problems) > 0 {
+		return nil, &ConfigError{Path: path, Problems: problems}
+	}
+	return root.Content[0], nil
+}
+
+// mergeNode merges the mapping src into dst. Values that are mappings in
+// both are merged recursively, other values of src replace those of dst.
+func mergeNode(dst, src *yaml.Node) {
+	for i := 0; i+1 < len(src.Content); i += 2 {
+		key, value := src.Content[i], src.Content[i+1]
+		replaced := false
+		for j := 0; j+1 < len(dst.Content); j += 2 {
+			if dst.Content[j].Value != key.Value {
+				continue
+			}
+			if existing := dst.Content[j+1]; existing.Kind == yaml.MappingNode && value.Kind == yaml.MappingNode {
+				mergeNode(existing, value)
+			} else {
+				dst.Content[j+1] = value
+			}
+			replaced = true
+			break
+		}
+		if !replaced {
+			dst.Content = append(dst.Content, key, value)
+		}
+	}
+}
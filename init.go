@@ -0,0 +1,177 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+	"text/template"
+)
+
+var configTemplate = template.Must(template.New("config").Parse(`# tmuxer configuration, see https://github.com/k1ng440/tmuxer
+# Generated by tmuxer init.
+
+# Directories projects are discovered in. The part in braces lists the
+# markers that make a directory a project.
+base:
+{{- range .Bases}}
+  - {{.}}
+{{- end}}
+
+# Order of the picker entries: name-asc, name-desc, path, mtime or frecency.
+sort: {{.Sort}}
+
+# Prepended to the names of sessions created by tmuxer.
+# session_prefix: dev/
+
+# Command run in the initial window of new sessions.
+# default_command: $EDITOR .
+
+# Windows created for new sessions, when no layout is configured.
+# smart_windows: true
+`))
+
+type configTemplateData struct {
+	Bases []string
+	Sort  string
+}
+
+// runInitCommand asks a few questions and writes a commented config file,
+// optionally adding the tmuxer key binding to the tmux configuration.
+func runInitCommand(_ *Config, _ []string) error {
+	path := loadedConfigPath
+	if path == "" || path == "-" {
+		return errors.New("init needs a config file to write")
+	}
+	if _, err := os.Stat(path); err == nil {
+		answer, err := prompt(fmt.Sprintf("%s exists, overwrite it? [y/N] ", path))
+		if err != nil {
+			return err
+		}
+		if !isYes(answer, false) {
+			return nil
+		}
+	}
+
+	answer, err := prompt("Directories containing your projects [~/code]: ")
+	if err != nil {
+		return err
+	}
+	dirs := strings.Fields(strings.ReplaceAll(answer, ",", " "))
+	if len(dirs) == 0 {
+		dirs = []string{"~/code"}
+	}
+
+	answer, err = prompt("Files or directories marking a project [.git]: ")
+	if err != nil {
+		return err
+	}
+	markers := strings.Fields(strings.ReplaceAll(answer, ",", " "))
+	if len(markers) == 0 {
+		markers = []string{".git"}
+	}
+
+	data := configTemplateData{Sort: defaultSort}
+	for _, dir := range dirs {
+		data.Bases = append(data.Bases, strings.TrimSuffix(dir, "/")+"/**/{"+strings.Join(markers, ",")+"}")
+	}
+
+	answer, err = prompt(fmt.Sprintf("Order projects by (%s) [%s]: ", strings.Join(sortedKeys(sortStrategies), ", "), defaultSort))
+	if err != nil {
+		return err
+	}
+	if answer != "" {
+		if _, ok := sortStrategies[answer]; !ok {
+			return fmt.Errorf("unknown sort %q", answer)
+		}
+		data.Sort = answer
+	}
+
+	var config strings.Builder
+	if err := configTemplate.Execute(&config, data); err != nil {
+		return err
+	}
+	if err := decodeStrict(path, []byte(config.String()), &Config{}); err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return err
+	}
+	if err := os.WriteFile(path, []byte(config.String()), 0o644); err != nil {
+		return err
+	}
+	fmt.Printf("Wrote %s\n", path)
+
+	answer, err = prompt("Add a tmux key binding opening tmuxer in a popup? [Y/n] ")
+	if err != nil {
+		return err
+	}
+	if !isYes(answer, true) {
+		return nil
+	}
+	return installKeybinding()
+}
+
+// installKeybinding appends the tmuxer key binding and hooks to the tmux
+// configuration file and loads them into a running server.
+func installKeybinding() error {
+	exe, err := os.Executable()
+	if err != nil {
+		return err
+	}
+
+	conf, err := tmuxConfPath()
+	if err != nil {
+		return err
+	}
+	lines := tmuxConfLines(exe, "T", true)
+
+	if err := os.MkdirAll(filepath.Dir(conf), 0o755); err != nil {
+		return err
+	}
+	file, err := os.OpenFile(conf, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0o644)
+	if err != nil {
+		return err
+	}
+	_, err = fmt.Fprintf(file, "\n# tmuxer, added by tmuxer init\n%s\n", strings.Join(lines, "\n"))
+	if cerr := file.Close(); err == nil {
+		err = cerr
+	}
+	if err != nil {
+		return err
+	}
+	fmt.Printf("Added the key binding prefix + T to %s\n", conf)
+
+	if tmuxClient.IsInsideTmux() {
+		return runTmuxCommand("source-file", conf)
+	}
+	return nil
+}
+
+// tmuxConfPath returns the tmux configuration file in use, preferring the
+// XDG location when neither exists yet.
+func tmuxConfPath() (string, error) {
+	for _, p := range []string{"~/.tmux.conf", "~/.config/tmux/tmux.conf"} {
+		p, err := normalizePath(p)
+		if err != nil {
+			return "", err
+		}
+		if _, err := os.Stat(p); !errors.Is(err, fs.ErrNotExist) {
+			return p, nil
+		}
+	}
+	return normalizePath("~/.config/tmux/tmux.conf")
+}
+
+func isYes(answer string, def bool) bool {
+	switch strings.ToLower(answer) {
+	case "":
+		return def
+	case "y", "yes":
+		return true
+	default:
+		return false
+	}
+}
@@ -0,0 +1,43 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
+
+	fuzzyfinder "github.com/k1ng440/tmuxer/third_party/go-fuzzyfinder"
+)
+
+// exitInterrupted is the exit status when the picker is aborted or tmuxer
+// is interrupted, like a shell reports a command killed by SIGINT.
+const exitInterrupted = 130
+
+// interruptGrace is how long tmuxer waits for the running command to stop
+// after an interrupt before exiting anyway, such as when it waits for input.
+const interruptGrace = time.Second
+
+// appContext is cancelled on SIGINT and SIGTERM. Scans, tmux and the other
+// commands tmuxer runs are stopped with it.
+var appContext, cancelApp = context.WithCancel(context.Background())
+
+// handleInterrupts cancels appContext on SIGINT or SIGTERM and exits once
+// the running command had interruptGrace to stop.
+func handleInterrupts() {
+	signals := make(chan os.Signal, 1)
+	signal.Notify(signals, os.Interrupt, syscall.SIGTERM)
+	go func() {
+		<-signals
+		cancelApp()
+		time.Sleep(interruptGrace)
+		os.Exit(exitInterrupted)
+	}()
+}
+
+// interrupted reports whether err ends tmuxer because the user aborted the
+// picker or interrupted it, which is not reported as an error.
+func interrupted(err error) bool {
+	return appContext.Err() != nil || errors.Is(err, fuzzyfinder.ErrAbort)
+}
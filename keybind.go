@@ -0,0 +1,79 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"os"
+)
+
+// hookIndex is the array index tmuxer's hooks are installed at, so
+// re-sourcing the configuration replaces them instead of adding duplicates.
+const hookIndex = 42
+
+// runKeybindCommand prints tmux configuration binding key (T by default) to
+// a tmuxer popup. With --hooks it also prints hooks that report session
+// events back to tmuxer, keeping its state fresh when sessions end outside
+// of tmuxer.
+func runKeybindCommand(_ *Config, args []string) error {
+	key := "T"
+	if len(args) > 0 {
+		key = args[0]
+	}
+
+	exe, err := os.Executable()
+	if err != nil {
+		return err
+	}
+
+	for _, line := range tmuxConfLines(exe, key, *printHooks) {
+		fmt.Println(line)
+	}
+	return nil
+}
+
+// tmuxConfLines returns the tmux configuration integrating the tmuxer
+// binary exe.
+// tmux before 3.2 has no popups, the picker opens in a new window instead.
+func tmuxConfLines(exe, key string, hooks bool) []string {
+	bind := fmt.Sprintf("bind-key %s display-popup -E -w 80%% -h 60%% \"%s\"", key, exe)
+	if !tmuxSupports(featurePopup) {
+		bind = fmt.Sprintf("bind-key %s new-window -n tmuxer \"%s\"", key, exe)
+	}
+	lines := []string{bind}
+
+	if hooks {
+		// before 3.0 a hook holds a single command
+		index := ""
+		if tmuxSupports(featureHookIndex) {
+			index = fmt.Sprintf("[%d]", hookIndex)
+		}
+		lines = append(lines,
+			fmt.Sprintf("set-hook -g session-closed%s 'run-shell -b \"%s event %s #{hook_session_name}\"'", index, exe, historyEventSessionClosed),
+			fmt.Sprintf("set-hook -g client-detached%s 'run-shell -b \"%s event %s #{session_name}\"'", index, exe, historyEventClientDetached),
+		)
+	}
+	return lines
+}
+
+// runEventCommand records a session event reported by a tmux hook. Events for
+// sessions tmuxer never opened are ignored.
+func runEventCommand(_ *Config, args []string) error {
+	if len(args) != 2 {
+		return errors.New("usage: tmuxer event <session-closed|client-detached> <session>")
+	}
+
+	event, session := args[0], args[1]
+	if event != historyEventSessionClosed && event != historyEventClientDetached {
+		return fmt.Errorf("unknown event %q", event)
+	}
+
+	entries, err := readHistory()
+	if err != nil {
+		return err
+	}
+
+	if project, ok := sessionProject(entries, session); ok {
+		return recordHistory(event, project)
+	}
+	return nil
+}
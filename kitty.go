@@ -0,0 +1,171 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"strconv"
+)
+
+// kittySessionVar is the user variable kitty windows of a session are
+// tagged with, as kitty has no sessions of its own.
+const kittySessionVar = "tmuxer_session"
+
+// kittyBackend opens each session as a kitty OS window through remote
+// control, which needs allow_remote_control in kitty.conf. Windows of a
+// layout become tabs and panes kitty windows.
+type kittyBackend struct{}
+
+type kittyOSWindow struct {
+	Tabs []struct {
+		Windows []struct {
+			ID       int               `json:"id"`
+			UserVars map[string]string `json:"user_vars"`
+		} `json:"windows"`
+	} `json:"tabs"`
+}
+
+func (kittyBackend) Command() string { return "kitty" }
+
+// firstWindow returns the id of the oldest kitty window of session, or -1
+// when it does not exist.
+func (kittyBackend) firstWindow(session string) (int, error) {
+	output, err := runBackend("kitty", "@", "ls")
+	if err != nil {
+		return -1, err
+	}
+	var osWindows []kittyOSWindow
+	if err := json.Unmarshal([]byte(output), &osWindows); err != nil {
+		return -1, fmt.Errorf("failed to read kitty windows: %w", err)
+	}
+	first := -1
+	for _, osWindow := range osWindows {
+		for _, tab := range osWindow.Tabs {
+			for _, w := range tab.Windows {
+				if w.UserVars[kittySessionVar] == session && (first == -1 || w.ID < first) {
+					first = w.ID
+				}
+			}
+		}
+	}
+	return first, nil
+}
+
+// launch starts a kitty window of session and returns its id.
+func (kittyBackend) launch(session string, args ...string) (string, error) {
+	args = append([]string{"@", "launch", "--var", kittySessionVar + "=" + session}, args...)
+	return runBackend("kitty", args...)
+}
+
+func (b kittyBackend) HasSession(name string) (bool, error) {
+	w, err := b.firstWindow(name)
+	return w != -1, err
+}
+
+func (b kittyBackend) NewSession(project *Project, dir string, command []string, env []string) (bool, error) {
+	args := []string{"--type=os-window", "--cwd", dir, "--tab-title", project.Session}
+	public, secret := splitSecrets(env)
+	for _, kv := range public {
+		args = append(args, "--env", kv)
+	}
+	switch {
+	case len(secret) > 0:
+		// secrets are read from a file instead of the arguments
+		start, err := secretCommand(secret, command)
+		if err != nil {
+			return false, err
+		}
+		args = append(args, start...)
+	case len(command) > 0:
+		args = append(args, "sh", "-c", command[0])
+	}
+	_, err := b.launch(project.Session, args...)
+	return err == nil, err
+}
+
+func (b kittyBackend) ApplyLayout(project *Project, layout *Layout) error {
+	first, err := b.firstWindow(project.Session)
+	if err != nil {
+		return err
+	}
+	firstID := strconv.Itoa(first)
+
+	for i, w := range layout.Windows {
+		window := firstID
+		if i == 0 {
+			if w.Name != "" {
+				_, err = runBackend("kitty", "@", "set-tab-title", "--match", "id:"+window, w.Name)
+			}
+			if err == nil && w.Dir != "" {
+				err = b.send(window, "cd "+shellQuote(layoutDir(project, w.Dir)))
+			}
+		} else {
+			args := []string{"--type=tab", "--match", "id:" + firstID, "--cwd", layoutDir(project, w.Dir)}
+			if w.Name != "" {
+				args = append(args, "--tab-title", w.Name)
+			}
+			window, err = b.launch(project.Session, args...)
+		}
+		if err != nil {
+			return err
+		}
+
+		command, err := w.WaitFor.wrap(w.Command)
+		if err != nil {
+			return err
+		}
+		if err := b.send(window, command); err != nil {
+			return err
+		}
+
+		for _, p := range w.Panes {
+			args := []string{"--type=window", "--match", "id:" + window, "--cwd", layoutDir(project, p.Dir)}
+			if p.Name != "" {
+				args = append(args, "--title", p.Name)
+			}
+			pane, err := b.launch(project.Session, args...)
+			if err != nil {
+				return err
+			}
+			command, err := p.WaitFor.wrap(p.Command)
+			if err != nil {
+				return err
+			}
+			if err := b.send(pane, command); err != nil {
+				return err
+			}
+		}
+		if w.Arrangement != "" {
+			slog.Debug("kitty does not support arrangements", "window", w.Name, "arrangement", w.Arrangement)
+		}
+	}
+	return nil
+}
+
+func (kittyBackend) send(window, command string) error {
+	if command == "" {
+		return nil
+	}
+	_, err := runBackend("kitty", "@", "send-text", "--match", "id:"+window, command+"\r")
+	return err
+}
+
+func (b kittyBackend) SendCommand(session, command string) error {
+	w, err := b.firstWindow(session)
+	if err != nil {
+		return err
+	}
+	return b.send(strconv.Itoa(w), command)
+}
+
+func (b kittyBackend) Attach(session string) error {
+	w, err := b.firstWindow(session)
+	if err != nil {
+		return err
+	}
+	if w == -1 {
+		return fmt.Errorf("no kitty window of session %s", session)
+	}
+	_, err = runBackend("kitty", "@", "focus-window", "--match", "id:"+strconv.Itoa(w))
+	return err
+}
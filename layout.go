@@ -0,0 +1,212 @@
+package main
+
+import (
+	"fmt"
+	"path/filepath"
+	"strings"
+)
+
+// Layout describes the windows created for a new session.
+type Layout struct {
+	Windows []Window `yaml:"windows"`
+}
+
+type Window struct {
+	Name string `yaml:"name,omitempty"`
+	// Dir is the working directory, relative to the project.
+	Dir     string `yaml:"dir,omitempty"`
+	Command string `yaml:"command,omitempty"`
+	// Arrangement is a tmux layout such as tiled or main-vertical.
+	Arrangement string `yaml:"arrangement,omitempty"`
+	Panes       []Pane `yaml:"panes,omitempty"`
+	// WaitFor delays Command until its conditions hold.
+	WaitFor *WaitFor `yaml:"wait_for,omitempty"`
+	// Editor makes this the editor window of the project, named editor and
+	// running the configured editor unless Name or Command are set.
+	Editor bool `yaml:"editor,omitempty"`
+}
+
+// Pane is an additional pane split off a window.
+type Pane struct {
+	// Name is set as the pane title.
+	Name    string   `yaml:"name,omitempty"`
+	Dir     string   `yaml:"dir,omitempty"`
+	Command string   `yaml:"command,omitempty"`
+	WaitFor *WaitFor `yaml:"wait_for,omitempty"`
+}
+
+func (cfg *Config) layoutFor(project *Project) (*Layout, error) {
+	name := cfg.Layout
+	for _, t := range detectTypes(project) {
+		if layout, ok := cfg.TypeLayouts[t]; ok {
+			name = layout
+			break
+		}
+	}
+	if v := cfg.Variants[project.Variant]; v != nil && v.Layout != "" {
+		name = v.Layout
+	}
+	if project.Layout != "" {
+		name = project.Layout
+	}
+
+	if name == "" {
+		if cfg.SmartWindows {
+			return smartLayout(project), nil
+		}
+		return nil, nil
+	}
+
+	layout, ok := cfg.Layouts[name]
+	if !ok {
+		return nil, fmt.Errorf("layout %q is not defined", name)
+	}
+	return layout, nil
+}
+
+// render returns a copy of the layout with the templates in names,
+// directories and commands executed for project, and the editor filled in
+// for editor windows.
+func (l *Layout) render(cfg *Config, project *Project) (*Layout, error) {
+	var err error
+	field := func(s string) string {
+		if err != nil {
+			return ""
+		}
+		var out string
+		out, err = renderTemplate(s, project)
+		return out
+	}
+
+	waitFor := func(w *WaitFor) *WaitFor {
+		if w == nil {
+			return nil
+		}
+		return &WaitFor{Port: w.Port, File: field(w.File), Command: field(w.Command), Timeout: w.Timeout}
+	}
+
+	rendered := &Layout{}
+	for _, w := range l.Windows {
+		if w.Editor {
+			w = editorWindow(cfg, project, w)
+		}
+		rw := Window{
+			Name:        field(w.Name),
+			Dir:         field(w.Dir),
+			Command:     field(w.Command),
+			Arrangement: w.Arrangement,
+			WaitFor:     waitFor(w.WaitFor),
+			Editor:      w.Editor,
+		}
+		for _, p := range w.Panes {
+			rw.Panes = append(rw.Panes, Pane{
+				Name:    field(p.Name),
+				Dir:     field(p.Dir),
+				Command: field(p.Command),
+				WaitFor: waitFor(p.WaitFor),
+			})
+		}
+		rendered.Windows = append(rendered.Windows, rw)
+	}
+	return rendered, err
+}
+
+// Apply creates the windows and panes of the layout in the project session.
+// The first window reuses the window tmux created along with the session.
+func (l *Layout) Apply(project *Project) error {
+	for i, w := range l.Windows {
+		var (
+			target string
+			err    error
+		)
+		if i == 0 {
+			target, err = tmuxOutput("display-message", "-p", "-t", project.Session+":", "#{window_id}")
+			if err == nil && w.Name != "" {
+				err = runTmuxCommand("rename-window", "-t", target, w.Name)
+			}
+			if err == nil && w.Dir != "" {
+				err = sendKeys(target, "cd "+shellQuote(tmuxPath(layoutDir(project, w.Dir))))
+			}
+		} else {
+			target, err = newLayoutWindow(project, w)
+		}
+		if err != nil {
+			return err
+		}
+
+		if err := fillWindow(project, w, target, true, 0); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// newLayoutWindow creates the window w in the project session and returns
+// its id.
+func newLayoutWindow(project *Project, w Window) (string, error) {
+	args := []string{"-d", "-P", "-F", "#{window_id}", "-t", project.Session + ":", "-c", layoutDir(project, w.Dir)}
+	if w.Name != "" {
+		args = append(args, "-n", w.Name)
+	}
+	return tmuxOutput("new-window", args...)
+}
+
+// fillWindow starts the command of the new window target and splits off
+// the panes of w, except for the first existing ones.
+func fillWindow(project *Project, w Window, target string, fresh bool, existing int) error {
+	if fresh {
+		command, err := w.WaitFor.wrap(w.Command)
+		if err != nil {
+			return err
+		}
+		if err := sendKeys(target, command); err != nil {
+			return err
+		}
+	}
+
+	for _, p := range w.Panes[min(existing, len(w.Panes)):] {
+		pane, err := tmuxOutput("split-window", "-d", "-P", "-F", "#{pane_id}", "-t", target, "-c", layoutDir(project, p.Dir))
+		if err != nil {
+			return err
+		}
+		if p.Name != "" && tmuxSupports(featurePaneTitle) {
+			if err := runTmuxCommand("select-pane", "-t", pane, "-T", p.Name); err != nil {
+				return err
+			}
+		}
+		command, err := p.WaitFor.wrap(p.Command)
+		if err != nil {
+			return err
+		}
+		if err := sendKeys(pane, command); err != nil {
+			return err
+		}
+	}
+
+	if w.Arrangement != "" {
+		return runTmuxCommand("select-layout", "-t", target, w.Arrangement)
+	}
+	return nil
+}
+
+func layoutDir(project *Project, dir string) string {
+	if dir == "" {
+		return project.FullPath
+	}
+	if filepath.IsAbs(dir) {
+		return dir
+	}
+	return filepath.Join(project.FullPath, dir)
+}
+
+func sendKeys(target, command string) error {
+	if command == "" {
+		return nil
+	}
+	return runTmuxCommand("send-keys", "-t", target, command, "Enter")
+}
+
+func shellQuote(s string) string {
+	return "'" + strings.ReplaceAll(s, "'", `'\''`) + "'"
+}
@@ -0,0 +1,162 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"log/slog"
+	"os/exec"
+	"path/filepath"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// shells are not recorded as pane commands when snapshotting a session.
+var shells = []string{"sh", "bash", "zsh", "fish", "dash", "ksh", "nu"}
+
+// runLayoutCommand manages layouts. edit <name> [session] snapshots the
+// windows and panes of a session, the current one by default, into the
+// layout name in the config file, replacing any layout of that name.
+func runLayoutCommand(_ *Config, args []string) error {
+	if len(args) < 2 || len(args) > 3 || args[0] != "edit" {
+		return errors.New("usage: tmuxer layout edit <name> [session]")
+	}
+	name := args[1]
+
+	session := ""
+	if len(args) == 3 {
+		session = args[2]
+	} else if !tmuxClient.IsInsideTmux() {
+		return errors.New("layout edit must be run inside a tmux session or given one")
+	}
+
+	layout, err := snapshotLayout(session)
+	if err != nil {
+		return err
+	}
+
+	var value yaml.Node
+	if err := value.Encode(layout); err != nil {
+		return err
+	}
+	err = updateConfig(loadedConfigPath, func(root *yaml.Node) error {
+		return setNode(root, &value, "layouts", name)
+	})
+	if err != nil {
+		return err
+	}
+	fmt.Printf("Saved layout %s with %d windows to %s\n", name, len(layout.Windows), loadedConfigPath)
+	return nil
+}
+
+// snapshotLayout describes the windows and panes of session as a layout.
+// Directories below the session's start directory are made relative to it.
+func snapshotLayout(session string) (*Layout, error) {
+	target := []string{"-p"}
+	if session != "" {
+		target = append(target, "-t", session+":")
+	}
+	root, err := tmuxOutput("display-message", append(target, "#{session_path}")...)
+	if err != nil {
+		return nil, err
+	}
+
+	args := []string{"-s", "-F", "#{window_id}\t#{window_name}\t#{window_layout}\t#{window_panes}\t#{pane_pid}\t#{pane_current_path}\t#{pane_current_command}"}
+	if session != "" {
+		args = append(args, "-t", session+":")
+	}
+	output, err := tmuxOutput("list-panes", args...)
+	if err != nil {
+		return nil, err
+	}
+
+	processes := listProcesses()
+	layout := &Layout{}
+	lastWindow := ""
+	for _, line := range strings.Split(output, "\n") {
+		fields := strings.SplitN(line, "\t", 7)
+		if len(fields) != 7 {
+			continue
+		}
+		id, name, arrangement, panes, pid, dir, command := fields[0], fields[1], fields[2], fields[3], fields[4], fields[5], fields[6]
+
+		dir = relativeDir(root, dir)
+		if contains(shells, command) {
+			command = ""
+		} else if args := processes.commandLine(pid); args != "" {
+			command = args
+		}
+
+		if id != lastWindow {
+			lastWindow = id
+			w := Window{Name: name, Dir: dir, Command: command}
+			if panes != "1" {
+				w.Arrangement = arrangement
+			}
+			layout.Windows = append(layout.Windows, w)
+			continue
+		}
+		w := &layout.Windows[len(layout.Windows)-1]
+		w.Panes = append(w.Panes, Pane{Dir: dir, Command: command})
+	}
+	return layout, nil
+}
+
+type process struct {
+	ppid string
+	args string
+}
+
+// processTable maps process ids to their processes.
+type processTable map[string]process
+
+// listProcesses returns the processes of the system, or none when ps
+// fails, in which case only command names are recorded.
+func listProcesses() processTable {
+	output, err := exec.Command("ps", "-e", "-o", "pid=", "-o", "ppid=", "-o", "args=").Output()
+	if err != nil {
+		slog.Debug("failed to list processes", "err", err)
+		return nil
+	}
+	table := make(processTable)
+	for _, line := range strings.Split(string(output), "\n") {
+		fields := strings.Fields(line)
+		if len(fields) < 3 {
+			continue
+		}
+		table[fields[0]] = process{ppid: fields[1], args: strings.Join(fields[2:], " ")}
+	}
+	return table
+}
+
+// commandLine returns the command line of the program running in the pane
+// whose process is pid: the process itself when the pane was started with
+// a command, otherwise the shell's child.
+func (t processTable) commandLine(pid string) string {
+	p, ok := t[pid]
+	if !ok {
+		return ""
+	}
+	if !contains(shells, filepath.Base(strings.TrimPrefix(strings.Fields(p.args)[0], "-"))) {
+		return p.args
+	}
+	for _, child := range sortedKeys(t) {
+		if t[child].ppid == pid {
+			return t[child].args
+		}
+	}
+	return ""
+}
+
+// relativeDir returns dir relative to root when it is inside of it, "" for
+// root itself.
+func relativeDir(root, dir string) string {
+	rel, err := filepath.Rel(root, dir)
+	if err != nil || rel == ".." || strings.HasPrefix(rel, "../") {
+		return dir
+	}
+	if rel == "." {
+		return ""
+	}
+	return rel
+}
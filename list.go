@@ -0,0 +1,48 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/spf13/pflag"
+)
+
+// runListCommand prints the discovered projects along with the markers that
+// identified them. --marker limits the output to projects matched through
+// the given markers.
+func runListCommand(cfg *Config, _ []string) error {
+	projects, err := findProjectDirectories(cfg)
+	if err != nil {
+		return err
+	}
+
+	if pflag.CommandLine.Changed("marker") {
+		projects = filterByMarker(projects, *projectMarkers)
+	}
+
+	return printProjects(cfg, projects)
+}
+
+// printProjects prints the names, paths and markers of projects, colored
+// when stdout is a terminal.
+func printProjects(cfg *Config, projects []*Project) error {
+	theme := cfg.theme()
+	if !isTerminal(os.Stdout) {
+		theme = Theme{}
+	}
+	w := tabwriter.NewWriter(os.Stdout, 0, 4, 2, ' ', 0)
+	for _, p := range projects {
+		// picker entries such as the stale toggle have no path
+		if p.FullPath == "" {
+			continue
+		}
+		name := p.basePrefix() + p.Name
+		if p.Pinned && isTerminal(os.Stdout) {
+			name += " " + pinGlyph
+		}
+		fmt.Fprintf(w, "%s\t%s\t%s\n", paint(theme.Name, name), paint(theme.Path, p.FullPath), paint(theme.Marker, strings.Join(p.Markers, ",")))
+	}
+	return w.Flush()
+}
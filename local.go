@@ -0,0 +1,95 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// localDir marks a project that carries its own tmuxer configuration in
+// .tmuxer/config.yaml.
+const localDir = ".tmuxer"
+
+// findLocalProject walks up from dir looking for a .tmuxer directory below
+// the home directory and returns the directory containing it.
+func findLocalProject(dir string) (string, bool) {
+	home, _ := os.UserHomeDir()
+	for {
+		if dir == home || dir == filepath.Dir(dir) {
+			return "", false
+		}
+		if info, err := os.Stat(filepath.Join(dir, localDir)); err == nil && info.IsDir() {
+			return dir, true
+		}
+		dir = filepath.Dir(dir)
+	}
+}
+
+// openLocalProject opens the project at dir with its .tmuxer/config.yaml
+// layered over the main config once approved with tmuxer trust, as it may
+// set hooks and layouts running anything.
+func openLocalProject(cfg *Config, dir string) error {
+	p := filepath.Join(dir, localDir, "config.yaml")
+	data, err := os.ReadFile(p)
+	if err != nil && !os.IsNotExist(err) {
+		return err
+	}
+	if err == nil && isTrusted(p, data) {
+		// tmuxer trust approves the contents of this file only
+		var root yaml.Node
+		if yaml.Unmarshal(data, &root) == nil && len(root.Content) > 0 && findNode(root.Content[0], "include") != nil {
+			return fmt.Errorf("%s: include is not supported in project config", p)
+		}
+		local := &Config{}
+		if err := decodeStrict(p, data, local); err != nil {
+			return err
+		}
+		if refs := local.secretRefs(); len(refs) > 0 {
+			return fmt.Errorf("%s: secrets are only resolved from your own config, found %s", p, strings.Join(refs, ", "))
+		}
+		if err := decodeStrict(p, data, cfg); err != nil {
+			return err
+		}
+		if err := cfg.NormalizePaths(); err != nil {
+			return err
+		}
+	}
+
+	project, err := newProject(filepath.Base(dir), dir)
+	if err != nil {
+		return err
+	}
+	return runActions(cfg, project)
+}
+
+// secretRefs lists the env settings of cfg with secret: values.
+func (cfg *Config) secretRefs() []string {
+	var refs []string
+	add := func(path string, env map[string]string) {
+		for k, v := range env {
+			if strings.HasPrefix(v, secretPrefix) {
+				refs = append(refs, joinPath(path, k))
+			}
+		}
+	}
+	add("env", cfg.Env)
+	for i, b := range cfg.ProjectBase {
+		add(fmt.Sprintf("base[%d].env", i), b.Env)
+	}
+	for name, pc := range cfg.Projects {
+		if pc != nil {
+			add("projects."+name+".env", pc.Env)
+		}
+	}
+	for name, v := range cfg.Variants {
+		if v != nil {
+			add("variants."+name+".env", v.Env)
+		}
+	}
+	sort.Strings(refs)
+	return refs
+}
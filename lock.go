@@ -0,0 +1,40 @@
+package main
+
+import (
+	"fmt"
+	"log/slog"
+	"net/url"
+	"os"
+	"path/filepath"
+)
+
+// runtimeDir holds the lock files, below $XDG_RUNTIME_DIR when it is set.
+func runtimeDir() string {
+	if dir := os.Getenv("XDG_RUNTIME_DIR"); dir != "" {
+		return filepath.Join(dir, "tmuxer")
+	}
+	return filepath.Join(os.TempDir(), fmt.Sprintf("tmuxer-%d", os.Getuid()))
+}
+
+// lockSession serializes the creation of the session name between tmuxer
+// instances, such as when a key binding is pressed repeatedly. The returned
+// function releases the lock.
+func lockSession(name string) (func(), error) {
+	dir := runtimeDir()
+	if err := os.MkdirAll(dir, 0o700); err != nil {
+		return nil, fmt.Errorf("failed to create lock directory: %w", err)
+	}
+
+	// escaped reversibly, so no two session names share a lock
+	p := filepath.Join(dir, url.QueryEscape(name)+".lock")
+	f, err := os.OpenFile(p, os.O_CREATE|os.O_RDWR, 0o600)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open lock file: %w", err)
+	}
+	slog.Debug("locking session", "session", name, "lock", p)
+	if err := flock(f); err != nil {
+		f.Close()
+		return nil, fmt.Errorf("failed to lock %s: %w", p, err)
+	}
+	return func() { f.Close() }, nil
+}
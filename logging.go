@@ -0,0 +1,36 @@
+package main
+
+import (
+	"io"
+	"log/slog"
+	"os"
+)
+
+// setupLogging configures the default slog logger from the command line
+// flags. Only warnings and errors are logged unless --verbose or --debug is
+// given.
+func setupLogging() error {
+	level := slog.LevelWarn
+	switch {
+	case *debug:
+		level = slog.LevelDebug
+	case *verbose:
+		level = slog.LevelInfo
+	}
+
+	var w io.Writer = os.Stderr
+	if *logFile != "" {
+		p, err := normalizePath(*logFile)
+		if err != nil {
+			return err
+		}
+		file, err := os.OpenFile(p, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0o644)
+		if err != nil {
+			return err
+		}
+		w = file
+	}
+
+	slog.SetDefault(slog.New(slog.NewTextHandler(w, &slog.HandlerOptions{Level: level})))
+	return nil
+}
@@ -23,46 +23,64 @@
 package main
 
 import (
+	"bytes"
+	"errors"
 	"fmt"
-	"io/fs"
+	"log/slog"
 	"os"
 	"os/exec"
 	"path"
 	"path/filepath"
 	"regexp"
-	"sort"
+	"strconv"
 	"strings"
+	"time"
 
-	"github.com/bmatcuk/doublestar/v4"
-	"github.com/ktr0731/go-fuzzyfinder"
+	"github.com/k1ng440/tmuxer/third_party/go-fuzzyfinder"
+	"github.com/k1ng440/tmuxer/third_party/go-fuzzyfinder/matching"
 	"github.com/spf13/pflag"
-	"gopkg.in/yaml.v3"
+
+	"github.com/k1ng440/tmuxer/tmux"
 )
 
 type Project struct {
 	Name     string
 	FullPath string
 	HomePath string
+	// Repo is the clone url of a project that might not be checked out yet.
+	Repo string
+	// Remote is set for projects discovered on a remote host over ssh.
+	Remote *Remote
+	// Session is the tmux session name, assigned before actions run.
+	Session string
+	// Base is the base the project was discovered in, if any.
+	Base *Base
+	// Git is set for git repositories once the project has been enriched.
+	Git *Enrichment
+	// Types are the detected project types, see detectTypes.
+	Types []string
+	// Tmux is set for projects with a running session before the picker
+	// is shown.
+	Tmux *SessionStatus
+	// Variant selects one of the configured variants.
+	Variant string
+	// Markers are the files or directories that made tmuxer consider the
+	// directory a project, such as .git or go.mod.
+	Markers []string
+	// Layout overrides the layout chosen by the config, such as the one of
+	// the template the project was created from.
+	Layout string
+	// Pinned is set for projects pinned with tmuxer pin once they are
+	// sorted.
+	Pinned bool
+	// Clones are other checkouts of the same repository, folded into this
+	// project with merge_clones.
+	Clones []*Project
+	// Parent is the project a git worktree belongs to, whose settings under
+	// projects apply to the worktree as well.
+	Parent *Project
 }
 
-type Config struct {
-	ProjectBase []string `yaml:"base"`
-}
-
-func (cfg *Config) NormalizePaths() error {
-	for i := range cfg.ProjectBase {
-		p, err := normalizePath(cfg.ProjectBase[i])
-		if err != nil {
-			return err
-		}
-		cfg.ProjectBase[i] = p
-	}
-
-	return nil
-}
-
-const defaultConfigPath = "~/.config/tmux/tmuxer.yaml"
-
 var (
 	projectBase = pflag.StringSliceP(
 		"base",
@@ -74,229 +92,590 @@ var (
 		"marker",
 		"m",
 		[]string{".git"},
-		"Directories containing any of these patterns will be considered as projects. Default: .git",
+		"Only show projects matched through one of these markers, e.g. go.mod",
 	)
 	ignorePatterns = pflag.StringSliceP(
 		"ignore",
 		"i",
 		[]string{},
-		"Patterns to ignore projects",
+		"Directories to leave out of the scan, in gitignore syntax",
 	)
 	configPath = pflag.StringP(
 		"config",
 		"c",
-		defaultConfigPath,
-		"Path to the configuration file",
+		"",
+		"Path to the configuration file (default $XDG_CONFIG_HOME/tmuxer/config.yaml)",
+	)
+	multiSelect = pflag.Bool(
+		"multi",
+		false,
+		"Allow marking multiple projects in the picker with tab",
+	)
+	spawnTerminal = pflag.Bool(
+		"terminal",
+		false,
+		"Attach to the project session in a new terminal window, such as from a desktop launcher",
+	)
+	printHooks = pflag.Bool(
+		"hooks",
+		false,
+		"Include tmux hooks reporting session events to tmuxer in the keybind output",
+	)
+	verbose = pflag.BoolP(
+		"verbose",
+		"v",
+		false,
+		"Log what tmuxer is doing",
+	)
+	debug = pflag.Bool(
+		"debug",
+		false,
+		"Log debugging information such as tmux invocations",
+	)
+	logFile = pflag.String(
+		"log-file",
+		"",
+		"Write logs to this file instead of stderr",
+	)
+	onlyDirty = pflag.Bool(
+		"dirty",
+		false,
+		"Only show projects with uncommitted changes",
+	)
+	includeArchived = pflag.Bool(
+		"include-archived",
+		false,
+		"Also list projects archived with tmuxer archive in the picker",
+	)
+	templateName = pflag.String(
+		"template",
+		"",
+		"Scaffold projects created by tmuxer new or the picker with one of the templates defined in the config",
+	)
+	tmuxSocket = pflag.StringP(
+		"socket",
+		"L",
+		"",
+		"tmux server socket name, or path, to use instead of the default one, also set by TMUXER_TMUX_SOCKET",
+	)
+	pickBase = pflag.Bool(
+		"pick-base",
+		false,
+		"Pick the base first and then a project of that base",
+	)
+	variant = pflag.String(
+		"variant",
+		"",
+		"Open the project with one of the variants defined in the config",
+	)
+	initialCommand = pflag.String(
+		"cmd",
+		"",
+		"Command to run in the initial window of a new session",
+	)
+	launchImport = pflag.Bool(
+		"launch",
+		false,
+		"Open the session of an imported project file instead of printing its layout",
+	)
+	detach = pflag.BoolP(
+		"detach",
+		"d",
+		false,
+		"Create the session without attaching or switching to it",
+	)
+	dryRun = pflag.BoolP(
+		"dry-run",
+		"n",
+		false,
+		"Print the tmux, hook and git commands that would change something instead of running them",
+	)
+	printPath = pflag.BoolP(
+		"print",
+		"p",
+		false,
+		"Print the path of the selected project and nothing else",
+	)
+	scanStats = pflag.Bool(
+		"stats",
+		false,
+		"Report scan durations, cache hit rates and memory usage of tmuxer scan",
+	)
+	showStale = pflag.Bool(
+		"show-stale",
+		false,
+		"Include projects hidden by hide_stale",
+	)
+	noTmux = pflag.Bool(
+		"no-tmux",
+		false,
+		"Start a shell in the project directory, or print its path when piped, instead of using tmux",
+	)
+	strict = pflag.Bool(
+		"strict",
+		false,
+		"Fail when a base cannot be scanned completely instead of warning",
+	)
+	readStdin = pflag.String(
+		"stdin",
+		"",
+		"Read newline-separated project paths from stdin, instead of discovering projects (replace) or in addition (merge)",
+	)
+	noColor = pflag.Bool(
+		"no-color",
+		false,
+		"Do not color the preview and list output, also set by NO_COLOR",
+	)
+	sortOrder = pflag.String(
+		"sort",
+		"",
+		"Order projects by name-asc, name-desc, path, mtime or frecency",
+	)
+	grepContent = pflag.Bool(
+		"content",
+		false,
+		"Also match README titles and Go module names in tmuxer grep",
+	)
+	forceNew = pflag.Bool(
+		"force-new",
+		false,
+		"Create another session of the project, named like project-2, even when one exists",
+	)
+	selectName = pflag.String(
+		"select",
+		"",
+		"Pick the project with this name instead of showing the picker, for scripts",
+	)
+	actionMode = pflag.String(
+		"mode",
+		"",
+		"Action chain to run after selecting a project (create, attach, print or one defined under modes)",
+	)
+	// -p is taken by --print
+	profileName = pflag.StringP(
+		"profile",
+		"P",
+		"",
+		"Use one of the profiles defined in the config, also set by TMUXER_PROFILE",
 	)
 )
 
 func main() {
-	var (
-		config = &Config{}
-		err    error
-	)
+	pflag.Lookup("stdin").NoOptDefVal = "replace"
+	// --spawn-terminal is the name --terminal had before
+	pflag.CommandLine.SetNormalizeFunc(func(_ *pflag.FlagSet, name string) pflag.NormalizedName {
+		if name == "spawn-terminal" {
+			name = "terminal"
+		}
+		return pflag.NormalizedName(name)
+	})
 	pflag.Parse()
+	handleInterrupts()
+	if *dryRun {
+		commandRunner = dryRunner{out: os.Stdout, next: commandRunner}
+	}
 
-	cfgPath, err := normalizePath(*configPath)
-	if err != nil {
+	if err := setupLogging(); err != nil {
 		fmt.Println("Error: ", err)
 		os.Exit(1)
 	}
 
-	config, err = loadConfig(cfgPath)
-	if err != nil {
-		fmt.Println("Error: ", err)
-		os.Exit(1)
+	run := runPicker
+	args := pflag.Args()
+	anyConfig := false
+	if len(args) > 0 {
+		if cmd, ok := commands[args[0]]; ok {
+			run, anyConfig = cmd.run, cmd.anyConfig
+			args = args[1:]
+		}
 	}
 
-	if err := mergeFlagsWithConfig(config); err != nil {
+	config, err := setupConfig()
+	if err != nil && anyConfig {
+		slog.Debug("failed to load config", "err", err)
+		config = &Config{}
+	} else if err != nil {
 		fmt.Println("Error: ", err)
 		os.Exit(1)
 	}
 
-	// Add ~ as default root if none provided
-	if len(config.ProjectBase) == 0 {
-		fmt.Println("Error: No project base path provided")
+	err = run(config, args)
+	asyncHooks.Wait()
+	if err != nil && interrupted(err) {
+		os.Exit(exitInterrupted)
+	}
+	if err != nil && !errors.Is(err, errCancelled) {
+		fmt.Println("Error:", err)
 		os.Exit(1)
 	}
+}
 
-	if err := config.NormalizePaths(); err != nil {
-		fmt.Printf("Error: Failed to normalize config path: %s", err.Error())
-		os.Exit(1)
+func runPicker(config *Config, args []string) error {
+	if len(args) == 0 {
+		if wd, err := os.Getwd(); err == nil {
+			if dir, ok := findLocalProject(wd); ok {
+				return openLocalProject(config, dir)
+			}
+		}
+	}
+
+	if config.streams(args) {
+		return runStreamingPicker(config)
 	}
 
 	projects, err := findProjectDirectories(config)
 	if err != nil {
-		fmt.Printf("Error: Failed to normalize config path: %s", err.Error())
-		os.Exit(1)
+		return err
+	}
+	if len(config.ProjectBase) == 0 && len(projects) == 0 {
+		return fmt.Errorf("no project base path provided")
+	}
+	if projects, err = withoutArchived(projects); err != nil {
+		return err
 	}
 
-	projectDir, err := selectProjectDirectory(projects)
-	if err != nil {
-		fmt.Println("Error:", err)
-		os.Exit(1)
+	if pflag.CommandLine.Changed("marker") {
+		projects = filterByMarker(projects, *projectMarkers)
+	}
+
+	if *onlyDirty {
+		enrichProjects(projects)
+		projects = filterDirty(projects)
+		if len(projects) == 0 {
+			return fmt.Errorf("no projects with uncommitted changes")
+		}
 	}
 
-	err = startOrAttachToTmux(projectDir)
+	projects, stale, err := config.splitStale(projects)
 	if err != nil {
-		fmt.Println("Error:", err)
-		os.Exit(1)
+		return err
 	}
-}
 
-func loadConfig(configPath string) (*Config, error) {
-	config := &Config{}
+	if query := strings.Join(args, " "); query != "" {
+		projects = filterByQuery(config, projects, query)
+		stale = filterByQuery(config, stale, query)
+		switch len(projects) + len(stale) {
+		case 0:
+			return fmt.Errorf("no project matches %q", query)
+		case 1:
+			return runActions(config, append(projects, stale...)[0])
+		}
+		if len(projects) == 0 {
+			projects, stale = stale, nil
+		}
+	}
 
-	if configPath == "" || configPath == "-" {
-		return config, nil
+	if projects, stale, err = config.pickBaseFirst(projects, stale); err != nil {
+		return err
+	}
+	if config.MergeClones {
+		projects, stale = mergeClones(projects), mergeClones(stale)
 	}
 
-	file, err := os.Open(configPath)
-	if err != nil {
-		return nil, err
+	config.annotateSessions(projects)
+	if *multiSelect {
+		return runMultiPicker(config, projects)
 	}
-	defer file.Close()
 
-	decoder := yaml.NewDecoder(file)
-	err = decoder.Decode(config)
+	choices := projects
+	var toggle *Project
+	if len(stale) > 0 {
+		toggle = staleToggle(len(stale))
+		choices = append(append([]*Project{}, projects...), toggle)
+	}
+	projectDir, err := selectProjectDirectory(config, choices)
 	if err != nil {
-		return nil, err
+		return err
+	}
+	if projectDir == toggle {
+		all := append(projects, stale...)
+		if err := config.sortProjects(all); err != nil {
+			return err
+		}
+		config.annotateSessions(all)
+		if projectDir, err = selectProjectDirectory(config, all); err != nil {
+			return err
+		}
+	}
+	if projectDir, err = pickClone(config, projectDir); err != nil {
+		return err
 	}
 
-	return config, nil
+	return runActions(config, projectDir)
 }
 
-func mergeFlagsWithConfig(config *Config) error {
-	if len(*projectBase) > 0 {
-		config.ProjectBase = append(config.ProjectBase, *projectBase...)
+// basePrefix returns the prefix of the base project was discovered in.
+func (p *Project) basePrefix() string {
+	if p.Base == nil {
+		return ""
 	}
-	return nil
+	return p.Base.Prefix
 }
 
-func findProjectDirectories(cfg *Config) ([]*Project, error) {
-	ret := make(map[string]*Project)
-	regex := regexp.MustCompile(`(\*|\*\*|\?|\[.*\]|\{[^}]*\})`)
+// baseWeight returns the weight of the base project was discovered in.
+func (p *Project) baseWeight() int {
+	if p.Base == nil {
+		return 0
+	}
+	return p.Base.Weight
+}
 
-	for _, basePattern := range cfg.ProjectBase {
-		base, pattern := doublestar.SplitPattern(basePattern)
-		patternUsed := len(regex.FindStringIndex(path.Base(pattern))) > 0
-		homedir, _ := os.UserHomeDir()
+// projectName derives the project name from a path p matched below base.
+// When the last pattern element is a glob, p is a marker inside the project
+// directory.
+func projectName(base, p string, patternUsed bool) string {
+	if !patternUsed {
+		return p
+	}
+	// handle immediate directories differently to avoid "." as name
+	if !strings.Contains(p, "/") {
+		return path.Base(base)
+	}
+	return path.Dir(p)
+}
 
-		doublestar.GlobWalk(os.DirFS(base), pattern, func(p string, _ fs.DirEntry) error {
-			name := p
-			if patternUsed {
-				// handle immediate directories differently to avoid "." as name
-				if !strings.Contains(p, "/") {
-					name = path.Base(base)
-				} else {
-					name = path.Dir(p)
-				}
-			}
+func newProject(name, fullpath string) (*Project, error) {
+	return &Project{
+		Name:     name,
+		FullPath: fullpath,
+		HomePath: homePath(fullpath),
+	}, nil
+}
 
-			fullpath := path.Join(base, name)
-			rel, err := filepath.Rel(homedir, fullpath)
-			if err != nil {
-				return err
-			}
+var globRegex = regexp.MustCompile(`(\*|\*\*|\?|\[.*\]|\{[^}]*\})`)
+
+// addProject adds project to the set of discovered projects, merging the
+// markers of projects found more than once, and reports whether it is new.
+// Projects are told apart by their real path, so a project reachable
+// through symlinks is listed once.
+func addProject(projects map[string]*Project, project *Project) bool {
+	key := project.FullPath
+	if project.Remote == nil {
+		if real, err := filepath.EvalSymlinks(key); err == nil {
+			key = real
+		}
+	}
+	existing, ok := projects[key]
+	if !ok {
+		projects[key] = project
+		return true
+	}
+	for _, m := range project.Markers {
+		if !contains(existing.Markers, m) {
+			existing.Markers = append(existing.Markers, m)
+		}
+	}
+	return false
+}
 
-			project := &Project{
-				Name:     name,
-				FullPath: fullpath,
-				HomePath: rel,
+// filterByMarker returns the projects matched through any of markers.
+func filterByMarker(projects []*Project, markers []string) []*Project {
+	var res []*Project
+	for _, project := range projects {
+		for _, m := range project.Markers {
+			if contains(markers, m) {
+				res = append(res, project)
+				break
 			}
-			ret[project.FullPath] = project
-			return nil
-		})
+		}
 	}
+	return res
+}
 
-	// let's convert it to string slice.
-	res := make([]*Project, len(ret))
-	i := 0
-	for _, v := range ret {
-		res[i] = v
-		i++
+// filterByQuery returns the projects whose picker entry matches query the
+// way the picker matches typed input, best matches first.
+func filterByQuery(cfg *Config, projects []*Project, query string) []*Project {
+	labels := make([]string, len(projects))
+	for i, project := range projects {
+		labels[i] = cfg.projectLabel(project)
 	}
-	sort.Slice(res, func(i, j int) bool {
-		fmt.Println(res[i].Name)
-		return strings.ToLower(res[i].Name) > strings.ToLower(res[j].Name)
+	var res []*Project
+	for _, m := range matching.FindAll(query, labels, matching.WithMode(matching.ModeSmart)) {
+		res = append(res, projects[m.Idx])
+	}
+	return res
+}
+
+func findProjectDirectories(cfg *Config) ([]*Project, error) {
+	projects, _, err := discoverProjects(cfg)
+	return projects, err
+}
+
+func projectPreview(cfg *Config, projects []*Project) fuzzyfinder.Option {
+	return fuzzyfinder.WithPreviewWindow(func(i, _, _ int) string {
+		if i == -1 {
+			return ""
+		}
+		return previewText(cfg, projects[i])
 	})
-	return res, nil
 }
 
-func selectProjectDirectory(projects []*Project) (*Project, error) {
+// previewInfo describes project in the info mode of the preview window.
+func previewInfo(cfg *Config, project *Project) string {
+	theme := cfg.theme()
+	var b strings.Builder
+	field := func(label, color string, value any) {
+		if b.Len() > 0 {
+			b.WriteByte('\n')
+		}
+		fmt.Fprintf(&b, "%s %s", paint(theme.Label, label+":"), paint(color, fmt.Sprint(value)))
+	}
+
+	field("Name", theme.Name, project.Name)
+	field("Full Path", theme.Path, project.FullPath)
+	if len(project.Markers) > 0 {
+		field("Markers", theme.Marker, strings.Join(project.Markers, ", "))
+	}
+	if types := detectTypes(project); len(types) > 0 {
+		field("Type", "", strings.Join(types, ", "))
+	}
+	if git := project.Git; git != nil {
+		field("Branch", "", git.Branch)
+		field("Uncommitted changes", "", git.Dirty)
+	}
+	if status := project.Tmux; status != nil {
+		field("Session attached", theme.Session, status.Attached)
+		field("Unseen activity", theme.Session, status.Activity)
+		if info := currentSessionInfo(status.Name); info != nil {
+			field("Uptime", theme.Session, shortDuration(time.Since(info.Created)))
+			field("Clients", theme.Session, info.Clients)
+			for _, w := range info.Windows {
+				field("Window "+strconv.Itoa(w.Index), theme.Session, w)
+			}
+		}
+	}
+	return b.String()
+}
+
+// selectProjectDirectory lets the user pick one of projects, or picks the
+// one named by --select. Without a terminal to show the picker on the
+// projects are listed instead and errCancelled is returned.
+func selectProjectDirectory(cfg *Config, projects []*Project) (*Project, error) {
+	if *selectName != "" {
+		project := findProjectByName(projects, *selectName)
+		if project == nil {
+			return nil, fmt.Errorf("project %q not found", *selectName)
+		}
+		return project, nil
+	}
+	if !hasTerminal() {
+		slog.Info("no terminal for the picker, listing projects instead")
+		if err := printProjects(cfg, projects); err != nil {
+			return nil, err
+		}
+		return nil, errCancelled
+	}
+
+	var (
+		actionErrs []error
+		query      string
+		noMatch    bool
+		list       = newPickerList(projects)
+	)
 	idx, err := fuzzyfinder.Find(
-		projects,
+		&list.items,
 		func(i int) string {
-			return projects[i].Name
+			// called with list.mu held by the picker
+			return cfg.projectLabel(list.items[i])
 		},
+		fuzzyfinder.WithHotReloadLock(&list.mu),
 		fuzzyfinder.WithPreviewWindow(func(i, _, _ int) string {
-			if i == -1 {
-				return ""
+			if project := list.project(i); project != nil {
+				return previewText(cfg, project)
 			}
-			return fmt.Sprintf(
-				"Name: %s\nFull Path: %s",
-				projects[i].Name,
-				projects[i].FullPath,
-			)
-		}))
+			return ""
+		}),
+		cfg.pickerItemStyle(),
+		fuzzyfinder.WithQueryOutput(&query),
+		fuzzyfinder.WithKeyHandler(noMatchEntered(cfg.pickerKeyHandler(list.project, &list.mu, list.toggleDirty, &actionErrs), &noMatch)))
+	reportPickerErrors(actionErrs)
+	if errors.Is(err, fuzzyfinder.ErrAbort) && noMatch && strings.TrimSpace(query) != "" {
+		return offerNewProject(cfg, strings.TrimSpace(query), projects)
+	}
 	if err != nil {
 		return nil, err
 	}
 
-	fmt.Printf("Starting selected project: %s\n", projects[idx].Name)
-	return projects[idx], nil
+	project := list.project(idx)
+	slog.Info("starting selected project", "name", project.Name)
+	return project, nil
 }
 
-func startOrAttachToTmux(project *Project) error {
-	sessionExists := false
-	inTmux := os.Getenv("TMUX") != ""
+// projectOption is the session option holding the project path of the
+// sessions created or adopted by tmuxer.
+const projectOption = "@tmuxer-project"
 
-	cmd := exec.Command("tmux", "list-sessions")
-	output, err := cmd.CombinedOutput()
-	fmt.Println(strings.Contains(string(output), "no server running"))
-	if err != nil && !strings.Contains(string(output), "no server running") {
-		return fmt.Errorf("failed to list sessions: %w", err)
-	}
+func markSession(project *Project) error {
+	return runTmuxCommand("set-option", "-t", project.Session, projectOption, project.FullPath)
+}
 
-	for _, line := range strings.Split(string(output), "\n") {
-		if strings.Contains(line, project.Name) {
-			sessionExists = true
-			break
-		}
+// windowStart returns the working directory and optional command for a
+// window showing project.
+func windowStart(project *Project) (string, []string) {
+	if project.Remote != nil {
+		homedir, _ := os.UserHomeDir()
+		return homedir, []string{remoteSessionCommand(project)}
 	}
+	return project.FullPath, nil
+}
 
-	switch {
-	case sessionExists && inTmux:
-		return runTmuxCommand("switch-client", "-t", project.Name)
-	case sessionExists:
-		return runTmuxCommand("attach-session", "-t", project.Name)
-	default:
-		if err := runTmuxCommand("new-session", "-d", "-s", project.Name, "-c", project.FullPath); err != nil {
-			return err
-		}
-
-		// recall self to attach or switch
-		return startOrAttachToTmux(project)
+func attachSession(name string) error {
+	if tmuxClient.IsInsideTmux() {
+		return runTmuxCommand("switch-client", "-t", name)
 	}
+	return runTmuxCommand("attach-session", "-t", name)
 }
 
+// tmuxClient queries the tmux server tmuxer talks to, see tmuxSocketArgs.
+var tmuxClient = tmux.New(tmux.RunnerFunc(func(args ...string) ([]byte, error) {
+	slog.Debug("running tmux", "args", redact(args))
+	// without a UTF-8 locale tmux replaces the tabs separating the fields of
+	// formats with underscores, -u keeps them
+	cmd := tmuxCommand(append([]string{"-u"}, args...)...)
+	var stderr bytes.Buffer
+	cmd.Stderr = &stderr
+	output, err := commandOutput(cmd)
+	var exit *exec.ExitError
+	if errors.As(err, &exit) {
+		return output, &tmux.ExitError{Code: exit.ExitCode(), Stderr: stderr.String()}
+	}
+	return output, err
+}))
+
 func runTmuxCommand(cmdName string, args ...string) error {
 	targ := append([]string{cmdName}, args...)
-	cmd := exec.Command("tmux", targ...)
+	slog.Debug("running tmux", "args", redact(targ))
+	cmd := tmuxCommand(targ...)
 	cmd.Stdin = os.Stdin
 	cmd.Stdout = os.Stdout
 	cmd.Stderr = os.Stderr
-	return cmd.Run()
+	return runCommand(cmd)
 }
 
-func normalizePath(path string) (string, error) {
-	if strings.HasPrefix(path, "~") {
-		homeDir, _ := os.UserHomeDir()
-		path = filepath.Join(homeDir, path[1:])
+// tmuxOutput runs a tmux command and returns its trimmed standard output.
+func tmuxOutput(cmdName string, args ...string) (string, error) {
+	targ := append([]string{cmdName}, args...)
+	slog.Debug("running tmux", "args", redact(targ))
+	// without a UTF-8 locale tmux replaces the tabs separating the fields of
+	// formats with underscores, -u keeps them
+	output, err := commandOutput(tmuxCommand(append([]string{"-u"}, targ...)...))
+	if err != nil {
+		return "", fmt.Errorf("tmux %s: %w", cmdName, err)
 	}
-	if strings.HasPrefix(path, "$HOME") {
-		homeDir, _ := os.UserHomeDir()
-		path = filepath.Join(homeDir, path[5:])
+	return strings.TrimSpace(string(output)), nil
+}
+
+// homePrefixes are the ways a path can start with the home directory. On
+// windows os.UserHomeDir is %USERPROFILE%.
+var homePrefixes = []string{"~", "$HOME", "$USERPROFILE", "%USERPROFILE%"}
+
+func normalizePath(path string) (string, error) {
+	for _, prefix := range homePrefixes {
+		if strings.HasPrefix(path, prefix) {
+			homeDir, _ := os.UserHomeDir()
+			path = filepath.Join(homeDir, path[len(prefix):])
+			break
+		}
 	}
 	return filepath.Abs(path)
 }
@@ -0,0 +1,82 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"log/slog"
+	"os"
+	"runtime"
+	"time"
+)
+
+// runScanCommand discovers all projects and refreshes their cached git
+// information. With --stats it reports how long each base took, how well
+// the cache worked and how much memory was used.
+func runScanCommand(cfg *Config, _ []string) error {
+	projects, report, err := scanProjects(cfg)
+	if err != nil {
+		return err
+	}
+	if *scanStats {
+		if err := printScanStats(os.Stdout, report.Bases); err != nil {
+			return err
+		}
+		printScanSummary(os.Stdout, report)
+		fmt.Println()
+	}
+	fmt.Printf("Found %d projects\n", len(projects))
+	return nil
+}
+
+func scanProjects(cfg *Config) ([]*Project, *scanReport, error) {
+	if len(cfg.ProjectBase) == 0 {
+		return nil, nil, errors.New("no project base path provided")
+	}
+
+	start := time.Now()
+	projects, stats, err := discoverProjects(cfg)
+	if err != nil {
+		return nil, nil, err
+	}
+	report := &scanReport{Time: start, Bases: stats}
+
+	gitStart := time.Now()
+	report.CacheHits, report.CacheMisses = enrichProjects(projects)
+	report.GitDuration = time.Since(gitStart)
+	report.Duration = time.Since(start)
+
+	var mem runtime.MemStats
+	runtime.ReadMemStats(&mem)
+	report.TotalAlloc, report.Sys = mem.TotalAlloc, mem.Sys
+
+	if err := saveScanReport(report); err != nil {
+		slog.Warn("failed to save scan report", "err", err)
+	}
+	slog.Info("scan finished", "projects", len(projects), "duration", report.Duration)
+	return projects, report, nil
+}
+
+// runCleanCommand removes cached data about projects that no longer exist.
+func runCleanCommand(_ *Config, _ []string) error {
+	removed, err := cleanCaches()
+	if err != nil {
+		return err
+	}
+	fmt.Printf("Removed %d stale cache entries\n", removed)
+	return nil
+}
+
+func cleanCaches() (int, error) {
+	cache := loadEnrichmentCache()
+
+	removed := 0
+	for p := range cache {
+		if _, err := os.Stat(p); errors.Is(err, os.ErrNotExist) {
+			delete(cache, p)
+			removed++
+		}
+	}
+
+	slog.Info("cleaned caches", "removed", removed)
+	return removed, saveEnrichmentCache(cache)
+}
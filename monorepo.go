@@ -0,0 +1,85 @@
+package main
+
+import (
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// Monorepo configures the monorepo project source, which lists the
+// subdirectories of discovered projects that contain one of Markers as
+// projects of their own.
+type Monorepo struct {
+	Markers []string `yaml:"markers"`
+	// Depth is how many directories below the project are searched.
+	Depth int `yaml:"depth"`
+}
+
+var (
+	defaultMonorepoMarkers = []string{"go.mod", "package.json", "Cargo.toml", "pyproject.toml"}
+	defaultMonorepoDepth   = 3
+)
+
+// monorepoSkip are directories never searched for sub-projects.
+var monorepoSkip = map[string]bool{
+	"node_modules": true,
+	"vendor":       true,
+	"target":       true,
+	"dist":         true,
+}
+
+// discoverMonorepos adds the sub-projects of the projects found so far,
+// named after the project and their path in it, like repo/services/api.
+// Nested git repositories are projects of their own and not searched.
+func discoverMonorepos(d *discovery) ([]*Project, error) {
+	markers, depth := defaultMonorepoMarkers, defaultMonorepoDepth
+	if len(d.cfg.Monorepo.Markers) > 0 {
+		markers = d.cfg.Monorepo.Markers
+	}
+	if d.cfg.Monorepo.Depth > 0 {
+		depth = d.cfg.Monorepo.Depth
+	}
+
+	var ret []*Project
+	for _, project := range d.projects {
+		if project.Remote != nil || project.FullPath == project.Repo {
+			continue
+		}
+		root := project.FullPath
+		_ = filepath.WalkDir(root, func(p string, e fs.DirEntry, err error) error {
+			if err != nil || !e.IsDir() {
+				return nil
+			}
+			if p == root {
+				return nil
+			}
+			rel, _ := filepath.Rel(root, p)
+			if strings.HasPrefix(e.Name(), ".") || monorepoSkip[e.Name()] || strings.Count(rel, string(filepath.Separator)) >= depth {
+				return filepath.SkipDir
+			}
+			if _, err := os.Stat(filepath.Join(p, ".git")); err == nil {
+				return filepath.SkipDir
+			}
+
+			var found []string
+			for _, m := range markers {
+				if _, err := os.Stat(filepath.Join(p, m)); err == nil {
+					found = append(found, m)
+				}
+			}
+			if len(found) == 0 {
+				return nil
+			}
+			sub, err := newProject(project.Name+"/"+filepath.ToSlash(rel), p)
+			if err != nil {
+				return nil
+			}
+			sub.Base = project.Base
+			sub.Markers = found
+			ret = append(ret, sub)
+			return nil
+		})
+	}
+	return ret, nil
+}
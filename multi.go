@@ -0,0 +1,161 @@
+package main
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"log/slog"
+	"os"
+	"strings"
+
+	"github.com/gdamore/tcell/v2"
+	"github.com/k1ng440/tmuxer/third_party/go-fuzzyfinder"
+)
+
+// combineKey accepts the marked projects of the multi picker like enter,
+// opening them as windows of one session rather than a session each.
+func combineKey(e *tcell.EventKey) bool {
+	return e.Key() == tcell.KeyEnter && e.Modifiers()&tcell.ModAlt != 0
+}
+
+// runMultiPicker lets the user mark several projects. Enter opens each marked
+// project in its own session, alt-enter all of them as windows of one new
+// session.
+func runMultiPicker(cfg *Config, projects []*Project) error {
+	if *selectName != "" || !hasTerminal() {
+		project, err := selectProjectDirectory(cfg, projects)
+		if err != nil {
+			return err
+		}
+		return runActions(cfg, project)
+	}
+
+	combine := false
+	indexes, err := fuzzyfinder.FindMulti(
+		projects,
+		func(i int) string {
+			return cfg.projectLabel(projects[i])
+		},
+		projectPreview(cfg, projects),
+		cfg.pickerItemStyle(),
+		fuzzyfinder.WithHeader("tab marks, enter opens each, alt-enter opens them in one session"),
+		fuzzyfinder.WithKeyHandler(func(e *tcell.EventKey, _ int) bool {
+			// the finder accepts it as enter, which ignores alt
+			combine = combine || combineKey(e)
+			return false
+		}))
+	if err != nil {
+		return err
+	}
+
+	selected := make([]*Project, len(indexes))
+	for i, idx := range indexes {
+		selected[i] = projects[idx]
+	}
+
+	if len(selected) == 1 {
+		return runActions(cfg, selected[0])
+	}
+	if *printPath {
+		for _, project := range selected {
+			fmt.Println(project.FullPath)
+		}
+		return nil
+	}
+
+	if !combine {
+		for _, project := range selected[1:] {
+			if err := runActions(cfg, project, actionAttach); err != nil {
+				return err
+			}
+		}
+		return runActions(cfg, selected[0])
+	}
+
+	return openCombinedSession(cfg, combinedSessionName(cfg, selected), selected)
+}
+
+// combinedSessionName names the session of several projects after all of
+// them, such as api+web.
+func combinedSessionName(cfg *Config, projects []*Project) string {
+	names := make([]string, len(projects))
+	for i, project := range projects {
+		names[i] = project.Name
+	}
+	return sessionNameReplacer.Replace(cfg.SessionPrefix + strings.Join(names, "+"))
+}
+
+// openCombinedSession creates a session with one window per project.
+func openCombinedSession(cfg *Config, session string, projects []*Project) error {
+	if !cfg.usesTmux() || withoutTmux() {
+		return errors.New("opening several projects in one session needs tmux")
+	}
+	exists, err := tmuxClient.HasSession(session)
+	if err != nil {
+		return err
+	}
+	if exists {
+		return fmt.Errorf("session %q already exists", session)
+	}
+
+	windowEnv := tmuxSupports(featureSessionEnv)
+	for i, project := range projects {
+		project.Session = cfg.sessionName(project)
+		dir, command := windowStart(project)
+
+		args := []string{"-d", "-n", project.Name, "-c", dir}
+		if i == 0 {
+			args = append([]string{"new-session", "-s", session}, args...)
+		} else {
+			args = append([]string{"new-window", "-t", session + ":"}, args...)
+		}
+
+		// each window gets the env of its project; secrets, and all of it
+		// before tmux 3.2, are read from a file instead of the arguments
+		env, err := cfg.projectEnv(project)
+		if err != nil {
+			return err
+		}
+		public, secret := splitSecrets(env)
+		if !windowEnv {
+			public, secret = nil, env
+		}
+		for _, kv := range public {
+			args = append(args, "-e", kv)
+		}
+		if len(secret) > 0 {
+			if command, err = secretCommand(secret, command); err != nil {
+				return err
+			}
+		}
+		args = append(args, command...)
+
+		if err := runTmuxCommand(args[0], args[1:]...); err != nil {
+			return err
+		}
+	}
+
+	for _, project := range projects {
+		if err := recordHistory(historyEventOpen, project); err != nil {
+			slog.Warn("failed to record history", "err", err)
+			break
+		}
+	}
+
+	if *detach {
+		return nil
+	}
+	return attachSession(session)
+}
+
+// stdin is shared by all prompts so input buffered by one is not lost.
+var stdin = bufio.NewReader(os.Stdin)
+
+func prompt(question string) (string, error) {
+	fmt.Print(question)
+	answer, err := stdin.ReadString('\n')
+	if err != nil && answer == "" {
+		return "", err
+	}
+	return strings.TrimSpace(answer), nil
+}
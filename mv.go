@@ -0,0 +1,189 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// runMvCommand renames or moves the directory of a project, renames its
+// session to match and updates the history, bookmarks, session records and
+// git cache so they follow the project. A target without a slash is a new
+// name for the directory in place.
+func runMvCommand(cfg *Config, args []string) error {
+	if len(args) != 2 {
+		return errors.New("usage: tmuxer mv <project> <new-path-or-name>")
+	}
+
+	projects, err := findProjectDirectories(cfg)
+	if err != nil {
+		return err
+	}
+	project := findProjectByName(projects, args[0])
+	if project == nil {
+		return fmt.Errorf("project %q not found", args[0])
+	}
+	if project.Remote != nil || project.Repo != "" {
+		return fmt.Errorf("project %q has no local directory to move", args[0])
+	}
+
+	target := filepath.Join(filepath.Dir(project.FullPath), args[1])
+	if strings.ContainsRune(args[1], filepath.Separator) || strings.HasPrefix(args[1], "~") {
+		if target, err = normalizePath(args[1]); err != nil {
+			return err
+		}
+	}
+	_, err = moveProject(cfg, project, target)
+	return err
+}
+
+// moveProject moves the directory of project to target and updates its
+// session and the data tmuxer keeps about it. It returns the moved
+// project.
+func moveProject(cfg *Config, project *Project, target string) (*Project, error) {
+	if _, err := os.Lstat(target); err == nil {
+		return nil, fmt.Errorf("%s already exists", target)
+	}
+
+	marker := localDir
+	if len(project.Markers) > 0 {
+		marker = project.Markers[0]
+	}
+	project.Session = cfg.sessionName(project)
+	oldSession := mappedSession(project)
+
+	if err := os.MkdirAll(filepath.Dir(target), 0o755); err != nil {
+		return nil, err
+	}
+	if err := os.Rename(project.FullPath, target); err != nil {
+		return nil, err
+	}
+	fmt.Printf("Moved %s to %s\n", project.FullPath, target)
+
+	moved := hereProject(cfg, target, marker)
+	moved.Session = cfg.sessionName(moved)
+	if err := moveSession(cfg, oldSession, moved); err != nil {
+		slog.Warn("failed to rename session", "session", oldSession, "err", err)
+	}
+
+	old := &Project{Name: project.Name, FullPath: project.FullPath, Session: oldSession}
+	for _, m := range []struct {
+		what string
+		move func(old, moved *Project) error
+	}{
+		{"history", moveHistory},
+		{"bookmarks", moveBookmarks},
+		{"session paths", moveSessionPaths},
+		{"git cache", moveEnrichment},
+		{"archived projects", moveArchived},
+		{"pinned projects", movePinned},
+		{"trusted project configs", moveTrusted},
+	} {
+		if err := m.move(old, moved); err != nil {
+			slog.Warn("failed to update "+m.what, "err", err)
+		}
+	}
+	return moved, nil
+}
+
+// movedPath returns p with the directory old replaced by new, and whether
+// p is old or inside it.
+func movedPath(p, old, new string) (string, bool) {
+	if p == old {
+		return new, true
+	}
+	if rest, ok := strings.CutPrefix(p, old+string(filepath.Separator)); ok {
+		return filepath.Join(new, rest), true
+	}
+	return p, false
+}
+
+// moveSession renames the running session of the moved project after it
+// and records its new directory.
+func moveSession(cfg *Config, session string, moved *Project) error {
+	if !cfg.usesTmux() {
+		return nil
+	}
+	exists, err := tmuxClient.HasSession(session)
+	if err != nil || !exists {
+		return err
+	}
+	if session != moved.Session {
+		taken, err := tmuxClient.HasSession(moved.Session)
+		if err != nil {
+			return err
+		}
+		if taken {
+			return fmt.Errorf("session %s already exists", moved.Session)
+		}
+		if err := runTmuxCommand("rename-session", "-t", session, moved.Session); err != nil {
+			return err
+		}
+		fmt.Printf("Renamed session %s to %s\n", session, moved.Session)
+	}
+	return markSession(moved)
+}
+
+func moveHistory(old, moved *Project) error {
+	entries, err := readHistory()
+	if err != nil || len(entries) == 0 {
+		return err
+	}
+	for i, entry := range entries {
+		p, ok := movedPath(entry.Path, old.FullPath, moved.FullPath)
+		if !ok {
+			continue
+		}
+		entries[i].Path = p
+		if p == moved.FullPath {
+			entries[i].Name = moved.Name
+			if entry.Session == old.Session {
+				entries[i].Session = moved.Session
+			}
+		}
+	}
+	return writeHistory(entries)
+}
+
+func moveBookmarks(old, moved *Project) error {
+	bookmarks, err := loadBookmarks()
+	if err != nil || len(bookmarks) == 0 {
+		return err
+	}
+	for i, b := range bookmarks {
+		bookmarks[i].Path, _ = movedPath(b.Path, old.FullPath, moved.FullPath)
+		if b.Path == old.FullPath && b.Name == filepath.Base(old.FullPath) {
+			bookmarks[i].Name = filepath.Base(moved.FullPath)
+		}
+	}
+	return saveBookmarks(bookmarks)
+}
+
+func moveSessionPaths(old, moved *Project) error {
+	paths, err := loadSessionPaths()
+	if err != nil || len(paths) == 0 {
+		return err
+	}
+	updated := make(map[string]string, len(paths))
+	for session, p := range paths {
+		p, ok := movedPath(p, old.FullPath, moved.FullPath)
+		if ok && session == old.Session {
+			session = moved.Session
+		}
+		updated[session] = p
+	}
+	return saveSessionPaths(updated)
+}
+
+func moveEnrichment(old, moved *Project) error {
+	cache := loadEnrichmentCache()
+	updated := make(map[string]*Enrichment, len(cache))
+	for p, e := range cache {
+		p, _ = movedPath(p, old.FullPath, moved.FullPath)
+		updated[p] = e
+	}
+	return saveEnrichmentCache(updated)
+}
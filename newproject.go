@@ -0,0 +1,168 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/bmatcuk/doublestar/v4"
+	"github.com/gdamore/tcell/v2"
+	"gopkg.in/yaml.v3"
+)
+
+// ProjectTemplate scaffolds projects created with tmuxer new --template.
+type ProjectTemplate struct {
+	// Command runs in the directory of the new project, such as a
+	// cookiecutter or git clone of a template repository.
+	Command string `yaml:"command"`
+	// Layout opens the new project with this layout rather than the one
+	// of its type.
+	Layout string `yaml:"layout"`
+}
+
+func (t *ProjectTemplate) UnmarshalYAML(value *yaml.Node) error {
+	if value.Kind == yaml.ScalarNode {
+		return value.Decode(&t.Command)
+	}
+
+	type plain ProjectTemplate
+	return value.Decode((*plain)(t))
+}
+
+// runNewCommand creates a project in a base the user picks, scaffolds it
+// with the template given with --template or the scaffold command, and opens
+// it.
+func runNewCommand(cfg *Config, args []string) error {
+	if len(args) != 1 {
+		return errors.New("usage: tmuxer new <name> [--template <name>]")
+	}
+	project, err := createProject(cfg, args[0], nil)
+	if err != nil {
+		return err
+	}
+	return runActions(cfg, project)
+}
+
+// projectTemplate returns the template selected with --template, or nil
+// without one.
+func (cfg *Config) projectTemplate() (*ProjectTemplate, error) {
+	if *templateName == "" {
+		return nil, nil
+	}
+	t, ok := cfg.Templates[*templateName]
+	if !ok || t == nil {
+		return nil, fmt.Errorf("template %q is not defined, expected one of: %s", *templateName, strings.Join(sortedKeys(cfg.Templates), ", "))
+	}
+	return t, nil
+}
+
+// noMatchEntered wraps the key handler of the picker to note in entered
+// whether enter was pressed while nothing matched the query.
+func noMatchEntered(handler func(*tcell.EventKey, int) bool, entered *bool) func(*tcell.EventKey, int) bool {
+	return func(e *tcell.EventKey, i int) bool {
+		*entered = e.Key() == tcell.KeyEnter && i < 0
+		return handler(e, i)
+	}
+}
+
+// offerNewProject asks whether to create a project named after the query
+// nothing matched in the picker, and creates it when the answer is yes.
+func offerNewProject(cfg *Config, query string, projects []*Project) (*Project, error) {
+	answer, err := prompt(fmt.Sprintf("Create new project %s? [y/N] ", query))
+	if err != nil {
+		return nil, err
+	}
+	if !isYes(answer, false) {
+		return nil, errCancelled
+	}
+	return createProject(cfg, query, projects)
+}
+
+// createProject creates the directory of a new project named name in a base
+// the user picks and runs the command of the template given with --template,
+// or the scaffold command, in it.
+func createProject(cfg *Config, name string, projects []*Project) (*Project, error) {
+	if !filepath.IsLocal(name) {
+		return nil, fmt.Errorf("invalid project name %q", name)
+	}
+	command := cfg.Scaffold
+	tmpl, err := cfg.projectTemplate()
+	if err != nil {
+		return nil, err
+	}
+	if tmpl != nil {
+		command = tmpl.Command
+	}
+	root, err := chooseBase(cfg, "create", projects)
+	if err != nil {
+		return nil, err
+	}
+	dir := filepath.Join(root, name)
+	if _, err := os.Stat(dir); err == nil {
+		return nil, fmt.Errorf("%s already exists", dir)
+	}
+	// the outermost directory created for a name such as group/api, which
+	// goes again when the project cannot be set up
+	created := dir
+	for parent := filepath.Dir(created); parent != root; parent = filepath.Dir(parent) {
+		if _, err := os.Stat(parent); err == nil {
+			break
+		}
+		created = parent
+	}
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return nil, fmt.Errorf("failed to create project: %w", err)
+	}
+	fmt.Printf("Created %s\n", dir)
+	remove := func() {
+		if err := os.RemoveAll(created); err != nil {
+			slog.Warn("failed to remove the project directory", "dir", created, "err", err)
+			return
+		}
+		fmt.Printf("Removed %s\n", created)
+	}
+
+	project, err := newProject(filepath.Base(dir), dir)
+	if err != nil {
+		remove()
+		return nil, err
+	}
+	for _, b := range cfg.ProjectBase {
+		if base, _ := doublestar.SplitPattern(b.Path); base == root {
+			project.Base = b
+			break
+		}
+	}
+
+	if err := scaffold(command, project); err != nil {
+		remove()
+		return nil, err
+	}
+	if tmpl != nil {
+		project.Layout = tmpl.Layout
+	}
+	return project, nil
+}
+
+// scaffold runs command, such as git init, in the directory of a project
+// that was just created. Its name and path are passed in
+// TMUXER_PROJECT_NAME and TMUXER_PROJECT_PATH.
+func scaffold(command string, project *Project) error {
+	if strings.TrimSpace(command) == "" {
+		return nil
+	}
+	cmd := shellCommand(command)
+	cmd.Dir = project.FullPath
+	cmd.Env = append(os.Environ(),
+		"TMUXER_PROJECT_NAME="+project.Name,
+		"TMUXER_PROJECT_PATH="+project.FullPath,
+	)
+	cmd.Stdin, cmd.Stdout, cmd.Stderr = os.Stdin, os.Stdout, os.Stderr
+	if err := runCommand(cmd); err != nil {
+		return fmt.Errorf("scaffold %q failed: %w", command, err)
+	}
+	return nil
+}
@@ -0,0 +1,34 @@
+package main
+
+import (
+	"fmt"
+	"log/slog"
+	"strings"
+)
+
+// displayMessage shows message in the status line of the clients attached
+// to session, or of the most recently active client when session is empty.
+func displayMessage(session, message string) error {
+	// the message is a format, # starts a format variable
+	args := []string{strings.ReplaceAll(message, "#", "##")}
+	if session != "" {
+		args = append([]string{"-t", session + ":"}, args...)
+	}
+	_, err := tmuxOutput("display-message", args...)
+	return err
+}
+
+// notify prints the outcome of an operation that ran in the background and
+// also shows it in tmux, as the terminal tmuxer ran in may be gone or out of
+// sight by the time it is done.
+func notify(cfg *Config, format string, args ...any) {
+	message := fmt.Sprintf(format, args...)
+	fmt.Println(message)
+	if !cfg.usesTmux() || *noTmux {
+		return
+	}
+	if err := displayMessage("", "tmuxer: "+message); err != nil {
+		// usually no client is attached
+		slog.Debug("failed to show message", "err", err)
+	}
+}
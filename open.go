@@ -0,0 +1,38 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+)
+
+// Variant is an alternative configuration a project can be opened with, in
+// its own session named <project>@<variant>.
+type Variant struct {
+	Layout string            `yaml:"layout"`
+	Env    map[string]string `yaml:"env"`
+}
+
+// runOpenCommand opens the project with the given name without showing the
+// picker.
+func runOpenCommand(cfg *Config, args []string) error {
+	if len(args) != 1 {
+		return errors.New("usage: tmuxer open <project> [--variant <name>]")
+	}
+
+	// aliases are opened without a scan
+	if project := cfg.aliasProject(args[0]); project != nil {
+		return runActions(cfg, project)
+	}
+
+	projects, err := findProjectDirectories(cfg)
+	if err != nil {
+		return err
+	}
+
+	project := findProjectByName(projects, args[0])
+	if project == nil {
+		return fmt.Errorf("project %q not found", args[0])
+	}
+
+	return runActions(cfg, project)
+}
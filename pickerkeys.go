@@ -0,0 +1,215 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"log/slog"
+	"sort"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"unicode/utf8"
+
+	"github.com/gdamore/tcell/v2"
+)
+
+// pickerActions are the actions keys of the picker can be bound to under
+// picker_keys. The binding is the action name, followed by its argument for
+// popup. edit is added by init. preview cycles what the preview window
+// shows, see previewModes. dirty works on the list rather than a project,
+// see pickerKeyHandler.
+var pickerActions = map[string]func(cfg *Config, project *Project, arg string) error{
+	"dirty": nil,
+	// kill kills the session of the highlighted project.
+	"kill": func(_ *Config, project *Project, _ string) error {
+		if project.Tmux == nil {
+			return nil
+		}
+		if _, err := tmuxOutput("kill-session", "-t", "="+project.Tmux.Name); err != nil {
+			return err
+		}
+		project.Tmux = nil
+		return nil
+	},
+	// popup runs its argument, such as lazygit, in a tmux popup in the
+	// project directory.
+	"popup": func(_ *Config, project *Project, command string) error {
+		if !tmuxClient.IsInsideTmux() {
+			return errors.New("popups need the picker to run inside tmux")
+		}
+		dir, _ := windowStart(project)
+		_, err := tmuxOutput("display-popup", "-E", "-w", "90%", "-h", "90%", "-d", dir, command)
+		return err
+	},
+	"preview": cyclePreview,
+}
+
+func init() {
+	// added here as runActions depends on the config validation, which
+	// checks picker_keys against pickerActions
+	pickerActions["edit"] = editInBackground
+}
+
+// editInBackground opens project in its editor window, creating the session
+// in the background, without attaching to it.
+func editInBackground(cfg *Config, project *Project, _ string) error {
+	if project.Remote != nil {
+		return fmt.Errorf("project %s is remote", project.Name)
+	}
+	// nobody can answer a confirmation while the picker is shown
+	editCfg := *cfg
+	editCfg.ConfirmCreate = false
+	project.Session = ""
+	if err := runActions(&editCfg, project, actionAttach); err != nil {
+		return err
+	}
+	return focusEditor(&editCfg, project)
+}
+
+// parsePickerAction splits a binding into the action and its argument.
+func parsePickerAction(binding string) (string, string, error) {
+	name, arg, _ := strings.Cut(strings.TrimSpace(binding), " ")
+	if _, ok := pickerActions[name]; !ok {
+		return "", "", fmt.Errorf("unknown picker action %q, expected one of: %s", name, strings.Join(sortedKeys(pickerActions), ", "))
+	}
+	arg = strings.TrimSpace(arg)
+	if name == "popup" && arg == "" {
+		return "", "", errors.New("popup needs the command to run, such as popup lazygit")
+	}
+	return name, arg, nil
+}
+
+// pickerKeyName is the name of key e as used in picker_keys: the lowercase
+// tcell name such as ctrl-k or f2, or alt- followed by the character.
+func pickerKeyName(e *tcell.EventKey) string {
+	if e.Key() == tcell.KeyRune {
+		if e.Modifiers()&tcell.ModAlt == 0 {
+			return ""
+		}
+		return "alt-" + string(e.Rune())
+	}
+	return strings.ToLower(tcell.KeyNames[e.Key()])
+}
+
+// checkPickerKey returns an error unless name is a key picker_keys can bind.
+func checkPickerKey(name string) error {
+	if rest, ok := strings.CutPrefix(name, "alt-"); ok && utf8.RuneCountInString(rest) == 1 {
+		return nil
+	}
+	var names []string
+	for _, n := range tcell.KeyNames {
+		if strings.ToLower(n) == name {
+			return nil
+		}
+		if strings.HasPrefix(n, "Ctrl-") {
+			names = append(names, strings.ToLower(n))
+		}
+	}
+	sort.Strings(names)
+	return fmt.Errorf("unknown picker key %q, expected alt- followed by a character, a function key such as f2 or one of %s", name, strings.Join(names, ", "))
+}
+
+// pickerKeyHandler returns the key handler of the picker running the actions
+// bound in picker_keys on the highlighted project, which project returns.
+// Changes of the actions to the project are made holding lock, the hot
+// reload lock of the picker. The dirty action calls toggleDirty, nil when
+// the picker cannot filter its list. The errors of the actions are
+// collected in errs, as the picker owns the terminal.
+func (cfg *Config) pickerKeyHandler(project func(i int) *Project, lock sync.Locker, toggleDirty func(), errs *[]error) func(*tcell.EventKey, int) bool {
+	return func(e *tcell.EventKey, i int) bool {
+		name := pickerKeyName(e)
+		binding, ok := cfg.PickerKeys[name]
+		if !ok && name == defaultPreviewKey {
+			binding, ok = "preview", true
+		}
+		if !ok {
+			return false
+		}
+		name, arg, err := parsePickerAction(binding)
+		if err == nil && name == "dirty" {
+			// also when the filter left nothing to highlight
+			if toggleDirty == nil {
+				*errs = append(*errs, fmt.Errorf("%s: not available while projects stream in", binding))
+			} else {
+				toggleDirty()
+			}
+			return true
+		}
+		if i < 0 {
+			return true
+		}
+		p := project(i)
+		// the stale toggle and similar entries are no projects
+		if p == nil || p.FullPath == "" {
+			return true
+		}
+
+		if err == nil {
+			slog.Debug("running picker action", "action", name, "project", p.Name)
+			// on a copy, as actions such as popups block while the picker
+			// draws the project
+			changed := *p
+			err = pickerActions[name](cfg, &changed, arg)
+			lock.Lock()
+			*p = changed
+			lock.Unlock()
+		}
+		if err != nil {
+			*errs = append(*errs, fmt.Errorf("%s: %w", binding, err))
+		}
+		return true
+	}
+}
+
+// pickerList is the list of projects the picker shows, which the dirty
+// action narrows down to those with uncommitted changes and back.
+type pickerList struct {
+	mu  sync.Mutex
+	all []*Project
+	// items are the projects listed, guarded by mu.
+	items []*Project
+	// shown is a copy of items read without mu, which the picker holds
+	// while it draws.
+	shown atomic.Pointer[[]*Project]
+	dirty bool
+}
+
+func newPickerList(projects []*Project) *pickerList {
+	l := &pickerList{all: projects, items: projects}
+	l.shown.Store(&projects)
+	return l
+}
+
+// project returns the project listed at index i, nil when there is none.
+func (l *pickerList) project(i int) *Project {
+	shown := *l.shown.Load()
+	if i < 0 || i >= len(shown) {
+		return nil
+	}
+	return shown[i]
+}
+
+// toggleDirty switches between listing all projects and only those with
+// uncommitted changes, reading their git status the first time.
+func (l *pickerList) toggleDirty() {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if !l.dirty {
+		enrichProjects(l.all)
+	}
+	l.dirty = !l.dirty
+	items := l.all
+	if l.dirty {
+		items = filterDirty(l.all)
+	}
+	l.items = items
+	l.shown.Store(&items)
+}
+
+// reportPickerErrors logs the errors of picker actions once the picker is
+// closed.
+func reportPickerErrors(errs []error) {
+	for _, err := range errs {
+		slog.Warn("picker action failed", "err", err)
+	}
+}
@@ -0,0 +1,164 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"sort"
+)
+
+const (
+	pinnedFile = "pinned.json"
+	// pinGlyph follows the names of pinned projects in the picker.
+	pinGlyph = "📌"
+)
+
+// runPinCommand pins a project, so tmuxer gc never kills its sessions and it
+// is listed first in the picker. Without a project the pinned ones are
+// listed.
+func runPinCommand(cfg *Config, args []string) error {
+	if len(args) > 1 {
+		return errors.New("usage: tmuxer pin [project]")
+	}
+	pinned, err := loadPinned()
+	if err != nil {
+		return err
+	}
+	if len(args) == 0 {
+		for _, p := range pinned {
+			fmt.Println(p)
+		}
+		return nil
+	}
+
+	projects, err := findProjectDirectories(cfg)
+	if err != nil {
+		return err
+	}
+	project := findProjectByName(projects, args[0])
+	if project == nil {
+		return fmt.Errorf("project %q not found", args[0])
+	}
+	if contains(pinned, project.FullPath) {
+		return fmt.Errorf("project %q is already pinned", args[0])
+	}
+	if err := savePinned(append(pinned, project.FullPath)); err != nil {
+		return err
+	}
+	fmt.Printf("Pinned %s\n", project.Name)
+	return nil
+}
+
+// runUnpinCommand lets tmuxer gc kill the sessions of a pinned project
+// again.
+func runUnpinCommand(cfg *Config, args []string) error {
+	if len(args) != 1 {
+		return errors.New("usage: tmuxer unpin <project>")
+	}
+
+	projects, err := findProjectDirectories(cfg)
+	if err != nil {
+		return err
+	}
+	project := findProjectByName(projects, args[0])
+	if project == nil {
+		return fmt.Errorf("project %q not found", args[0])
+	}
+
+	pinned, err := loadPinned()
+	if err != nil {
+		return err
+	}
+	kept := []string{}
+	for _, p := range pinned {
+		if p != project.FullPath {
+			kept = append(kept, p)
+		}
+	}
+	if len(kept) == len(pinned) {
+		return fmt.Errorf("project %q is not pinned", args[0])
+	}
+	if err := savePinned(kept); err != nil {
+		return err
+	}
+	fmt.Printf("Unpinned %s\n", project.Name)
+	return nil
+}
+
+// pinnedFirst marks the pinned projects and moves them to the front,
+// keeping the order among them and among the others.
+func pinnedFirst(projects []*Project) {
+	pinned, err := loadPinned()
+	if err != nil {
+		slog.Warn("failed to load pinned projects", "err", err)
+		return
+	}
+	if len(pinned) == 0 {
+		return
+	}
+	for _, p := range projects {
+		p.Pinned = contains(pinned, p.FullPath)
+	}
+	sort.SliceStable(projects, func(i, j int) bool {
+		return projects[i].Pinned && !projects[j].Pinned
+	})
+}
+
+func pinnedPath() (string, error) {
+	dir, err := dataDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, pinnedFile), nil
+}
+
+// loadPinned returns the directories of the pinned projects.
+func loadPinned() ([]string, error) {
+	p, err := pinnedPath()
+	if err != nil {
+		return nil, err
+	}
+	data, err := os.ReadFile(p)
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	var pinned []string
+	if err := json.Unmarshal(data, &pinned); err != nil {
+		return nil, fmt.Errorf("corrupt pinned projects file %s: %w", p, err)
+	}
+	return pinned, nil
+}
+
+func savePinned(pinned []string) error {
+	p, err := pinnedPath()
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(p), 0o755); err != nil {
+		return err
+	}
+	data, err := json.MarshalIndent(pinned, "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(p, data, 0o644)
+}
+
+func movePinned(old, moved *Project) error {
+	pinned, err := loadPinned()
+	if err != nil || len(pinned) == 0 {
+		return err
+	}
+	for i, p := range pinned {
+		pinned[i], _ = movedPath(p, old.FullPath, moved.FullPath)
+	}
+	return savePinned(pinned)
+}
@@ -0,0 +1,109 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"strings"
+)
+
+// pluginScript is the TPM entry point, tmuxer.tmux in the repository root.
+// TPM runs it on every tmux start; it installs the binary next to the plugin
+// when tmuxer is not on the PATH and then hands over to tmuxer plugin install.
+// The binary is the release asset tmuxer_<os>_<arch> of the release tagged
+// in the plugin checkout, built with go install when there is none.
+const pluginScript = `#!/usr/bin/env bash
+# Generated by tmuxer plugin script, do not edit.
+CURRENT_DIR="$(cd "$(dirname "${BASH_SOURCE[0]}")" && pwd)"
+
+install_tmuxer() {
+	local os arch version url bin="$CURRENT_DIR/bin/tmuxer"
+	os="$(uname -s | tr '[:upper:]' '[:lower:]')"
+	case "$(uname -m)" in
+	x86_64 | amd64) arch=amd64 ;;
+	aarch64 | arm64) arch=arm64 ;;
+	armv*) arch=arm ;;
+	i?86) arch=386 ;;
+	*) arch="$(uname -m)" ;;
+	esac
+
+	# the release the plugin checkout is at, the latest one without tags
+	version="$(git -C "$CURRENT_DIR" describe --tags --abbrev=0 2>/dev/null)"
+	if [ -n "$version" ]; then
+		url="https://github.com/k1ng440/tmuxer/releases/download/$version/tmuxer_${os}_${arch}"
+	else
+		url="https://github.com/k1ng440/tmuxer/releases/latest/download/tmuxer_${os}_${arch}"
+	fi
+	mkdir -p "$CURRENT_DIR/bin"
+	if curl -fsSL -o "$bin" "$url" 2>/dev/null || wget -qO "$bin" "$url" 2>/dev/null; then
+		chmod +x "$bin"
+		return 0
+	fi
+	rm -f "$bin"
+
+	# no release binary for this platform
+	command -v go >/dev/null && GOBIN="$CURRENT_DIR/bin" go install "github.com/k1ng440/tmuxer@${version:-latest}"
+}
+
+TMUXER="$CURRENT_DIR/bin/tmuxer"
+if [ ! -x "$TMUXER" ]; then
+	TMUXER="$(command -v tmuxer)"
+fi
+if [ -z "$TMUXER" ]; then
+	tmux display-message "tmuxer: installing binary"
+	if ! install_tmuxer; then
+		tmux display-message "tmuxer: install failed, see https://github.com/k1ng440/tmuxer"
+		exit 1
+	fi
+	TMUXER="$CURRENT_DIR/bin/tmuxer"
+fi
+
+"$TMUXER" plugin install
+`
+
+// runPluginCommand integrates tmuxer as a TPM plugin. install applies the
+// key binding and hooks to the running tmux server, configured through the
+// @tmuxer-key and @tmuxer-hooks options; script prints the TPM entry point.
+func runPluginCommand(_ *Config, args []string) error {
+	if len(args) == 0 {
+		return fmt.Errorf("expected a plugin command: install or script")
+	}
+
+	switch args[0] {
+	case "install":
+		return installPlugin()
+	case "script":
+		fmt.Print(pluginScript)
+		return nil
+	default:
+		return fmt.Errorf("unknown plugin command %q, expected install or script", args[0])
+	}
+}
+
+func installPlugin() error {
+	exe, err := os.Executable()
+	if err != nil {
+		return err
+	}
+
+	key, _ := tmuxOutput("show-option", "-gqv", "@tmuxer-key")
+	if key == "" {
+		key = "T"
+	}
+	hooks, _ := tmuxOutput("show-option", "-gqv", "@tmuxer-hooks")
+
+	file, err := os.CreateTemp("", "tmuxer-*.conf")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(file.Name())
+
+	lines := tmuxConfLines(exe, key, hooks != "off")
+	if _, err := file.WriteString(strings.Join(lines, "\n") + "\n"); err != nil {
+		file.Close()
+		return err
+	}
+	if err := file.Close(); err != nil {
+		return err
+	}
+	return runTmuxCommand("source-file", file.Name())
+}
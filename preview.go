@@ -0,0 +1,151 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"sync"
+	"sync/atomic"
+)
+
+// previewModes are what the preview window shows, cycled through with the
+// preview picker action.
+var previewModes = []string{"info", "readme", "log"}
+
+// defaultPreviewKey cycles the preview modes unless picker_keys binds it to
+// something else.
+const defaultPreviewKey = "ctrl-t"
+
+// readmeLines is how many lines of the README the preview shows.
+const readmeLines = 40
+
+// previewMode is the index of the current mode in previewModes.
+var previewMode atomic.Int32
+
+// cyclePreview switches the preview to the next of previewModes.
+func cyclePreview(*Config, *Project, string) error {
+	previewMode.Store((previewMode.Load() + 1) % int32(len(previewModes)))
+	return nil
+}
+
+// previewText describes project in the preview window of the picker, in
+// the current preview mode.
+func previewText(cfg *Config, project *Project) string {
+	if project.FullPath == "" {
+		return ""
+	}
+	switch previewModes[previewMode.Load()] {
+	case "readme":
+		return paint(cfg.theme().Label, "README") + "\n" + cachedPreview("readme", project, readmePreview)
+	case "log":
+		return paint(cfg.theme().Label, "Recent commits") + "\n" + cachedPreview("log", project, gitLogPreview)
+	default:
+		return previewInfo(cfg, project)
+	}
+}
+
+var (
+	previewMu    sync.Mutex
+	previewCache = make(map[string]string)
+)
+
+// cachedPreview returns the text of a preview mode for project, rendering it
+// once per picker rather than on every redraw.
+func cachedPreview(mode string, project *Project, render func(*Project) string) string {
+	key := mode + "\x00" + project.FullPath
+	previewMu.Lock()
+	text, ok := previewCache[key]
+	previewMu.Unlock()
+	if ok {
+		return text
+	}
+
+	if project.Remote != nil || project.FullPath == project.Repo {
+		text = "not available for projects that are not checked out locally"
+	} else {
+		text = render(project)
+	}
+	previewMu.Lock()
+	previewCache[key] = text
+	previewMu.Unlock()
+	return text
+}
+
+// readmePreview returns the start of the README of project without the
+// markdown markup.
+func readmePreview(project *Project) string {
+	entries, err := os.ReadDir(project.FullPath)
+	if err != nil {
+		return err.Error()
+	}
+	for _, e := range entries {
+		if e.IsDir() || !strings.HasPrefix(strings.ToLower(e.Name()), "readme") {
+			continue
+		}
+		data, err := os.ReadFile(filepath.Join(project.FullPath, e.Name()))
+		if err != nil {
+			return err.Error()
+		}
+		return stripMarkdown(string(data), readmeLines)
+	}
+	return "no README"
+}
+
+var (
+	markdownImage    = regexp.MustCompile(`!\[[^\]]*\]\([^)]*\)`)
+	markdownLink     = regexp.MustCompile(`\[([^\]]*)\]\([^)]*\)`)
+	markdownRefLink  = regexp.MustCompile(`\[([^\]]*)\]\[[^\]]*\]`)
+	markdownEmphasis = regexp.MustCompile("(\\*\\*|__|`)")
+	htmlTag          = regexp.MustCompile(`<[^>]+>`)
+	markdownHeading  = regexp.MustCompile(`^#{1,6}\s+`)
+	markdownRule     = regexp.MustCompile(`^(-{3,}|\*{3,}|_{3,}|={3,})$`)
+	markdownRefDef   = regexp.MustCompile(`^\[[^\]]+\]:\s`)
+)
+
+// stripMarkdown returns up to max lines of the markdown text with images,
+// badges, html, link targets, emphasis, headings marks and code fences
+// removed, and runs of empty lines collapsed.
+func stripMarkdown(text string, max int) string {
+	var lines []string
+	blank := true
+	for _, line := range strings.Split(text, "\n") {
+		line = strings.TrimRight(line, " \t\r")
+		if strings.HasPrefix(strings.TrimSpace(line), "```") || markdownRule.MatchString(line) || markdownRefDef.MatchString(line) {
+			continue
+		}
+		line = markdownImage.ReplaceAllString(line, "")
+		line = markdownLink.ReplaceAllString(line, "$1")
+		line = markdownRefLink.ReplaceAllString(line, "$1")
+		line = htmlTag.ReplaceAllString(line, "")
+		line = markdownEmphasis.ReplaceAllString(line, "")
+		line = markdownHeading.ReplaceAllString(line, "")
+		if strings.TrimSpace(line) == "" {
+			if !blank {
+				lines = append(lines, "")
+			}
+			blank = true
+			continue
+		}
+		blank = false
+		lines = append(lines, line)
+		if len(lines) >= max {
+			break
+		}
+	}
+	return strings.TrimSpace(strings.Join(lines, "\n"))
+}
+
+// gitLogPreview returns the last five commits of project.
+func gitLogPreview(project *Project) string {
+	output, err := commandOutput(exec.CommandContext(appContext, "git", "-C", project.FullPath, "log", "-5", "--format=%h %s (%cr, %an)"))
+	if err != nil {
+		return fmt.Sprintf("no git history: %v", err)
+	}
+	if len(output) == 0 {
+		return "no commits"
+	}
+	return strings.TrimSpace(string(output))
+}
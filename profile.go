@@ -0,0 +1,47 @@
+package main
+
+import (
+	"fmt"
+	"os"
+
+	"gopkg.in/yaml.v3"
+)
+
+// selectedProfile returns the name of the profile given with --profile or
+// TMUXER_PROFILE, or else the profile key of doc.
+func selectedProfile(doc *yaml.Node) string {
+	if *profileName != "" {
+		return *profileName
+	}
+	if name := os.Getenv("TMUXER_PROFILE"); name != "" {
+		return name
+	}
+	if n := findNode(doc, "profile"); n != nil {
+		return n.Value
+	}
+	return ""
+}
+
+// applyProfile merges the selected profile under profiles over the rest of
+// doc, the root mapping of the config at path, like an included file merged
+// last: its mappings are merged key by key and its other values, such as
+// base, replace those of the config.
+func applyProfile(path string, doc *yaml.Node) (*yaml.Node, error) {
+	name := selectedProfile(doc)
+	if name == "" {
+		return doc, nil
+	}
+	profile := findNode(doc, "profiles", name)
+	if profile == nil {
+		problem := fmt.Sprintf("profile %q is not defined", name)
+		if n := findNode(doc, "profile"); n != nil && n.Value == name {
+			problem = fmt.Sprintf("line %d: %s", n.Line, problem)
+		}
+		return nil, &ConfigError{Path: path, Problems: []string{problem}}
+	}
+	if findNode(profile, "profiles") != nil || findNode(profile, "include") != nil {
+		return nil, &ConfigError{Path: path, Problems: []string{fmt.Sprintf("line %d: profile %q cannot have profiles or include", profile.Line, name)}}
+	}
+	mergeNode(doc, profile)
+	return doc, nil
+}
@@ -0,0 +1,111 @@
+package main
+
+import (
+	"errors"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// localConfigFile is an optional file in the project directory overriding
+// the per-project settings of the main config once approved with tmuxer
+// trust.
+const localConfigFile = ".tmuxer.yaml"
+
+// projectConfig returns the settings for project, merging the projects
+// section of the config with the project's own .tmuxer.yaml, which takes
+// precedence. A worktree gets the settings of its parent project first.
+func (cfg *Config) projectConfig(project *Project) *ProjectConfig {
+	merged := &ProjectConfig{Env: make(map[string]string)}
+	if project.Parent != nil {
+		if pc := cfg.Projects[project.Parent.Name]; pc != nil {
+			merged.merge(pc)
+		}
+	}
+	if pc := cfg.Projects[project.Name]; pc != nil {
+		merged.merge(pc)
+	}
+
+	if project.Remote == nil {
+		local, err := loadLocalProjectConfig(project.FullPath)
+		if err != nil {
+			slog.Warn("ignoring project config", "project", project.Name, "err", err)
+		} else if local != nil {
+			for k, v := range local.Env {
+				if strings.HasPrefix(v, secretPrefix) {
+					slog.Warn("ignoring secret in project config, secrets are only resolved from your own config", "project", project.Name, "env", k)
+					delete(local.Env, k)
+				}
+			}
+			merged.merge(local)
+		}
+	}
+
+	return merged
+}
+
+func (pc *ProjectConfig) merge(other *ProjectConfig) {
+	for k, v := range other.Env {
+		pc.Env[k] = v
+	}
+	if other.DefaultCommand != "" {
+		pc.DefaultCommand = other.DefaultCommand
+	}
+	if other.Editor != "" {
+		pc.Editor = other.Editor
+	}
+}
+
+func loadLocalProjectConfig(dir string) (*ProjectConfig, error) {
+	p := filepath.Join(dir, localConfigFile)
+	data, err := os.ReadFile(p)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	if !isTrusted(p, data) {
+		return nil, nil
+	}
+
+	pc := &ProjectConfig{}
+	if err := decodeStrict(p, data, pc); err != nil {
+		return nil, err
+	}
+	return pc, nil
+}
+
+// defaultCommand is the command run in the initial window of a new session:
+// --cmd, or the project's default_command, or the global one.
+func (cfg *Config) defaultCommand(project *Project) string {
+	if *initialCommand != "" {
+		return *initialCommand
+	}
+	if pc := cfg.projectConfig(project); pc.DefaultCommand != "" {
+		return pc.DefaultCommand
+	}
+	return cfg.DefaultCommand
+}
+
+// editorCommand is the command of the editor window of project: the
+// project's editor, or the global one, or $EDITOR, or vi.
+func (cfg *Config) editorCommand(project *Project) string {
+	if pc := cfg.projectConfig(project); pc.Editor != "" {
+		return pc.Editor
+	}
+	if cfg.Editor != "" {
+		return cfg.Editor
+	}
+	return systemEditor()
+}
+
+// systemEditor is $EDITOR, or vi when it is not set.
+func systemEditor() string {
+	if editor := os.Getenv("EDITOR"); editor != "" {
+		return editor
+	}
+	return "vi"
+}
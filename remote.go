@@ -0,0 +1,145 @@
+package main
+
+import (
+	"fmt"
+	"net/url"
+	"os/exec"
+	"path"
+	"strings"
+
+	"github.com/bmatcuk/doublestar/v4"
+)
+
+// Remote locates a project directory on a host reachable over ssh.
+type Remote struct {
+	// Host is the ssh destination, optionally including the user.
+	Host string
+	Port string
+	Path string
+}
+
+func isRemoteBase(base string) bool {
+	return strings.HasPrefix(base, "ssh://")
+}
+
+// parseRemoteBase splits a base such as ssh://devbox/~/src/** into the host
+// part and the pattern evaluated on the remote host.
+func parseRemoteBase(base string) (*Remote, error) {
+	u, err := url.Parse(base)
+	if err != nil {
+		return nil, fmt.Errorf("invalid remote base %q: %w", base, err)
+	}
+
+	host := u.Hostname()
+	if host == "" {
+		return nil, fmt.Errorf("remote base %q has no host", base)
+	}
+	if u.User != nil {
+		host = u.User.Username() + "@" + host
+	}
+
+	p := u.Path
+	if strings.HasPrefix(p, "/~") {
+		p = p[1:]
+	}
+	if p == "" {
+		p = "~"
+	}
+
+	return &Remote{Host: host, Port: u.Port(), Path: p}, nil
+}
+
+func (r *Remote) sshArgs() []string {
+	if r.Port != "" {
+		return []string{"-p", r.Port, r.Host}
+	}
+	return []string{r.Host}
+}
+
+// URL identifies the remote directory and doubles as the project's FullPath.
+func (r *Remote) URL() string {
+	host := r.Host
+	if r.Port != "" {
+		host += ":" + r.Port
+	}
+	return "ssh://" + host + "/" + strings.TrimPrefix(r.Path, "/")
+}
+
+// findRemoteProjects lists the entries below the static part of the base
+// pattern on the remote host and matches them against the pattern locally.
+func findRemoteProjects(basePattern string) ([]*Project, int, error) {
+	remote, err := parseRemoteBase(basePattern)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	base, pattern := doublestar.SplitPattern(remote.Path)
+	patternUsed := len(globRegex.FindStringIndex(path.Base(pattern))) > 0
+
+	// base is left unquoted so the remote shell expands ~.
+	args := append(remote.sshArgs(), "find "+base+" -mindepth 1 -print 2>/dev/null")
+	output, err := commandOutput(exec.CommandContext(appContext, "ssh", args...))
+	if err != nil && len(output) == 0 {
+		return nil, 0, fmt.Errorf("failed to list projects on %s: %w", remote.Host, err)
+	}
+
+	var (
+		projects []*Project
+		root     string
+		visited  int
+	)
+	for _, line := range strings.Split(string(output), "\n") {
+		if line == "" {
+			continue
+		}
+		visited++
+		// find expands ~ in its output; the first entry it prints is a
+		// direct child of the base, so its parent is the expanded base.
+		if root == "" {
+			root = path.Dir(line)
+		}
+
+		rel := strings.TrimPrefix(strings.TrimPrefix(line, root), "/")
+		if ok, _ := doublestar.Match(pattern, rel); !ok {
+			continue
+		}
+
+		name := projectName(base, rel, patternUsed)
+		dir := &Remote{Host: remote.Host, Port: remote.Port, Path: path.Join(base, name)}
+		project := &Project{
+			Name:     name,
+			FullPath: dir.URL(),
+			HomePath: dir.URL(),
+			Remote:   dir,
+		}
+		if patternUsed {
+			project.Markers = []string{path.Base(rel)}
+		}
+		projects = append(projects, project)
+	}
+
+	return projects, visited, nil
+}
+
+// remoteSessionCommand is the command run in the first window of the local
+// session, attaching to (or creating) a session of the same name remotely.
+func remoteSessionCommand(project *Project) string {
+	remote := fmt.Sprintf("tmux new -A -s %s -c %s", shellQuote(project.Session), remotePathArg(project.Remote.Path))
+	args := append([]string{"ssh", "-t"}, project.Remote.sshArgs()...)
+	for i := range args {
+		args[i] = shellQuote(args[i])
+	}
+	return strings.Join(args, " ") + " " + shellQuote(remote)
+}
+
+// remotePathArg quotes p for the remote shell while keeping a leading ~
+// unquoted so it is still expanded.
+func remotePathArg(p string) string {
+	if p == "~" {
+		return p
+	}
+	if strings.HasPrefix(p, "~/") {
+		return "~/" + shellQuote(p[2:])
+	}
+	return shellQuote(p)
+}
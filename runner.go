@@ -0,0 +1,181 @@
+package main
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"os/exec"
+	"path/filepath"
+	"slices"
+	"strings"
+)
+
+// Runner runs the external commands tmuxer starts, tmux, hooks and git
+// among them, so tests can replace them with fakes and --dry-run can print
+// them instead.
+type Runner interface {
+	// Run starts cmd and waits for it like cmd.Run.
+	Run(cmd *exec.Cmd) error
+}
+
+// execRunner runs commands for real.
+type execRunner struct{}
+
+func (execRunner) Run(cmd *exec.Cmd) error {
+	return cmd.Run()
+}
+
+// commandRunner runs the commands of runCommand, commandOutput and
+// commandCombinedOutput.
+var commandRunner Runner = execRunner{}
+
+// runCommand runs cmd with commandRunner.
+func runCommand(cmd *exec.Cmd) error {
+	return commandRunner.Run(cmd)
+}
+
+// commandOutput runs cmd with commandRunner and returns its standard output,
+// like cmd.Output.
+func commandOutput(cmd *exec.Cmd) ([]byte, error) {
+	var stdout, stderr bytes.Buffer
+	cmd.Stdout = &stdout
+	captureStderr := cmd.Stderr == nil
+	if captureStderr {
+		cmd.Stderr = &stderr
+	}
+	err := commandRunner.Run(cmd)
+	var exit *exec.ExitError
+	if captureStderr && errors.As(err, &exit) {
+		exit.Stderr = stderr.Bytes()
+	}
+	return stdout.Bytes(), err
+}
+
+// commandCombinedOutput runs cmd with commandRunner and returns its standard
+// output and error together, like cmd.CombinedOutput.
+func commandCombinedOutput(cmd *exec.Cmd) ([]byte, error) {
+	var output bytes.Buffer
+	cmd.Stdout, cmd.Stderr = &output, &output
+	err := commandRunner.Run(cmd)
+	return output.Bytes(), err
+}
+
+// dryRunner prints the commands that change something to out instead of
+// running them, and runs the ones that only look, such as tmux has-session,
+// with next so the rest of tmuxer sees the real state.
+type dryRunner struct {
+	out  io.Writer
+	next Runner
+}
+
+func (r dryRunner) Run(cmd *exec.Cmd) error {
+	if readOnly(cmd.Args) {
+		return r.next.Run(cmd)
+	}
+	fmt.Fprintln(r.out, commandLine(cmd))
+	return nil
+}
+
+// commandLine formats cmd as a shell command line with secrets redacted.
+func commandLine(cmd *exec.Cmd) string {
+	args := redact(cmd.Args)
+	line := make([]string, len(args))
+	for i, arg := range args {
+		line[i] = quoteArg(arg)
+	}
+	if cmd.Dir != "" {
+		return fmt.Sprintf("(cd %s && %s)", quoteArg(cmd.Dir), strings.Join(line, " "))
+	}
+	return strings.Join(line, " ")
+}
+
+// quoteArg quotes arg for the shell unless it needs no quoting.
+func quoteArg(arg string) string {
+	if arg == "" || strings.ContainsAny(arg, " \t\n'\"\\$`#*?[]{}()<>|&;~!") {
+		return shellQuote(arg)
+	}
+	return arg
+}
+
+// tmuxQueries are the tmux commands that change nothing.
+var tmuxQueries = map[string]bool{
+	"-V":               true,
+	"capture-pane":     true,
+	"has-session":      true,
+	"list-clients":     true,
+	"list-keys":        true,
+	"list-panes":       true,
+	"list-sessions":    true,
+	"list-windows":     true,
+	"show-environment": true,
+	"show-options":     true,
+	"show-option":      true,
+}
+
+// gitQueries are the git commands that change nothing.
+var gitQueries = map[string]bool{
+	"for-each-ref": true,
+	"log":          true,
+	"rev-parse":    true,
+	"status":       true,
+}
+
+// backendQueries are the commands of the other backends and of zoxide that
+// change nothing, by program and the arguments they start with.
+var backendQueries = map[string][]string{
+	"kitty":   {"@", "ls"},
+	"wezterm": {"cli", "list"},
+	"zellij":  {"list-sessions"},
+	"zoxide":  {"query"},
+}
+
+// readOnly reports whether the command line args, the program first, only
+// looks at tmux, git, a backend or zoxide without changing anything.
+func readOnly(args []string) bool {
+	if len(args) == 0 {
+		return false
+	}
+	program := strings.TrimSuffix(filepath.Base(args[0]), ".exe")
+	args = args[1:]
+	if program == "wsl" && len(args) > 1 && args[0] == "--exec" {
+		program, args = args[1], args[2:]
+	}
+
+	switch program {
+	case "tmux":
+		// skip the socket and -u, see tmuxCommand
+		for len(args) > 0 {
+			if (args[0] == "-L" || args[0] == "-S") && len(args) > 1 {
+				args = args[2:]
+			} else if args[0] == "-u" {
+				args = args[1:]
+			} else {
+				break
+			}
+		}
+		if len(args) == 0 {
+			return false
+		}
+		if args[0] == "display-message" {
+			// without -p it shows the message in the status line
+			return contains(args, "-p")
+		}
+		return tmuxQueries[args[0]]
+	case "git":
+		for len(args) > 1 && args[0] == "-C" {
+			args = args[2:]
+		}
+		if len(args) > 1 && args[0] == "worktree" {
+			return args[1] == "list"
+		}
+		if len(args) > 1 && args[0] == "remote" {
+			return args[1] == "get-url"
+		}
+		return len(args) > 0 && gitQueries[args[0]]
+	}
+	if query, ok := backendQueries[program]; ok {
+		return len(args) >= len(query) && slices.Equal(args[:len(query)], query)
+	}
+	return false
+}
@@ -0,0 +1,140 @@
+package main
+
+import (
+	"bytes"
+	"io"
+	"os/exec"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+// fakeRunner records the commands it is given and answers them with canned
+// output by command line.
+type fakeRunner struct {
+	output map[string]string
+	ran    []string
+}
+
+func (f *fakeRunner) Run(cmd *exec.Cmd) error {
+	line := strings.Join(cmd.Args, " ")
+	f.ran = append(f.ran, line)
+	if cmd.Stdout != nil {
+		io.WriteString(cmd.Stdout, f.output[line])
+	}
+	return nil
+}
+
+// useRunner makes runner the commandRunner for the rest of the test.
+func useRunner(t *testing.T, runner Runner) {
+	saved := commandRunner
+	commandRunner = runner
+	t.Cleanup(func() { commandRunner = saved })
+}
+
+func TestTmuxOutput(t *testing.T) {
+	fake := &fakeRunner{output: map[string]string{
+		"tmux -u display-message -p #{session_path}": "/src/api\n",
+	}}
+	useRunner(t, fake)
+	t.Setenv("TMUXER_TMUX_SOCKET", "")
+
+	got, err := tmuxOutput("display-message", "-p", "#{session_path}")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got != "/src/api" {
+		t.Errorf("tmuxOutput() = %q, want /src/api", got)
+	}
+}
+
+func TestReadOnly(t *testing.T) {
+	tests := []struct {
+		args []string
+		want bool
+	}{
+		{[]string{"tmux", "-u", "has-session", "-t", "=api"}, true},
+		{[]string{"tmux", "-L", "work", "-u", "list-sessions", "-F", "#S"}, true},
+		{[]string{"tmux", "-S", "/tmp/tmux.sock", "-V"}, true},
+		{[]string{"tmux", "-u", "display-message", "-p", "#S"}, true},
+		{[]string{"tmux", "display-message", "saved"}, false},
+		{[]string{"tmux", "new-session", "-d", "-s", "api"}, false},
+		{[]string{"tmux", "-L", "list-sessions", "kill-server"}, false},
+		{[]string{"wsl.exe", "--exec", "tmux", "-u", "list-windows"}, true},
+		{[]string{"git", "-C", "/src/api", "status", "--porcelain=v1"}, true},
+		{[]string{"git", "-C", "/src/api", "worktree", "add", "../api-fix", "fix"}, false},
+		{[]string{"git", "-C", "/src/api", "worktree", "list", "--porcelain"}, true},
+		{[]string{"git", "-C", "/src/api", "remote", "get-url", "origin"}, true},
+		{[]string{"git", "-C", "/src/api", "remote", "set-url", "origin", "git@github.com:k1ng440/api"}, false},
+		{[]string{"git", "clone", "--", "https://github.com/k1ng440/tmuxer", "/src/tmuxer"}, false},
+		{[]string{"wezterm", "cli", "list", "--format", "json"}, true},
+		{[]string{"wezterm", "cli", "spawn", "--new-window"}, false},
+		{[]string{"kitty", "@", "ls"}, true},
+		{[]string{"kitty", "@", "send-text", "--match", "id:1", "ls"}, false},
+		{[]string{"zellij", "list-sessions", "--short"}, true},
+		{[]string{"zellij", "--session", "api", "action", "write", "13"}, false},
+		{[]string{"zoxide", "query", "--list"}, true},
+		{[]string{"git", "config", "--global", "user.name", "api"}, false},
+		{[]string{"sh", "-c", "make setup", "sh"}, false},
+		{nil, false},
+	}
+	for _, tt := range tests {
+		if got := readOnly(tt.args); got != tt.want {
+			t.Errorf("readOnly(%q) = %v, want %v", tt.args, got, tt.want)
+		}
+	}
+}
+
+func TestDryRunner(t *testing.T) {
+	fake := &fakeRunner{output: map[string]string{
+		"tmux -u list-sessions -F #S": "api\n",
+	}}
+	var printed bytes.Buffer
+	useRunner(t, dryRunner{out: &printed, next: fake})
+
+	output, err := commandOutput(exec.Command("tmux", "-u", "list-sessions", "-F", "#S"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(output) != "api\n" {
+		t.Errorf("query output = %q, want it from the runner", output)
+	}
+
+	if err := runCommand(exec.Command("tmux", "new-session", "-d", "-s", "api", "-c", "/src/my api")); err != nil {
+		t.Fatal(err)
+	}
+	hook := exec.Command("sh", "-c", "make setup", "sh")
+	hook.Dir = "/src/api"
+	if err := runCommand(hook); err != nil {
+		t.Fatal(err)
+	}
+
+	if want := []string{"tmux -u list-sessions -F #S"}; !reflect.DeepEqual(fake.ran, want) {
+		t.Errorf("ran %q, want only the query %q", fake.ran, want)
+	}
+	want := "tmux new-session -d -s api -c '/src/my api'\n" +
+		"(cd /src/api && sh -c 'make setup' sh)\n"
+	if printed.String() != want {
+		t.Errorf("printed %q, want %q", printed.String(), want)
+	}
+}
+
+func TestDiscoverZoxide(t *testing.T) {
+	dir := t.TempDir()
+	fake := &fakeRunner{output: map[string]string{
+		"zoxide query --list": dir + "\n" + filepath.Join(dir, "missing") + "\n",
+	}}
+	useRunner(t, fake)
+
+	projects, err := discoverZoxide(nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(projects) != 1 || projects[0].FullPath != dir {
+		t.Errorf("discoverZoxide() = %v, want only %s", projects, dir)
+	}
+	if want := []string{"zoxide query --list"}; !reflect.DeepEqual(fake.ran, want) {
+		t.Errorf("ran %q, want %q", fake.ran, want)
+	}
+}
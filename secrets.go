@@ -0,0 +1,185 @@
+package main
+
+import (
+	"fmt"
+	"log/slog"
+	"os"
+	"os/exec"
+	"strings"
+	"sync"
+)
+
+// Secrets configures how env values starting with secret: are resolved
+// when a session is created, so the config never holds them in plain
+// text. Only the user's own config may use them, not project config that
+// comes with a repository.
+type Secrets struct {
+	// Command resolves references without one of the schemes of
+	// resolveSecret, given the reference as its last argument, e.g.
+	// "pass show".
+	Command string `yaml:"command"`
+	// AgeIdentity is the identity file age: references are decrypted
+	// with.
+	AgeIdentity string `yaml:"age_identity"`
+}
+
+const secretPrefix = "secret:"
+
+var (
+	secretsMu sync.Mutex
+	// secretValues are the secrets resolved so far by reference, they are
+	// resolved once per run and redacted from logs.
+	secretValues = make(map[string]string)
+)
+
+// resolveSecret returns the secret ref refers to:
+//
+//	op://vault/item/field  read with the 1Password CLI
+//	pass:name              the first line of a pass entry
+//	gopass:name            the password of a gopass entry
+//	age:file or armor      a file or inline armored text decrypted with age
+//	anything else          the output of secrets.command given ref
+func (cfg *Config) resolveSecret(ref string) (string, error) {
+	secretsMu.Lock()
+	defer secretsMu.Unlock()
+	if v, ok := secretValues[ref]; ok {
+		return v, nil
+	}
+
+	var (
+		cmd   *exec.Cmd
+		first bool
+	)
+	switch {
+	case strings.HasPrefix(ref, "op://"):
+		cmd = exec.Command("op", "read", "--no-newline", ref)
+	case strings.HasPrefix(ref, "pass:"):
+		cmd = exec.Command("pass", "show", strings.TrimPrefix(ref, "pass:"))
+		first = true
+	case strings.HasPrefix(ref, "gopass:"):
+		cmd = exec.Command("gopass", "show", "--password", strings.TrimPrefix(ref, "gopass:"))
+	case strings.HasPrefix(ref, "age:"):
+		if cfg.Secrets.AgeIdentity == "" {
+			return "", fmt.Errorf("secret %q: secrets.age_identity is not set", ref)
+		}
+		identity, err := normalizePath(cfg.Secrets.AgeIdentity)
+		if err != nil {
+			return "", err
+		}
+		data := strings.TrimPrefix(ref, "age:")
+		if strings.HasPrefix(data, "-----BEGIN AGE ENCRYPTED FILE-----") {
+			cmd = exec.Command("age", "--decrypt", "-i", identity)
+			cmd.Stdin = strings.NewReader(data)
+		} else {
+			file, err := normalizePath(data)
+			if err != nil {
+				return "", err
+			}
+			cmd = exec.Command("age", "--decrypt", "-i", identity, file)
+		}
+	case cfg.Secrets.Command != "":
+		cmd = shellCommand(cfg.Secrets.Command, ref)
+	default:
+		return "", fmt.Errorf("secret %q: no secrets.command to resolve it", ref)
+	}
+
+	slog.Debug("resolving secret", "ref", ref, "command", cmd.Args[0])
+	output, err := commandOutput(cmd)
+	if err != nil {
+		if exit, ok := err.(*exec.ExitError); ok && len(exit.Stderr) > 0 {
+			return "", fmt.Errorf("secret %q: %s", ref, strings.TrimSpace(string(exit.Stderr)))
+		}
+		return "", fmt.Errorf("secret %q: %w", ref, err)
+	}
+	value := strings.TrimRight(string(output), "\n")
+	if first {
+		value, _, _ = strings.Cut(value, "\n")
+	}
+	secretValues[ref] = value
+	return value, nil
+}
+
+// isSecret reports whether the NAME=value pair kv holds a resolved secret.
+func isSecret(kv string) bool {
+	_, value, _ := strings.Cut(kv, "=")
+	secretsMu.Lock()
+	defer secretsMu.Unlock()
+	for _, v := range secretValues {
+		if v != "" && v == value {
+			return true
+		}
+	}
+	return false
+}
+
+// splitSecrets separates the NAME=value pairs of env holding secrets from
+// the others.
+func splitSecrets(env []string) (public, secret []string) {
+	for _, kv := range env {
+		if isSecret(kv) {
+			secret = append(secret, kv)
+		} else {
+			public = append(public, kv)
+		}
+	}
+	return public, secret
+}
+
+// writeSecretFile writes text to a new temporary file only the user can
+// read and returns its path. Secrets are handed to tmux and shells through
+// such files rather than as arguments, which ps shows to everyone.
+func writeSecretFile(text string) (string, error) {
+	f, err := os.CreateTemp("", "tmuxer-env-*")
+	if err != nil {
+		return "", err
+	}
+	if _, err := f.WriteString(text); err != nil {
+		f.Close()
+		os.Remove(f.Name())
+		return "", err
+	}
+	if err := f.Close(); err != nil {
+		os.Remove(f.Name())
+		return "", err
+	}
+	return f.Name(), nil
+}
+
+// secretCommand returns the arguments running command, or the login shell
+// without one, with the NAME=value pairs of secret exported from a
+// temporary file the shell removes once read.
+func secretCommand(secret, command []string) ([]string, error) {
+	var b strings.Builder
+	for _, kv := range secret {
+		name, value, _ := strings.Cut(kv, "=")
+		fmt.Fprintf(&b, "export %s=%s\n", name, shellQuote(value))
+	}
+	file, err := writeSecretFile(b.String())
+	if err != nil {
+		return nil, fmt.Errorf("failed to pass secrets: %w", err)
+	}
+	args := []string{"sh", "-c", `. "$0" && rm -f "$0" && exec "$@"`, file}
+	if len(command) > 0 {
+		return append(args, "sh", "-c", command[0]), nil
+	}
+	return append(args, loginShell()), nil
+}
+
+// redact returns args with the secrets resolved so far masked, for logging.
+func redact(args []string) []string {
+	secretsMu.Lock()
+	defer secretsMu.Unlock()
+	if len(secretValues) == 0 {
+		return args
+	}
+	redacted := make([]string, len(args))
+	for i, arg := range args {
+		for _, v := range secretValues {
+			if v != "" {
+				arg = strings.ReplaceAll(arg, v, "***")
+			}
+		}
+		redacted[i] = arg
+	}
+	return redacted
+}
@@ -0,0 +1,116 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+)
+
+// sessionInfo is what the preview shows about a running session.
+type sessionInfo struct {
+	Created time.Time
+	Clients int
+	Windows []*sessionWindow
+}
+
+type sessionWindow struct {
+	Index  int
+	Name   string
+	Active bool
+	// Commands are the commands running in the panes of the window.
+	Commands []string
+}
+
+func (w *sessionWindow) String() string {
+	name := w.Name
+	if w.Active {
+		name += "*"
+	}
+	return fmt.Sprintf("%s (%s)", name, strings.Join(w.Commands, ", "))
+}
+
+// sessionInfoTTL is how long the info of a session is reused, so the tmux
+// queries run at most once per keystroke rather than on every redraw.
+const sessionInfoTTL = time.Second
+
+var (
+	sessionInfoMu    sync.Mutex
+	sessionInfoCache = make(map[string]*cachedSessionInfo)
+)
+
+type cachedSessionInfo struct {
+	info    *sessionInfo
+	queried time.Time
+}
+
+// currentSessionInfo returns the info of session, querying tmux unless it
+// was queried within sessionInfoTTL. It returns nil when the session cannot
+// be queried, for example because it was killed meanwhile.
+func currentSessionInfo(session string) *sessionInfo {
+	sessionInfoMu.Lock()
+	defer sessionInfoMu.Unlock()
+
+	if cached := sessionInfoCache[session]; cached != nil && time.Since(cached.queried) < sessionInfoTTL {
+		return cached.info
+	}
+	info, err := querySessionInfo(session)
+	if err != nil {
+		info = nil
+	}
+	sessionInfoCache[session] = &cachedSessionInfo{info: info, queried: time.Now()}
+	return info
+}
+
+// querySessionInfo asks tmux for the uptime, clients, windows and pane
+// commands of session.
+func querySessionInfo(session string) (*sessionInfo, error) {
+	sessions, err := tmuxClient.ListSessions()
+	if err != nil {
+		return nil, err
+	}
+	info := &sessionInfo{}
+	found := false
+	for _, s := range sessions {
+		if s.Name == session {
+			info.Created, info.Clients, found = s.Created, s.Clients, true
+		}
+	}
+	if !found {
+		return nil, fmt.Errorf("no session %s", session)
+	}
+
+	windows, err := tmuxClient.ListWindows(session)
+	if err != nil {
+		return nil, err
+	}
+	byIndex := make(map[int]*sessionWindow)
+	for _, w := range windows {
+		sw := &sessionWindow{Index: w.Index, Name: w.Name, Active: w.Active}
+		info.Windows = append(info.Windows, sw)
+		byIndex[w.Index] = sw
+	}
+
+	panes, err := tmuxClient.ListPanes(session)
+	if err != nil {
+		return nil, err
+	}
+	for _, p := range panes {
+		if w := byIndex[p.WindowIndex]; w != nil {
+			w.Commands = append(w.Commands, p.Command)
+		}
+	}
+	return info, nil
+}
+
+// shortDuration formats d as days and hours, hours and minutes or minutes.
+func shortDuration(d time.Duration) string {
+	switch {
+	case d >= 24*time.Hour:
+		return fmt.Sprintf("%dd %dh", d/(24*time.Hour), d%(24*time.Hour)/time.Hour)
+	case d >= time.Hour:
+		return fmt.Sprintf("%dh %dm", d/time.Hour, d%time.Hour/time.Minute)
+	default:
+		return fmt.Sprintf("%dm", d/time.Minute)
+	}
+}
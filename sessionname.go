@@ -0,0 +1,70 @@
+package main
+
+import (
+	"fmt"
+	"log/slog"
+	"path"
+	"path/filepath"
+	"strings"
+	"text/template"
+)
+
+// sessionNameData is available to the session_name_template.
+type sessionNameData struct {
+	Name string
+	// Parent is the name of the directory the project is in.
+	Parent string
+	// Type is the first detected type of the project, such as go, or
+	// empty when none was detected.
+	Type string
+	// Prefix is the prefix of the project's base.
+	Prefix string
+}
+
+func newSessionNameData(project *Project) sessionNameData {
+	data := sessionNameData{Name: project.Name, Prefix: project.basePrefix()}
+	if project.FullPath != "" {
+		data.Parent = path.Base(path.Dir(filepath.ToSlash(project.FullPath)))
+		if types := detectTypes(project); len(types) > 0 {
+			data.Type = types[0]
+		}
+	}
+	return data
+}
+
+// parseSessionNameTemplate parses the session_name_template of the config.
+func parseSessionNameTemplate(text string) (*template.Template, error) {
+	tmpl, err := template.New("session").Option("missingkey=error").Parse(text)
+	if err != nil {
+		return nil, fmt.Errorf("invalid session name template %q: %w", text, err)
+	}
+	if err := tmpl.Execute(&strings.Builder{}, sessionNameData{}); err != nil {
+		return nil, fmt.Errorf("invalid session name template %q: %w", text, err)
+	}
+	return tmpl, nil
+}
+
+// baseSessionName is the session name of project before session_prefix and
+// the variant are added: the project name behind the prefix of its base, or
+// the session_name_template rendered for it.
+func (cfg *Config) baseSessionName(project *Project) string {
+	if cfg.SessionNameTemplate == "" {
+		return project.basePrefix() + project.Name
+	}
+	if cfg.sessionNameTemplate == nil {
+		tmpl, err := parseSessionNameTemplate(cfg.SessionNameTemplate)
+		if err != nil {
+			slog.Warn("ignoring session name template", "err", err)
+			cfg.SessionNameTemplate = ""
+			return project.basePrefix() + project.Name
+		}
+		cfg.sessionNameTemplate = tmpl
+	}
+
+	var b strings.Builder
+	if err := cfg.sessionNameTemplate.Execute(&b, newSessionNameData(project)); err != nil || b.Len() == 0 {
+		slog.Debug("failed to render session name template", "project", project.Name, "err", err)
+		return project.basePrefix() + project.Name
+	}
+	return b.String()
+}
@@ -0,0 +1,112 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"sort"
+)
+
+const sessionsFile = "sessions.json"
+
+func sessionsPath() (string, error) {
+	dir, err := dataDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, sessionsFile), nil
+}
+
+// loadSessionPaths returns the project directories recorded for sessions
+// by name.
+func loadSessionPaths() (map[string]string, error) {
+	p, err := sessionsPath()
+	if err != nil {
+		return nil, err
+	}
+	data, err := os.ReadFile(p)
+	if errors.Is(err, fs.ErrNotExist) {
+		return map[string]string{}, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	paths := make(map[string]string)
+	if err := json.Unmarshal(data, &paths); err != nil {
+		return nil, fmt.Errorf("corrupt sessions file %s: %w", p, err)
+	}
+	return paths, nil
+}
+
+func saveSessionPaths(paths map[string]string) error {
+	p, err := sessionsPath()
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(p), 0o755); err != nil {
+		return err
+	}
+	data, err := json.MarshalIndent(paths, "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(p, data, 0o644)
+}
+
+// recordSession records the directory of the session of a local project in
+// the sessions file, which outlives the tmux server unlike the
+// projectOption of the session. Sessions of directories that no longer
+// exist are dropped from it.
+func recordSession(project *Project) error {
+	if project.Remote != nil || *dryRun {
+		return nil
+	}
+	paths, err := loadSessionPaths()
+	if err != nil {
+		return err
+	}
+	for session, path := range paths {
+		if _, err := os.Stat(path); err != nil {
+			delete(paths, session)
+		}
+	}
+	paths[project.Session] = project.FullPath
+	return saveSessionPaths(paths)
+}
+
+// mappedSession returns the running session showing the directory of
+// project, which is not named after the project when it was renamed, and
+// the usual session name of project otherwise.
+func mappedSession(project *Project) string {
+	sessions, err := listSessions()
+	if err != nil {
+		slog.Debug("failed to list sessions", "err", err)
+		return project.Session
+	}
+	if s := sessions[project.Session]; s != nil && s.Path == project.FullPath {
+		return project.Session
+	}
+
+	names := make([]string, 0, len(sessions))
+	for name := range sessions {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		if sessions[name].Path == project.FullPath {
+			slog.Debug("found renamed session of project", "project", project.Name, "session", name)
+			renamed := *project
+			renamed.Session = name
+			if err := recordSession(&renamed); err != nil {
+				slog.Warn("failed to record session", "err", err)
+			}
+			return name
+		}
+	}
+	return project.Session
+}
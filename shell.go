@@ -0,0 +1,8 @@
+package main
+
+import "os/exec"
+
+// shellCommand is shellCommandContext stopped by interrupts, see appContext.
+func shellCommand(command string, args ...string) *exec.Cmd {
+	return shellCommandContext(appContext, command, args...)
+}
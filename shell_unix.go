@@ -0,0 +1,21 @@
+//go:build !windows
+
+package main
+
+import (
+	"context"
+	"os/exec"
+)
+
+// shellCommandContext runs command, such as a hook or the editor, with sh,
+// passing args to it as additional arguments.
+func shellCommandContext(ctx context.Context, command string, args ...string) *exec.Cmd {
+	if len(args) > 0 {
+		command += ` "$@"`
+	}
+	return exec.CommandContext(ctx, "sh", append([]string{"-c", command, "sh"}, args...)...)
+}
+
+func hasSh() bool {
+	return true
+}
@@ -0,0 +1,37 @@
+//go:build windows
+
+package main
+
+import (
+	"context"
+	"os/exec"
+	"strings"
+	"sync"
+	"syscall"
+)
+
+// hasSh reports whether sh, as installed by MSYS2, Git Bash or Cygwin, is
+// in the PATH.
+var hasSh = sync.OnceValue(func() bool {
+	_, err := exec.LookPath("sh")
+	return err == nil
+})
+
+// shellCommandContext runs command with sh when there is one and with cmd
+// otherwise, passing args to it as additional quoted arguments.
+func shellCommandContext(ctx context.Context, command string, args ...string) *exec.Cmd {
+	if hasSh() {
+		if len(args) > 0 {
+			command += ` "$@"`
+		}
+		return exec.CommandContext(ctx, "sh", append([]string{"-c", command, "sh"}, args...)...)
+	}
+
+	for _, arg := range args {
+		command += ` "` + strings.ReplaceAll(arg, `"`, `""`) + `"`
+	}
+	cmd := exec.CommandContext(ctx, "cmd")
+	// cmd does not follow the quoting rules Go uses to join arguments
+	cmd.SysProcAttr = &syscall.SysProcAttr{CmdLine: `cmd /S /C "` + command + `"`}
+	return cmd
+}
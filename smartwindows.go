@@ -0,0 +1,111 @@
+package main
+
+import (
+	"bufio"
+	"encoding/json"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"regexp"
+)
+
+// runTargets are the make targets and package.json scripts considered for
+// the run window, in order of preference.
+var runTargets = []string{"dev", "run", "start", "serve"}
+
+var makeTargetRegex = regexp.MustCompile(`^([A-Za-z0-9_.-]+):`)
+
+// smartLayout builds a layout from what is found in the project directory:
+// an editor window, a git window for repositories and a run window when a
+// run command can be inferred.
+func smartLayout(project *Project) *Layout {
+	layout := &Layout{}
+
+	layout.Windows = append(layout.Windows, Window{Editor: true})
+
+	if exists(filepath.Join(project.FullPath, ".git")) {
+		command := "git status"
+		if _, err := exec.LookPath("lazygit"); err == nil {
+			command = "lazygit"
+		}
+		layout.Windows = append(layout.Windows, Window{Name: "git", Command: command})
+	}
+
+	if command := inferRunCommand(project.FullPath); command != "" {
+		layout.Windows = append(layout.Windows, Window{Name: "run", Command: command})
+	}
+
+	return layout
+}
+
+// inferRunCommand guesses how the project is started from its Makefile,
+// package.json, Cargo.toml or go.mod.
+func inferRunCommand(dir string) string {
+	if targets := makeTargets(filepath.Join(dir, "Makefile")); len(targets) > 0 {
+		for _, t := range runTargets {
+			if targets[t] {
+				return "make " + t
+			}
+		}
+	}
+
+	if scripts := packageScripts(filepath.Join(dir, "package.json")); len(scripts) > 0 {
+		runner := "npm run"
+		switch {
+		case exists(filepath.Join(dir, "pnpm-lock.yaml")):
+			runner = "pnpm run"
+		case exists(filepath.Join(dir, "yarn.lock")):
+			runner = "yarn"
+		}
+		for _, t := range runTargets {
+			if _, ok := scripts[t]; ok {
+				return runner + " " + t
+			}
+		}
+	}
+
+	if exists(filepath.Join(dir, "Cargo.toml")) {
+		return "cargo run"
+	}
+	if exists(filepath.Join(dir, "go.mod")) && exists(filepath.Join(dir, "main.go")) {
+		return "go run ."
+	}
+	return ""
+}
+
+func makeTargets(makefile string) map[string]bool {
+	file, err := os.Open(makefile)
+	if err != nil {
+		return nil
+	}
+	defer file.Close()
+
+	targets := make(map[string]bool)
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		if m := makeTargetRegex.FindStringSubmatch(scanner.Text()); m != nil {
+			targets[m[1]] = true
+		}
+	}
+	return targets
+}
+
+func packageScripts(packageJSON string) map[string]string {
+	data, err := os.ReadFile(packageJSON)
+	if err != nil {
+		return nil
+	}
+
+	var pkg struct {
+		Scripts map[string]string `json:"scripts"`
+	}
+	if err := json.Unmarshal(data, &pkg); err != nil {
+		return nil
+	}
+	return pkg.Scripts
+}
+
+func exists(p string) bool {
+	_, err := os.Stat(p)
+	return err == nil
+}
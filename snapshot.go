@@ -0,0 +1,101 @@
+package main
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Snapshot is a session saved by tmuxer snapshot. Its windows are a
+// layout, so they can also be copied under layouts in the config.
+type Snapshot struct {
+	Session string `yaml:"session"`
+	// Root is the start directory of the session, the windows' directories
+	// are relative to it.
+	Root   string `yaml:"root"`
+	Layout `yaml:",inline"`
+}
+
+// runSnapshotCommand writes the windows, panes, directories and running
+// commands of a session to a file, or stdout.
+func runSnapshotCommand(_ *Config, args []string) error {
+	if len(args) < 1 || len(args) > 2 {
+		return errors.New("usage: tmuxer snapshot <session> [file]")
+	}
+	session := args[0]
+
+	layout, err := snapshotLayout(session)
+	if err != nil {
+		return err
+	}
+	root, err := tmuxOutput("display-message", "-p", "-t", session+":", "#{session_path}")
+	if err != nil {
+		return err
+	}
+	var b bytes.Buffer
+	enc := yaml.NewEncoder(&b)
+	enc.SetIndent(2)
+	if err := enc.Encode(&Snapshot{Session: session, Root: root, Layout: *layout}); err != nil {
+		return err
+	}
+
+	if len(args) == 1 {
+		_, err := os.Stdout.Write(b.Bytes())
+		return err
+	}
+	if err := os.WriteFile(args[1], b.Bytes(), 0o644); err != nil {
+		return err
+	}
+	fmt.Printf("Saved session %s with %d windows to %s\n", session, len(layout.Windows), args[1])
+	return nil
+}
+
+// runRestoreCommand recreates a session from a snapshot, under another name
+// when given, and attaches to it like opening a project.
+func runRestoreCommand(cfg *Config, args []string) error {
+	if len(args) < 1 || len(args) > 2 {
+		return errors.New("usage: tmuxer restore <file> [session]")
+	}
+
+	data, err := os.ReadFile(args[0])
+	if err != nil {
+		return err
+	}
+	var snapshot Snapshot
+	if err := yaml.Unmarshal(data, &snapshot); err != nil {
+		return fmt.Errorf("failed to read snapshot %s: %w", args[0], err)
+	}
+	if len(args) == 2 {
+		snapshot.Session = args[1]
+	}
+	if snapshot.Session == "" || snapshot.Root == "" {
+		return fmt.Errorf("snapshot %s has no session or root", args[0])
+	}
+
+	exists, err := tmuxClient.HasSession(snapshot.Session)
+	if err != nil {
+		return err
+	}
+	if exists {
+		return fmt.Errorf("session %s already exists, give another name to restore it as", snapshot.Session)
+	}
+
+	project, err := newProject(filepath.Base(snapshot.Root), snapshot.Root)
+	if err != nil {
+		return err
+	}
+	project.Session = snapshot.Session
+
+	// the snapshot wins over the layouts picked by project type
+	cfg.TypeLayouts = nil
+	if cfg.Layouts == nil {
+		cfg.Layouts = make(map[string]*Layout)
+	}
+	cfg.Layouts[snapshot.Session] = &snapshot.Layout
+	cfg.Layout = snapshot.Session
+	return runActions(cfg, project)
+}
@@ -0,0 +1,24 @@
+package main
+
+import (
+	"os"
+	"strings"
+)
+
+// tmuxSocketArgs returns the tmux options selecting the server given with
+// --socket or TMUXER_TMUX_SOCKET: -L for a socket name, -S for a path. They
+// are empty for the default server, or the one of $TMUX inside tmux.
+func tmuxSocketArgs() []string {
+	socket := *tmuxSocket
+	if socket == "" {
+		socket = os.Getenv("TMUXER_TMUX_SOCKET")
+	}
+	switch {
+	case socket == "":
+		return nil
+	case strings.ContainsAny(socket, `/\`):
+		return []string{"-S", tmuxPath(socket)}
+	default:
+		return []string{"-L", socket}
+	}
+}
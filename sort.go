@@ -0,0 +1,134 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+	"time"
+)
+
+const defaultSort = "name-asc"
+
+// sortStrategies order the discovered projects. The picker shows the first
+// project closest to the prompt.
+var sortStrategies = map[string]func(projects []*Project) error{
+	"name-asc": func(projects []*Project) error {
+		sort.SliceStable(projects, func(i, j int) bool {
+			return lessName(projects[i], projects[j])
+		})
+		return nil
+	},
+	"name-desc": func(projects []*Project) error {
+		sort.SliceStable(projects, func(i, j int) bool {
+			an, bn := strings.ToLower(projects[i].Name), strings.ToLower(projects[j].Name)
+			if an != bn {
+				return an > bn
+			}
+			return lessName(projects[i], projects[j])
+		})
+		return nil
+	},
+	"path": func(projects []*Project) error {
+		sort.SliceStable(projects, func(i, j int) bool {
+			return projects[i].FullPath < projects[j].FullPath
+		})
+		return nil
+	},
+	"mtime":    sortByMtime,
+	"frecency": sortByFrecency,
+}
+
+// lessName orders projects by name. Projects of the same name are ordered
+// by the weight of their base, heaviest first, and then by path.
+func lessName(a, b *Project) bool {
+	an, bn := strings.ToLower(a.Name), strings.ToLower(b.Name)
+	if an != bn {
+		return an < bn
+	}
+	if a.baseWeight() != b.baseWeight() {
+		return a.baseWeight() > b.baseWeight()
+	}
+	return a.FullPath < b.FullPath
+}
+
+// sortProjects orders projects by the strategy selected with --sort or the
+// sort setting, after the pinned projects.
+func (cfg *Config) sortProjects(projects []*Project) error {
+	name := cfg.Sort
+	if name == "" {
+		name = defaultSort
+	}
+	fn, ok := sortStrategies[name]
+	if !ok {
+		return fmt.Errorf("unknown sort %q, expected one of: %s", name, strings.Join(sortedKeys(sortStrategies), ", "))
+	}
+
+	// ties, such as projects without history, stay in name order
+	sort.SliceStable(projects, func(i, j int) bool {
+		return lessName(projects[i], projects[j])
+	})
+	if err := fn(projects); err != nil {
+		return err
+	}
+	pinnedFirst(projects)
+	return nil
+}
+
+// sortByMtime lists the most recently modified project directories first.
+func sortByMtime(projects []*Project) error {
+	mtimes := make(map[*Project]time.Time, len(projects))
+	for _, p := range projects {
+		if p.Remote != nil {
+			continue
+		}
+		if info, err := os.Stat(p.FullPath); err == nil {
+			mtimes[p] = info.ModTime()
+		}
+	}
+	sort.SliceStable(projects, func(i, j int) bool {
+		return mtimes[projects[i]].After(mtimes[projects[j]])
+	})
+	return nil
+}
+
+// sortByFrecency lists the projects opened most often and most recently
+// first, weighing each open from the history by its age. The weight of the
+// base of a project is added to its score, so each point counts as much as
+// an open within the last week.
+func sortByFrecency(projects []*Project) error {
+	entries, err := readHistory()
+	if err != nil {
+		return err
+	}
+
+	scores := make(map[string]float64)
+	now := time.Now()
+	for _, entry := range entries {
+		if entry.Event != historyEventOpen {
+			continue
+		}
+		scores[entry.Path] += frecencyWeight(now.Sub(entry.Time))
+	}
+
+	score := func(p *Project) float64 {
+		return scores[p.FullPath] + float64(p.baseWeight())
+	}
+	sort.SliceStable(projects, func(i, j int) bool {
+		return score(projects[i]) > score(projects[j])
+	})
+	return nil
+}
+
+func frecencyWeight(age time.Duration) float64 {
+	switch {
+	case age < time.Hour:
+		return 4
+	case age < 24*time.Hour:
+		return 2
+	case age < 7*24*time.Hour:
+		return 1
+	default:
+		return 0.25
+	}
+}
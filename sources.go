@@ -0,0 +1,297 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+const (
+	sourcesFile = "sources.json"
+	// sourcesTTL is how long repository listings are reused before asking
+	// the API again.
+	sourcesTTL = time.Hour
+	// sourcesPerPage is the page size requested from the APIs.
+	sourcesPerPage = 100
+)
+
+// Source lists the repositories of a GitHub or GitLab organization, group
+// or user. They are shown in the picker and cloned when selected.
+type Source struct {
+	// Type is github or gitlab.
+	Type string `yaml:"type"`
+	// Owner is the organization, group or user.
+	Owner string `yaml:"owner"`
+	// URL is the address of a self-hosted GitHub Enterprise or GitLab.
+	URL string `yaml:"url"`
+	// TokenEnv names the environment variable holding the API token,
+	// GITHUB_TOKEN or GITLAB_TOKEN by default.
+	TokenEnv string `yaml:"token_env"`
+	// SSH clones over ssh instead of https.
+	SSH bool `yaml:"ssh"`
+}
+
+func (s *Source) String() string {
+	return s.Type + ":" + s.Owner
+}
+
+// cacheKey identifies the listing of the source in the cache.
+func (s *Source) cacheKey() string {
+	return fmt.Sprintf("%s %s %s ssh=%t", s.Type, s.URL, s.Owner, s.SSH)
+}
+
+func (s *Source) token() string {
+	name := s.TokenEnv
+	if name == "" {
+		name = strings.ToUpper(s.Type) + "_TOKEN"
+	}
+	return os.Getenv(name)
+}
+
+// sourceRepo is a repository listed by a source.
+type sourceRepo struct {
+	Name string `json:"name"`
+	URL  string `json:"url"`
+}
+
+type sourceListing struct {
+	Repos     []sourceRepo `json:"repos"`
+	UpdatedAt time.Time    `json:"updated_at"`
+}
+
+var sourceClient = &http.Client{Timeout: 10 * time.Second}
+
+// sourceProjects returns the repositories of all sources as uncloned
+// projects, leaving out those with a checkout among discovered. Listings
+// are cached; a source that fails is skipped with a warning.
+func (cfg *Config) sourceProjects(discovered map[string]*Project) []*Project {
+	if len(cfg.Sources) == 0 {
+		return nil
+	}
+
+	cloned := clonedNames(discovered)
+	cache := loadSourceCache()
+	changed := false
+
+	var projects []*Project
+	for _, source := range cfg.Sources {
+		key := source.cacheKey()
+		listing, ok := cache[key]
+		if !ok || time.Since(listing.UpdatedAt) > sourcesTTL {
+			repos, err := listSourceRepos(source)
+			if err != nil {
+				slog.Warn("failed to list repositories", "source", source, "err", err)
+				if !ok {
+					continue
+				}
+			} else {
+				listing = &sourceListing{Repos: repos, UpdatedAt: time.Now()}
+				cache[key] = listing
+				changed = true
+			}
+		}
+
+		for _, repo := range listing.Repos {
+			if cloned[repo.Name] {
+				continue
+			}
+			projects = append(projects, &Project{
+				Name:     repo.Name,
+				FullPath: repo.URL,
+				HomePath: repo.URL,
+				Repo:     repo.URL,
+				Markers:  []string{source.Type},
+			})
+		}
+	}
+
+	if changed {
+		if err := saveSourceCache(cache); err != nil {
+			slog.Warn("failed to save repository listings", "err", err)
+		}
+	}
+	return projects
+}
+
+func listSourceRepos(source *Source) ([]sourceRepo, error) {
+	switch source.Type {
+	case "github":
+		return listGitHubRepos(source)
+	case "gitlab":
+		return listGitLabRepos(source)
+	default:
+		return nil, fmt.Errorf("unknown source type %q", source.Type)
+	}
+}
+
+func listGitHubRepos(source *Source) ([]sourceRepo, error) {
+	api := "https://api.github.com"
+	if source.URL != "" {
+		api = strings.TrimRight(source.URL, "/") + "/api/v3"
+	}
+
+	var repos []sourceRepo
+	// organizations and users have different endpoints
+	for _, kind := range []string{"orgs", "users"} {
+		for page := 1; ; page++ {
+			var items []struct {
+				Name     string `json:"name"`
+				CloneURL string `json:"clone_url"`
+				SSHURL   string `json:"ssh_url"`
+				Archived bool   `json:"archived"`
+			}
+			u := fmt.Sprintf("%s/%s/%s/repos?per_page=%d&page=%d", api, kind, url.PathEscape(source.Owner), sourcesPerPage, page)
+			err := getJSON(u, map[string]string{"Authorization": bearer(source.token())}, &items)
+			if errors.Is(err, errNotFound) && kind == "orgs" {
+				break
+			}
+			if err != nil {
+				return nil, err
+			}
+
+			for _, item := range items {
+				if item.Archived {
+					continue
+				}
+				repo := sourceRepo{Name: item.Name, URL: item.CloneURL}
+				if source.SSH {
+					repo.URL = item.SSHURL
+				}
+				repos = append(repos, repo)
+			}
+			if len(items) < sourcesPerPage {
+				return repos, nil
+			}
+		}
+	}
+	return nil, fmt.Errorf("no github organization or user %q", source.Owner)
+}
+
+func listGitLabRepos(source *Source) ([]sourceRepo, error) {
+	api := "https://gitlab.com"
+	if source.URL != "" {
+		api = strings.TrimRight(source.URL, "/")
+	}
+
+	var repos []sourceRepo
+	for _, kind := range []string{"groups", "users"} {
+		for page := 1; ; page++ {
+			var items []struct {
+				Path     string `json:"path"`
+				HTTPURL  string `json:"http_url_to_repo"`
+				SSHURL   string `json:"ssh_url_to_repo"`
+				Archived bool   `json:"archived"`
+			}
+			u := fmt.Sprintf("%s/api/v4/%s/%s/projects?include_subgroups=true&per_page=%d&page=%d", api, kind, url.PathEscape(source.Owner), sourcesPerPage, page)
+			headers := map[string]string{}
+			if token := source.token(); token != "" {
+				headers["PRIVATE-TOKEN"] = token
+			}
+			err := getJSON(u, headers, &items)
+			if errors.Is(err, errNotFound) && kind == "groups" {
+				break
+			}
+			if err != nil {
+				return nil, err
+			}
+
+			for _, item := range items {
+				if item.Archived {
+					continue
+				}
+				repo := sourceRepo{Name: item.Path, URL: item.HTTPURL}
+				if source.SSH {
+					repo.URL = item.SSHURL
+				}
+				repos = append(repos, repo)
+			}
+			if len(items) < sourcesPerPage {
+				return repos, nil
+			}
+		}
+	}
+	return nil, fmt.Errorf("no gitlab group or user %q", source.Owner)
+}
+
+var errNotFound = errors.New("not found")
+
+func bearer(token string) string {
+	if token == "" {
+		return ""
+	}
+	return "Bearer " + token
+}
+
+func getJSON(u string, headers map[string]string, v any) error {
+	req, err := http.NewRequest(http.MethodGet, u, nil)
+	if err != nil {
+		return err
+	}
+	for k, h := range headers {
+		if h != "" {
+			req.Header.Set(k, h)
+		}
+	}
+
+	slog.Debug("requesting", "url", u)
+	resp, err := sourceClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	switch {
+	case resp.StatusCode == http.StatusNotFound:
+		return errNotFound
+	case resp.StatusCode != http.StatusOK:
+		return fmt.Errorf("%s: %s", u, resp.Status)
+	}
+	return json.NewDecoder(resp.Body).Decode(v)
+}
+
+func sourceCachePath() (string, error) {
+	dir, err := cacheDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, sourcesFile), nil
+}
+
+func loadSourceCache() map[string]*sourceListing {
+	cache := make(map[string]*sourceListing)
+	p, err := sourceCachePath()
+	if err != nil {
+		return cache
+	}
+	data, err := os.ReadFile(p)
+	if err != nil {
+		return cache
+	}
+	if err := json.Unmarshal(data, &cache); err != nil {
+		slog.Warn("ignoring corrupt repository listings", "err", err)
+		return make(map[string]*sourceListing)
+	}
+	return cache
+}
+
+func saveSourceCache(cache map[string]*sourceListing) error {
+	p, err := sourceCachePath()
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(p), 0o755); err != nil {
+		return err
+	}
+	data, err := json.Marshal(cache)
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(p, data, 0o644)
+}
@@ -0,0 +1,71 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// parseAge parses durations such as 180d or 12w in addition to the units
+// understood by time.ParseDuration.
+func parseAge(s string) (time.Duration, error) {
+	units := map[string]time.Duration{"d": 24 * time.Hour, "w": 7 * 24 * time.Hour}
+	for suffix, unit := range units {
+		if n, ok := strings.CutSuffix(s, suffix); ok {
+			if v, err := strconv.Atoi(n); err == nil && v >= 0 {
+				return time.Duration(v) * unit, nil
+			}
+		}
+	}
+
+	d, err := time.ParseDuration(s)
+	if err != nil || d < 0 {
+		return 0, fmt.Errorf("invalid duration %q, expected e.g. 180d, 12w or 720h", s)
+	}
+	return d, nil
+}
+
+// lastActivity is the time of the last commit of project or the
+// modification time of its directory, whichever is later. Projects must
+// have been enriched before.
+func lastActivity(project *Project) time.Time {
+	var t time.Time
+	if info, err := os.Stat(project.FullPath); err == nil {
+		t = info.ModTime()
+	}
+	if project.Git != nil && project.Git.LastCommit.After(t) {
+		t = project.Git.LastCommit
+	}
+	return t
+}
+
+// splitStale separates the projects without activity for longer than
+// hide_stale. Remote, bookmarked and uncloned projects are never stale.
+func (cfg *Config) splitStale(projects []*Project) (fresh, stale []*Project, err error) {
+	if cfg.HideStale == "" || *showStale {
+		return projects, nil, nil
+	}
+	maxAge, err := parseAge(cfg.HideStale)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	enrichProjects(projects)
+	cutoff := time.Now().Add(-maxAge)
+	for _, project := range projects {
+		if project.Remote == nil && project.Repo == "" && !contains(project.Markers, bookmarkMarker) && lastActivity(project).Before(cutoff) {
+			stale = append(stale, project)
+			continue
+		}
+		fresh = append(fresh, project)
+	}
+	return fresh, stale, nil
+}
+
+// staleToggle is a picker entry listing the hidden stale projects when
+// selected.
+func staleToggle(n int) *Project {
+	return &Project{Name: fmt.Sprintf("[show %d stale projects]", n)}
+}
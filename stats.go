@@ -0,0 +1,189 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"log/slog"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+	"text/tabwriter"
+	"time"
+)
+
+const scanStatsFile = "scan.json"
+
+// BaseStats describes the scan of a single base.
+type BaseStats struct {
+	Base string `json:"base"`
+	// Visited counts the directories read, or for remote bases the entries
+	// listed.
+	Visited  int `json:"visited"`
+	Projects int `json:"projects"`
+	// Ignored counts the entries left out by ignore rules.
+	Ignored  int           `json:"ignored"`
+	Duration time.Duration `json:"duration"`
+	Errors   []string      `json:"errors,omitempty"`
+}
+
+// countingFS counts the directories read through it and hides the entries
+// matched by ignore, so the walk never descends into them. Read errors are
+// kept in errs since GlobWalk skips unreadable directories silently.
+type countingFS struct {
+	fs.FS
+	ignore  *ignoreMatcher
+	dirs    int
+	ignored int
+	errs    []error
+	// root is the directory of FS. When set, directories reached again
+	// through symlinks are not read twice, which also ends symlink loops.
+	root string
+	seen map[string]bool
+}
+
+func (c *countingFS) ReadDir(name string) ([]fs.DirEntry, error) {
+	// an interrupt ends the walk, discoverBases reports it
+	if appContext.Err() != nil {
+		return nil, nil
+	}
+	if c.root != "" {
+		real, err := filepath.EvalSymlinks(filepath.Join(c.root, name))
+		if err == nil && c.seen[real] {
+			slog.Debug("skipping directory visited through a symlink", "dir", name, "target", real)
+			return nil, nil
+		}
+		if c.seen == nil {
+			c.seen = make(map[string]bool)
+		}
+		c.seen[real] = true
+	}
+
+	c.dirs++
+	entries, err := fs.ReadDir(c.FS, name)
+	if err != nil {
+		c.errs = append(c.errs, err)
+	}
+	if c.ignore == nil {
+		return entries, err
+	}
+
+	kept := entries[:0]
+	for _, e := range entries {
+		if c.ignore.ignored(path.Join(name, e.Name()), e.IsDir()) {
+			c.ignored++
+			continue
+		}
+		kept = append(kept, e)
+	}
+	return kept, err
+}
+
+// scanReport is what the last full scan found, kept for tmuxer cache status.
+type scanReport struct {
+	Time  time.Time    `json:"time"`
+	Bases []*BaseStats `json:"bases"`
+	// Duration covers the whole scan including reading git information.
+	Duration    time.Duration `json:"duration"`
+	GitDuration time.Duration `json:"git_duration"`
+	CacheHits   int           `json:"cache_hits"`
+	CacheMisses int           `json:"cache_misses"`
+	// TotalAlloc and Sys are the bytes allocated during the run and
+	// obtained from the operating system, see runtime.MemStats.
+	TotalAlloc uint64 `json:"total_alloc"`
+	Sys        uint64 `json:"sys"`
+}
+
+func printScanStats(w io.Writer, stats []*BaseStats) error {
+	tw := tabwriter.NewWriter(w, 0, 4, 2, ' ', 0)
+	fmt.Fprintln(tw, "BASE\tVISITED\tIGNORED\tPROJECTS\tDURATION\tERRORS")
+	for _, st := range stats {
+		fmt.Fprintf(tw, "%s\t%d\t%d\t%d\t%s\t%s\n", st.Base, st.Visited, st.Ignored, st.Projects, st.Duration.Round(time.Microsecond), strings.Join(st.Errors, "; "))
+	}
+	return tw.Flush()
+}
+
+// printScanSummary prints the totals of a scan below the table of bases.
+func printScanSummary(w io.Writer, report *scanReport) {
+	rate := 0.0
+	if total := report.CacheHits + report.CacheMisses; total > 0 {
+		rate = 100 * float64(report.CacheHits) / float64(total)
+	}
+	fmt.Fprintf(w, "\nTotal duration: %s\n", report.Duration.Round(time.Microsecond))
+	fmt.Fprintf(w, "Git information: %s, %d cache hits, %d misses (%.0f%% hit rate)\n",
+		report.GitDuration.Round(time.Microsecond), report.CacheHits, report.CacheMisses, rate)
+	fmt.Fprintf(w, "Memory: %s allocated, %s from the system\n", formatBytes(report.TotalAlloc), formatBytes(report.Sys))
+}
+
+func scanReportPath() (string, error) {
+	dir, err := cacheDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, scanStatsFile), nil
+}
+
+func saveScanReport(report *scanReport) error {
+	p, err := scanReportPath()
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(p), 0o755); err != nil {
+		return err
+	}
+	data, err := json.Marshal(report)
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(p, data, 0o644)
+}
+
+func loadScanReport() (*scanReport, error) {
+	p, err := scanReportPath()
+	if err != nil {
+		return nil, err
+	}
+	data, err := os.ReadFile(p)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	report := &scanReport{}
+	return report, json.Unmarshal(data, report)
+}
+
+// runCacheCommand implements tmuxer cache status, reporting what is cached
+// and how the last scan went.
+func runCacheCommand(_ *Config, args []string) error {
+	if len(args) != 1 || args[0] != "status" {
+		return errors.New("usage: tmuxer cache status")
+	}
+
+	dir, err := cacheDir()
+	if err != nil {
+		return err
+	}
+	fmt.Printf("Cache directory: %s\n", dir)
+	fmt.Printf("Git information cached for %d projects\n", len(loadEnrichmentCache()))
+
+	report, err := loadScanReport()
+	if err != nil {
+		return err
+	}
+	if report == nil {
+		fmt.Println("No scan recorded yet, run tmuxer scan")
+		return nil
+	}
+
+	fmt.Printf("Last scan: %s\n\n", report.Time.Format(time.RFC1123))
+	if err := printScanStats(os.Stdout, report.Bases); err != nil {
+		return err
+	}
+	printScanSummary(os.Stdout, report)
+	return nil
+}
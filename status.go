@@ -0,0 +1,170 @@
+package main
+
+import (
+	"fmt"
+	"log/slog"
+
+	"github.com/gdamore/tcell/v2"
+	"github.com/k1ng440/tmuxer/third_party/go-fuzzyfinder"
+)
+
+// SessionStatus describes the tmux session of a project that has one.
+type SessionStatus struct {
+	Name     string
+	Attached bool
+	// Activity is set when a window of the session has an activity, bell or
+	// silence alert nobody has looked at yet.
+	Activity bool
+	// Path is the project directory of the session, from its projectOption
+	// or else the sessions file, see recordSession.
+	Path string
+}
+
+// listSessions returns the status of every running tmux session by name.
+func listSessions() (map[string]*SessionStatus, error) {
+	running, err := tmuxClient.ListSessions(projectOption)
+	if err != nil {
+		return nil, err
+	}
+	recorded, err := loadSessionPaths()
+	if err != nil {
+		slog.Warn("failed to load sessions", "err", err)
+	}
+
+	sessions := make(map[string]*SessionStatus)
+	for _, s := range running {
+		path := s.Options[projectOption]
+		if path == "" {
+			// the session outlived its option, e.g. when it was restored
+			// after a restart of the tmux server
+			path = recorded[s.Name]
+		}
+		sessions[s.Name] = &SessionStatus{
+			Name:     s.Name,
+			Attached: s.Attached(),
+			Activity: s.Alerted,
+			Path:     path,
+		}
+	}
+	return sessions, nil
+}
+
+// annotateSessions sets the session status of the projects with a running
+// session, querying tmux once.
+func (cfg *Config) annotateSessions(projects []*Project) {
+	annotate := cfg.sessionAnnotator()
+	for _, project := range projects {
+		annotate(project)
+	}
+}
+
+// sessionAnnotator queries tmux for the running sessions and returns a
+// function setting the session status of a project from them.
+func (cfg *Config) sessionAnnotator() func(*Project) {
+	if *noTmux {
+		return func(*Project) {}
+	}
+	sessions, err := listSessions()
+	if err != nil {
+		// usually no server is running
+		slog.Debug("failed to list sessions", "err", err)
+		return func(*Project) {}
+	}
+
+	byPath := make(map[string]*SessionStatus)
+	for _, s := range sessions {
+		if s.Path != "" && byPath[s.Path] == nil {
+			byPath[s.Path] = s
+		}
+	}
+
+	return func(project *Project) {
+		name := project.Session
+		if name == "" {
+			// a copy, the variant only names the session looked for
+			p := *project
+			p.Variant = *variant
+			name = cfg.sessionName(&p)
+		}
+		project.Tmux = sessions[name]
+		if project.Tmux == nil && project.Session == "" && *variant == "" {
+			// the session was renamed
+			project.Tmux = byPath[project.FullPath]
+		}
+	}
+}
+
+// projectLabel is the picker entry of project: its display name prefixed
+// with ◆ when its session is attached or ● when it exists, and followed by !
+// when the session has unseen activity.
+func (cfg *Config) projectLabel(project *Project) string {
+	name := cfg.displayName(project)
+	if project.Pinned {
+		name += " " + pinGlyph
+	}
+	if len(project.Clones) > 0 {
+		name += fmt.Sprintf("  [%d checkouts]", len(project.Clones)+1)
+	}
+	status := project.Tmux
+	if status == nil {
+		return "  " + name
+	}
+
+	label := "● " + name
+	if status.Attached {
+		label = "◆ " + name
+	}
+	if status.Activity {
+		label += " !"
+	}
+	return label
+}
+
+// pickerItemStyle colors the picker entries made by projectLabel with the
+// theme: the session status in the session color and the rest in the name
+// color, or with display: columns the directory in the path color and the
+// types in the marker color.
+func (cfg *Config) pickerItemStyle() fuzzyfinder.Option {
+	theme := cfg.theme()
+	session, name := tcellStyle(theme.Session), tcellStyle(theme.Name)
+	path, marker := tcellStyle(theme.Path), tcellStyle(theme.Marker)
+	columns := cfg.Display == displayColumns
+	return fuzzyfinder.WithItemStyle(func(item []rune) []tcell.Style {
+		styles := make([]tcell.Style, len(item))
+		for j := range styles {
+			styles[j] = name
+		}
+		if len(item) < 2 {
+			return styles
+		}
+		if item[0] != ' ' {
+			styles[0] = session
+			if n := len(item); item[n-1] == '!' {
+				styles[n-1] = session
+			}
+		}
+		if !columns {
+			return styles
+		}
+		// the columns are separated by at least two spaces, the number of
+		// checkouts follows the types in brackets
+		column, checkouts := 0, false
+		for j := 2; j < len(item); j++ {
+			if item[j] == ' ' && j+1 < len(item) && item[j+1] == ' ' {
+				for j+1 < len(item) && item[j+1] == ' ' {
+					j++
+				}
+				column++
+				checkouts = j+1 < len(item) && item[j+1] == '['
+				continue
+			}
+			switch {
+			case column == 0:
+				styles[j] = path
+			case column == 2 && !checkouts:
+				styles[j] = marker
+			}
+		}
+		return styles
+	})
+}
@@ -0,0 +1,131 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"text/template"
+	"time"
+)
+
+// defaultStatusFormat is the status_format used when none is configured,
+// such as api:main* [3].
+const defaultStatusFormat = "{{.Project}}{{with .Branch}}:{{.}}{{end}}{{if .Dirty}}*{{end}} [{{.Sessions}}]"
+
+// statusLineTTL is how long the output of tmuxer status is reused. tmux
+// runs it every status-interval, 15 seconds by default, for every client.
+const statusLineTTL = 10 * time.Second
+
+// statusData is available to the status_format template.
+type statusData struct {
+	// Project is the name of the project of the session, or the session
+	// name when it is no tmuxer session.
+	Project string
+	Session string
+	Branch  string
+	Dirty   bool
+	// Sessions is the number of running sessions.
+	Sessions int
+}
+
+// parseStatusFormat parses the status_format template of the config.
+func parseStatusFormat(text string) (*template.Template, error) {
+	tmpl, err := template.New("status").Option("missingkey=error").Parse(text)
+	if err != nil {
+		return nil, fmt.Errorf("invalid status format %q: %w", text, err)
+	}
+	if err := tmpl.Execute(&strings.Builder{}, statusData{}); err != nil {
+		return nil, fmt.Errorf("invalid status format %q: %w", text, err)
+	}
+	return tmpl, nil
+}
+
+// runStatusCommand prints a line describing the given tmux session, or the
+// current one, for the tmux status bar:
+//
+//	set -g status-right '#(tmuxer status #{session_name})'
+//
+// The line is cached for statusLineTTL.
+func runStatusCommand(cfg *Config, args []string) error {
+	if len(args) > 1 {
+		return errors.New("usage: tmuxer status [session]")
+	}
+
+	var session string
+	if len(args) == 1 {
+		session = args[0]
+	} else {
+		if !tmuxClient.IsInsideTmux() {
+			return errors.New("tmuxer status needs a session outside of tmux")
+		}
+		var err error
+		if session, err = tmuxClient.CurrentSession(); err != nil {
+			return err
+		}
+	}
+
+	cached, err := statusLineCachePath(session)
+	if err != nil {
+		return err
+	}
+	if info, err := os.Stat(cached); err == nil && time.Since(info.ModTime()) < statusLineTTL {
+		if line, err := os.ReadFile(cached); err == nil {
+			fmt.Println(string(line))
+			return nil
+		}
+	}
+
+	line, err := cfg.statusLine(session)
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(cached), 0o755); err == nil {
+		// a failure only costs asking tmux and git again next time
+		_ = os.WriteFile(cached, []byte(line), 0o644)
+	}
+	fmt.Println(line)
+	return nil
+}
+
+// statusLine renders status_format for session.
+func (cfg *Config) statusLine(session string) (string, error) {
+	format := cfg.StatusFormat
+	if format == "" {
+		format = defaultStatusFormat
+	}
+	tmpl, err := parseStatusFormat(format)
+	if err != nil {
+		return "", err
+	}
+
+	sessions, err := listSessions()
+	if err != nil {
+		return "", err
+	}
+	data := statusData{Project: session, Session: session, Sessions: len(sessions)}
+	if status := sessions[session]; status != nil && status.Path != "" {
+		project := &Project{Name: filepath.Base(status.Path), FullPath: status.Path}
+		enrichProjects([]*Project{project})
+		data.Project = project.Name
+		if project.Git != nil {
+			data.Branch, data.Dirty = project.Git.Branch, project.Git.Dirty
+		}
+	}
+
+	var b strings.Builder
+	if err := tmpl.Execute(&b, data); err != nil {
+		return "", fmt.Errorf("invalid status format %q: %w", format, err)
+	}
+	return b.String(), nil
+}
+
+// statusLineCachePath is where the status line of session is cached.
+func statusLineCachePath(session string) (string, error) {
+	dir, err := cacheDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, "status", strings.ReplaceAll(session, string(filepath.Separator), "_")), nil
+}
@@ -0,0 +1,120 @@
+package main
+
+import (
+	"errors"
+	"log/slog"
+	"strings"
+	"sync"
+	"sync/atomic"
+
+	"github.com/k1ng440/tmuxer/third_party/go-fuzzyfinder"
+	"github.com/spf13/pflag"
+)
+
+// streams reports whether runPicker opens the picker before the scan ends,
+// which it only does for the plain picker when stream is set.
+func (cfg *Config) streams(args []string) bool {
+	return cfg.Stream && len(args) == 0 && !*multiSelect && !*onlyDirty &&
+		*selectName == "" && cfg.HideStale == "" && !cfg.PickBase && !cfg.MergeClones && hasTerminal()
+}
+
+// runStreamingPicker opens the picker right away and adds the projects to
+// it as the scan finds them. They are listed in the order they are found
+// rather than sorted. The scan stops once a project is picked.
+func runStreamingPicker(cfg *Config) error {
+	var (
+		mu       sync.Mutex
+		projects []*Project
+		// shown is a copy of projects the preview reads without taking mu,
+		// which the picker may hold while it draws.
+		shown   atomic.Pointer[[]*Project]
+		stopped atomic.Bool
+	)
+	shown.Store(&projects)
+
+	archived, err := archivedFilter()
+	if err != nil {
+		return err
+	}
+	annotate := cfg.sessionAnnotator()
+	filterMarkers := pflag.CommandLine.Changed("marker")
+	scanned := make(chan error, 1)
+	go func() {
+		_, _, err := streamProjects(cfg, func(project *Project) error {
+			if stopped.Load() {
+				return errEnough
+			}
+			if filterMarkers && len(filterByMarker([]*Project{project}, *projectMarkers)) == 0 {
+				return nil
+			}
+			if archived(project) {
+				return nil
+			}
+			annotate(project)
+
+			mu.Lock()
+			projects = append(projects, project)
+			current := projects
+			mu.Unlock()
+			shown.Store(&current)
+			return nil
+		})
+		scanned <- err
+	}()
+
+	var (
+		actionErrs []error
+		query      string
+		noMatch    bool
+	)
+	idx, err := fuzzyfinder.Find(
+		&projects,
+		func(i int) string {
+			// called with mu held by the picker
+			return cfg.projectLabel(projects[i])
+		},
+		fuzzyfinder.WithHotReloadLock(&mu),
+		fuzzyfinder.WithPreviewWindow(func(i, _, _ int) string {
+			current := *shown.Load()
+			if i < 0 || i >= len(current) {
+				return ""
+			}
+			return previewText(cfg, current[i])
+		}),
+		cfg.pickerItemStyle(),
+		fuzzyfinder.WithQueryOutput(&query),
+		fuzzyfinder.WithKeyHandler(noMatchEntered(cfg.pickerKeyHandler(func(i int) *Project {
+			current := *shown.Load()
+			if i >= len(current) {
+				return nil
+			}
+			return current[i]
+		}, &mu, nil, &actionErrs), &noMatch)))
+	stopped.Store(true)
+	reportPickerErrors(actionErrs)
+	if errors.Is(err, fuzzyfinder.ErrAbort) && noMatch && strings.TrimSpace(query) != "" {
+		project, err := offerNewProject(cfg, strings.TrimSpace(query), *shown.Load())
+		if err != nil {
+			return err
+		}
+		return runActions(cfg, project)
+	}
+	if err != nil {
+		if errors.Is(err, fuzzyfinder.ErrAbort) {
+			select {
+			case scanErr := <-scanned:
+				if scanErr != nil {
+					return scanErr
+				}
+			default:
+			}
+		}
+		return err
+	}
+
+	mu.Lock()
+	project := projects[idx]
+	mu.Unlock()
+	slog.Info("starting selected project", "name", project.Name)
+	return runActions(cfg, project)
+}
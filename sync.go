@@ -0,0 +1,126 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"path/filepath"
+	"strings"
+)
+
+// runSyncCommand creates the windows and panes of the layout missing from
+// the current session, or the one given, leaving existing ones alone. This
+// applies layout edits without restarting the session.
+func runSyncCommand(cfg *Config, args []string) error {
+	if len(args) > 1 {
+		return errors.New("usage: tmuxer sync [session]")
+	}
+	session := ""
+	if len(args) == 1 {
+		session = args[0]
+	} else {
+		session, _ = tmuxClient.CurrentSession()
+	}
+	if session == "" {
+		return errors.New("not inside tmux, name the session to sync")
+	}
+
+	project, err := sessionOwner(cfg, session)
+	if err != nil {
+		return err
+	}
+	layout, err := cfg.layoutFor(project)
+	if err != nil {
+		return err
+	}
+	if layout == nil {
+		return fmt.Errorf("no layout configured for %s", project.Name)
+	}
+	if layout, err = layout.render(cfg, project); err != nil {
+		return err
+	}
+
+	windows, err := listWindows(session)
+	if err != nil {
+		return err
+	}
+	for i, w := range layout.Windows {
+		existing := matchWindow(windows, w, i)
+		if existing == nil {
+			target, err := newLayoutWindow(project, w)
+			if err != nil {
+				return err
+			}
+			if err := fillWindow(project, w, target, true, 0); err != nil {
+				return err
+			}
+			fmt.Printf("Created window %s\n", windowLabel(w, i))
+			continue
+		}
+
+		panes, err := tmuxOutput("list-panes", "-t", existing.ID, "-F", "#{pane_id}")
+		if err != nil {
+			return err
+		}
+		// the first pane of a window is not listed under panes
+		split := len(strings.Fields(panes)) - 1
+		if missing := len(w.Panes) - split; missing > 0 {
+			if err := fillWindow(project, w, existing.ID, false, split); err != nil {
+				return err
+			}
+			fmt.Printf("Added %d pane(s) to window %s\n", missing, windowLabel(w, i))
+		}
+	}
+	return nil
+}
+
+// sessionOwner returns the project of a session created or adopted by
+// tmuxer, including its variant.
+func sessionOwner(cfg *Config, session string) (*Project, error) {
+	path, err := tmuxOutput("display-message", "-p", "-t", session+":", "#{"+projectOption+"}")
+	if err != nil {
+		return nil, err
+	}
+	if path == "" {
+		return nil, fmt.Errorf("session %s was not created by tmuxer, see tmuxer adopt", session)
+	}
+
+	entries, err := readHistory()
+	if err != nil {
+		return nil, err
+	}
+	project, ok := sessionProject(entries, session)
+	if !ok || project.FullPath != path {
+		project = &Project{Name: filepath.Base(path), FullPath: path, Session: session}
+	}
+	project.HomePath = homePath(path)
+	if i := strings.LastIndex(session, "@"); i >= 0 {
+		if _, ok := cfg.Variants[session[i+1:]]; ok {
+			project.Variant = session[i+1:]
+		}
+	}
+	return project, nil
+}
+
+// matchWindow finds the window of the layout window w at position i, by
+// name or, for unnamed windows, by position.
+func matchWindow(windows []*tmuxWindow, w Window, i int) *tmuxWindow {
+	if w.Name == "" {
+		if i < len(windows) {
+			return windows[i]
+		}
+		return nil
+	}
+	for _, existing := range windows {
+		if existing.Name == w.Name {
+			return existing
+		}
+	}
+	return nil
+}
+
+func windowLabel(w Window, i int) string {
+	if w.Name != "" {
+		return w.Name
+	}
+	return fmt.Sprint(i + 1)
+}
@@ -0,0 +1,53 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+	"text/template"
+)
+
+// templateData is available to layout and hook templates.
+type templateData struct {
+	ProjectName string
+	ProjectPath string
+	Session     string
+	GitBranch   string
+	// Type is the primary project type, see detectTypes.
+	Type string
+}
+
+func newTemplateData(project *Project) templateData {
+	data := templateData{
+		ProjectName: project.Name,
+		ProjectPath: project.FullPath,
+		Session:     project.Session,
+	}
+	if types := detectTypes(project); len(types) > 0 {
+		data.Type = types[0]
+	}
+	if project.Git == nil && project.Remote == nil {
+		project.Git, _ = gitEnrichment(project.FullPath)
+	}
+	if project.Git != nil {
+		data.GitBranch = project.Git.Branch
+	}
+	return data
+}
+
+// renderTemplate executes text as a template with the data of project.
+// Text without actions is returned as is.
+func renderTemplate(text string, project *Project) (string, error) {
+	if !strings.Contains(text, "{{") {
+		return text, nil
+	}
+
+	tmpl, err := template.New("").Option("missingkey=error").Parse(text)
+	if err != nil {
+		return "", fmt.Errorf("invalid template %q: %w", text, err)
+	}
+	var b strings.Builder
+	if err := tmpl.Execute(&b, newTemplateData(project)); err != nil {
+		return "", fmt.Errorf("invalid template %q: %w", text, err)
+	}
+	return b.String(), nil
+}
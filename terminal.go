@@ -0,0 +1,90 @@
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"text/template"
+)
+
+const defaultTerminal = "x-terminal-emulator -e {{.Attach}}"
+
+// terminalPresets are the commands of terminal emulators that can be named
+// as terminal instead of a template.
+var terminalPresets = map[string]string{
+	"alacritty":      "alacritty --working-directory {{quote .Path}} -e {{.Attach}}",
+	"foot":           "foot --working-directory={{quote .Path}} {{.Attach}}",
+	"ghostty":        "ghostty --working-directory={{quote .Path}} -e {{.Attach}}",
+	"gnome-terminal": "gnome-terminal --working-directory={{quote .Path}} -- {{.Attach}}",
+	"kitty":          "kitty --directory {{quote .Path}} {{.Attach}}",
+	"wezterm":        "wezterm start --cwd {{quote .Path}} -- {{.Attach}}",
+}
+
+// terminalData is available to the terminal command template.
+type terminalData struct {
+	Name    string
+	Path    string
+	Session string
+	// Attach is a shell command attaching to the session.
+	Attach string
+}
+
+// attachCommand is the shell command attaching to session on the selected
+// tmux server.
+func attachCommand(session string) string {
+	command := "tmux"
+	for _, arg := range tmuxSocketArgs() {
+		command += " " + shellQuote(arg)
+	}
+	return command + " attach-session -t " + shellQuote(session)
+}
+
+// parseTerminal parses the terminal setting, which is one of
+// terminalPresets or a template, x-terminal-emulator when empty.
+func parseTerminal(text string) (*template.Template, error) {
+	if text == "" {
+		text = defaultTerminal
+	}
+	if preset, ok := terminalPresets[text]; ok {
+		text = preset
+	}
+	tmpl, err := template.New("terminal").Funcs(template.FuncMap{"quote": shellQuote}).Parse(text)
+	if err != nil {
+		return nil, fmt.Errorf("invalid terminal template: %w", err)
+	}
+	return tmpl, nil
+}
+
+// spawnTerminalWindow starts a new terminal emulator window attached to the
+// project session and returns without waiting for it.
+func spawnTerminalWindow(cfg *Config, project *Project) error {
+	tmpl, err := parseTerminal(cfg.Terminal)
+	if err != nil {
+		return err
+	}
+
+	var command bytes.Buffer
+	err = tmpl.Execute(&command, terminalData{
+		Name:    project.Name,
+		Path:    project.FullPath,
+		Session: project.Session,
+		Attach:  attachCommand(project.Session),
+	})
+	if err != nil {
+		return fmt.Errorf("invalid terminal template: %w", err)
+	}
+
+	cmd := shellCommand(command.String())
+	cmd.Dir, _ = windowStart(project)
+	// the attach command would refuse to nest the session when tmuxer
+	// runs inside tmux
+	cmd.Env = append(os.Environ(), "TMUX=")
+	if *dryRun {
+		fmt.Println(commandLine(cmd))
+		return nil
+	}
+	if err := cmd.Start(); err != nil {
+		return fmt.Errorf("failed to start terminal: %w", err)
+	}
+	return cmd.Process.Release()
+}
@@ -0,0 +1,188 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+
+	"github.com/gdamore/tcell/v2"
+)
+
+// Theme colors the picker entries and preview and the output of tmuxer
+// list. Each color is a list of attributes and a color separated by
+// spaces, such as "bold blue", "208" or "#ff8800", or none.
+type Theme struct {
+	Name    string `yaml:"name"`
+	Path    string `yaml:"path"`
+	Marker  string `yaml:"marker"`
+	Session string `yaml:"session"`
+	// Label colors the field names of the preview.
+	Label string `yaml:"label"`
+}
+
+var defaultTheme = Theme{
+	Name:    "bold",
+	Path:    "blue",
+	Marker:  "magenta",
+	Session: "green",
+	Label:   "yellow",
+}
+
+// theme returns the configured theme with unset colors taken from
+// defaultTheme.
+func (cfg *Config) theme() Theme {
+	t := cfg.Theme
+	for _, c := range []struct {
+		value *string
+		def   string
+	}{
+		{&t.Name, defaultTheme.Name},
+		{&t.Path, defaultTheme.Path},
+		{&t.Marker, defaultTheme.Marker},
+		{&t.Session, defaultTheme.Session},
+		{&t.Label, defaultTheme.Label},
+	} {
+		if *c.value == "" {
+			*c.value = c.def
+		}
+	}
+	return t
+}
+
+// colorEnabled reports whether output is colored, which --no-color and the
+// NO_COLOR environment variable turn off.
+func colorEnabled() bool {
+	return !*noColor && os.Getenv("NO_COLOR") == ""
+}
+
+// isTerminal reports whether f is a terminal rather than a pipe or file.
+func isTerminal(f *os.File) bool {
+	info, err := f.Stat()
+	return err == nil && info.Mode()&os.ModeCharDevice != 0
+}
+
+var sgrAttributes = map[string]string{
+	"bold":      "1",
+	"dim":       "2",
+	"italic":    "3",
+	"underline": "4",
+	"reverse":   "7",
+}
+
+var sgrColors = []string{"black", "red", "green", "yellow", "blue", "magenta", "cyan", "white"}
+
+// parseColor turns a theme color into the parameters of an SGR escape
+// sequence.
+func parseColor(spec string) (string, error) {
+	var params []string
+	for _, word := range strings.Fields(spec) {
+		if word == "none" {
+			continue
+		}
+		if p, ok := sgrAttributes[word]; ok {
+			params = append(params, p)
+			continue
+		}
+		if p, ok := colorParam(word); ok {
+			params = append(params, p)
+			continue
+		}
+		return "", fmt.Errorf("unknown color %q", word)
+	}
+	return strings.Join(params, ";"), nil
+}
+
+func colorParam(word string) (string, bool) {
+	name, bright := strings.CutPrefix(word, "bright-")
+	for i, c := range sgrColors {
+		if c == name {
+			if bright {
+				return strconv.Itoa(90 + i), true
+			}
+			return strconv.Itoa(30 + i), true
+		}
+	}
+	if bright {
+		return "", false
+	}
+	if n, err := strconv.Atoi(word); err == nil && n >= 0 && n <= 255 {
+		return "38;5;" + word, true
+	}
+	if hex, ok := strings.CutPrefix(word, "#"); ok && len(hex) == 6 {
+		rgb, err := strconv.ParseUint(hex, 16, 32)
+		if err == nil {
+			return fmt.Sprintf("38;2;%d;%d;%d", rgb>>16, rgb>>8&0xff, rgb&0xff), true
+		}
+	}
+	return "", false
+}
+
+// tcellStyle returns the theme color spec as the style of picker entries,
+// the default style when colors are turned off or spec is invalid.
+func tcellStyle(spec string) tcell.Style {
+	style := tcell.StyleDefault
+	if !colorEnabled() {
+		return style
+	}
+	for _, word := range strings.Fields(spec) {
+		switch word {
+		case "none":
+		case "bold":
+			style = style.Bold(true)
+		case "dim":
+			style = style.Dim(true)
+		case "italic":
+			style = style.Italic(true)
+		case "underline":
+			style = style.Underline(true)
+		case "reverse":
+			style = style.Reverse(true)
+		default:
+			color, ok := tcellColor(word)
+			if !ok {
+				return tcell.StyleDefault
+			}
+			style = style.Foreground(color)
+		}
+	}
+	return style
+}
+
+// tcellColor is colorParam for tcell.
+func tcellColor(word string) (tcell.Color, bool) {
+	name, bright := strings.CutPrefix(word, "bright-")
+	for i, c := range sgrColors {
+		if c == name {
+			if bright {
+				i += 8
+			}
+			return tcell.PaletteColor(i), true
+		}
+	}
+	if bright {
+		return tcell.ColorDefault, false
+	}
+	if n, err := strconv.Atoi(word); err == nil && n >= 0 && n <= 255 {
+		return tcell.PaletteColor(n), true
+	}
+	if hex, ok := strings.CutPrefix(word, "#"); ok && len(hex) == 6 {
+		if rgb, err := strconv.ParseUint(hex, 16, 32); err == nil {
+			return tcell.NewHexColor(int32(rgb)), true
+		}
+	}
+	return tcell.ColorDefault, false
+}
+
+// paint colors s with the theme color spec unless colors are turned off.
+// Invalid colors, reported by tmuxer config validate, leave s as is.
+func paint(spec, s string) string {
+	if s == "" || !colorEnabled() {
+		return s
+	}
+	params, err := parseColor(spec)
+	if err != nil || params == "" {
+		return s
+	}
+	return "\x1b[" + params + "m" + s + "\x1b[0m"
+}
@@ -0,0 +1,152 @@
+package fuzzyfinder
+
+import (
+	"sync"
+
+	"github.com/gdamore/tcell/v2"
+)
+
+type opt struct {
+	mode          mode
+	previewFunc   func(i, width, height int) string
+	multi         bool
+	hotReload     bool
+	hotReloadLock sync.Locker
+	promptString  string
+	header        string
+	beginAtTop    bool
+	keyHandler    func(e *tcell.EventKey, i int) bool
+	itemStyle     func(item []rune) []tcell.Style
+	query         *string
+}
+
+type mode int
+
+const (
+	// ModeSmart enables a smart matching. It is the default matching mode.
+	// At the beginning, matching mode is ModeCaseInsensitive, but it switches
+	// over to ModeCaseSensitive if an upper case character is inputted.
+	ModeSmart mode = iota
+	// ModeCaseSensitive enables a case-sensitive matching.
+	ModeCaseSensitive
+	// ModeCaseInsensitive enables a case-insensitive matching.
+	ModeCaseInsensitive
+)
+
+var defaultOption = opt{
+	promptString: "> ",
+	hotReloadLock: &sync.Mutex{}, // this won't resolve the race condition but avoid nil panic
+}
+
+// Option represents available fuzzy-finding options.
+type Option func(*opt)
+
+// WithMode specifies a matching mode. The default mode is ModeSmart.
+func WithMode(m mode) Option {
+	return func(o *opt) {
+		o.mode = m
+	}
+}
+
+// WithPreviewWindow enables to display a preview for the selected item.
+// The argument f receives i, width and height. i is the same as Find's one.
+// width and height are the size of the terminal so that you can use these to adjust
+// a preview content. Note that width and height are calculated as a rune-based length.
+//
+// If there is no selected item, previewFunc passes -1 to previewFunc.
+//
+// If f is nil, the preview feature is disabled.
+func WithPreviewWindow(f func(i, width, height int) string) Option {
+	return func(o *opt) {
+		o.previewFunc = f
+	}
+}
+
+// WithHotReload reloads the passed slice automatically when some entries are appended.
+// The caller must pass a pointer of the slice instead of the slice itself.
+//
+// Deprecated: use WithHotReloadLock instead.
+func WithHotReload() Option {
+	return func(o *opt) {
+		o.hotReload = true
+	}
+}
+
+// WithHotReloadLock reloads the passed slice automatically when some entries are appended.
+// The caller must pass a pointer of the slice instead of the slice itself.
+// The caller must pass a RLock which is used to synchronize access to the slice.
+// The caller MUST NOT lock in the itemFunc passed to Find / FindMulti because it will be locked by the fuzzyfinder.
+// If used together with WithPreviewWindow, the caller MUST use the RLock only in the previewFunc passed to WithPreviewWindow. 
+func WithHotReloadLock(lock sync.Locker) Option {
+	return func(o *opt) {
+		o.hotReload = true
+		o.hotReloadLock = lock
+	}
+}
+
+type cursorPosition int
+
+const (
+	CursorPositionBottom cursorPosition = iota
+	CursorPositionTop
+)
+
+// WithCursorPosition sets the initial position of the cursor
+func WithCursorPosition(position cursorPosition) Option {
+	return func(o *opt) {
+		switch position {
+		case CursorPositionTop:
+			o.beginAtTop = true
+		case CursorPositionBottom:
+			o.beginAtTop = false
+		}
+	}
+}
+
+// WithPromptString changes the prompt string. The default value is "> ".
+func WithPromptString(s string) Option {
+	return func(o *opt) {
+		o.promptString = s
+	}
+}
+
+// withMulti enables to select multiple items by tab key.
+func withMulti() Option {
+	return func(o *opt) {
+		o.multi = true
+	}
+}
+
+// WithKeyHandler passes every key event to f before the finder handles it,
+// along with the index of the item under the cursor or -1 when nothing
+// matches. Keys f returns true for are not handled by the finder, which
+// makes the items again afterwards, as f may have changed them.
+func WithKeyHandler(f func(e *tcell.EventKey, i int) bool) Option {
+	return func(o *opt) {
+		o.keyHandler = f
+	}
+}
+
+// WithItemStyle styles the runes of the items with the styles f returns for
+// them, one per rune. Runes without a style keep the default one. Matched
+// runes and the item under the cursor are highlighted as usual.
+func WithItemStyle(f func(item []rune) []tcell.Style) Option {
+	return func(o *opt) {
+		o.itemStyle = f
+	}
+}
+
+// WithQueryOutput stores the query typed into the finder in q when the
+// finder returns, also when nothing matched it.
+func WithQueryOutput(q *string) Option {
+	return func(o *opt) {
+		o.query = q
+	}
+}
+
+// WithHeader enables to set the header.
+func WithHeader(s string) Option {
+	return func(o *opt) {
+		o.header = s
+	}
+}
@@ -0,0 +1,246 @@
+// Package tmux queries a tmux server. Commands run through a Runner, so
+// the queries can be tested without tmux.
+package tmux
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// ExitError is returned by a Runner when tmux exits with a non-zero status.
+type ExitError struct {
+	Code   int
+	Stderr string
+}
+
+func (e *ExitError) Error() string {
+	if msg := strings.TrimSpace(e.Stderr); msg != "" {
+		return fmt.Sprintf("tmux exited with status %d: %s", e.Code, msg)
+	}
+	return fmt.Sprintf("tmux exited with status %d", e.Code)
+}
+
+// noServer reports whether tmux failing with err after printing output
+// means there is no server to ask. tmux lists nothing and exits with status
+// 1 then, whatever language its message is in.
+func noServer(output []byte, err error) bool {
+	var exit *ExitError
+	return errors.As(err, &exit) && len(strings.TrimSpace(string(output))) == 0
+}
+
+// Runner runs tmux with args, such as list-sessions -F '#S', and returns
+// its standard output. It returns an *ExitError when tmux exits with a
+// non-zero status.
+type Runner interface {
+	Run(args ...string) ([]byte, error)
+}
+
+// RunnerFunc is a function used as a Runner.
+type RunnerFunc func(args ...string) ([]byte, error)
+
+func (f RunnerFunc) Run(args ...string) ([]byte, error) {
+	return f(args...)
+}
+
+// Client queries the tmux server its Runner talks to.
+type Client struct {
+	runner Runner
+	getenv func(string) string
+}
+
+// New returns a client running tmux with runner.
+func New(runner Runner) *Client {
+	return &Client{runner: runner, getenv: os.Getenv}
+}
+
+// IsInsideTmux reports whether the process runs inside a tmux client, which
+// sets $TMUX.
+func (c *Client) IsInsideTmux() bool {
+	return c.getenv("TMUX") != ""
+}
+
+// HasSession reports whether the session named exactly name exists. It is
+// false as well when no server is running.
+func (c *Client) HasSession(name string) (bool, error) {
+	// = matches the whole name instead of any session starting with it
+	_, err := c.runner.Run("has-session", "-t", "="+name)
+	var exit *ExitError
+	if errors.As(err, &exit) {
+		return false, nil
+	}
+	if err != nil {
+		return false, fmt.Errorf("failed to check for session %s: %w", name, err)
+	}
+	return true, nil
+}
+
+// Session is a running tmux session.
+type Session struct {
+	Name string
+	// Clients is the number of clients attached to the session.
+	Clients  int
+	Windows  int
+	Created  time.Time
+	Activity time.Time
+	// Alerted is set when a window of the session has an activity, bell or
+	// silence alert nobody has looked at yet.
+	Alerted bool
+	// Path is the start directory of the session.
+	Path string
+	// Options are the values of the session options ListSessions was
+	// asked for, such as @project, empty when unset.
+	Options map[string]string
+}
+
+// Attached reports whether a client is attached to the session.
+func (s *Session) Attached() bool {
+	return s.Clients > 0
+}
+
+// sessionFormat are the fields of Session in the order ListSessions asks
+// tmux for them.
+var sessionFormat = []string{
+	"#{session_name}",
+	"#{session_attached}",
+	"#{session_windows}",
+	"#{session_created}",
+	"#{session_activity}",
+	"#{session_alerts}",
+	"#{session_path}",
+}
+
+// ListSessions returns the running sessions along with the values of the
+// given session options, such as @project. Without a running server there
+// are no sessions.
+func (c *Client) ListSessions(options ...string) ([]*Session, error) {
+	format := append([]string{}, sessionFormat...)
+	for _, o := range options {
+		format = append(format, "#{"+o+"}")
+	}
+	output, err := c.runner.Run("list-sessions", "-F", strings.Join(format, "\t"))
+	if noServer(output, err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to list sessions: %w", err)
+	}
+
+	var sessions []*Session
+	for _, line := range strings.Split(strings.TrimRight(string(output), "\r\n"), "\n") {
+		if line == "" {
+			continue
+		}
+		fields := strings.Split(strings.TrimRight(line, "\r"), "\t")
+		if len(fields) != len(format) {
+			return nil, fmt.Errorf("unexpected list-sessions output %q", line)
+		}
+		s := &Session{
+			Name:     fields[0],
+			Clients:  atoi(fields[1]),
+			Windows:  atoi(fields[2]),
+			Created:  unixTime(fields[3]),
+			Activity: unixTime(fields[4]),
+			Alerted:  fields[5] != "",
+			Path:     fields[6],
+			Options:  make(map[string]string, len(options)),
+		}
+		for i, o := range options {
+			s.Options[o] = fields[len(sessionFormat)+i]
+		}
+		sessions = append(sessions, s)
+	}
+	return sessions, nil
+}
+
+// Window is a window of a session.
+type Window struct {
+	Index  int
+	Name   string
+	Active bool
+}
+
+// ListWindows returns the windows of session in order.
+func (c *Client) ListWindows(session string) ([]*Window, error) {
+	rows, err := c.list(3, "list-windows", "-t", "="+session+":", "-F", "#{window_index}\t#{window_name}\t#{window_active}")
+	if err != nil {
+		return nil, fmt.Errorf("failed to list the windows of %s: %w", session, err)
+	}
+	windows := make([]*Window, 0, len(rows))
+	for _, fields := range rows {
+		windows = append(windows, &Window{Index: atoi(fields[0]), Name: fields[1], Active: fields[2] == "1"})
+	}
+	return windows, nil
+}
+
+// Pane is a pane of a session.
+type Pane struct {
+	// WindowIndex is the index of the window the pane is in.
+	WindowIndex int
+	// Command is the command running in the pane.
+	Command string
+}
+
+// ListPanes returns the panes of all windows of session.
+func (c *Client) ListPanes(session string) ([]*Pane, error) {
+	rows, err := c.list(2, "list-panes", "-s", "-t", "="+session+":", "-F", "#{window_index}\t#{pane_current_command}")
+	if err != nil {
+		return nil, fmt.Errorf("failed to list the panes of %s: %w", session, err)
+	}
+	panes := make([]*Pane, 0, len(rows))
+	for _, fields := range rows {
+		panes = append(panes, &Pane{WindowIndex: atoi(fields[0]), Command: fields[1]})
+	}
+	return panes, nil
+}
+
+// list runs a tmux list command with args and splits its output into lines
+// of n tab separated fields.
+func (c *Client) list(n int, args ...string) ([][]string, error) {
+	output, err := c.runner.Run(args...)
+	if err != nil {
+		return nil, err
+	}
+	var rows [][]string
+	for _, line := range strings.Split(strings.TrimRight(string(output), "\r\n"), "\n") {
+		if line == "" {
+			continue
+		}
+		fields := strings.Split(strings.TrimRight(line, "\r"), "\t")
+		if len(fields) != n {
+			return nil, fmt.Errorf("unexpected %s output %q", args[0], line)
+		}
+		rows = append(rows, fields)
+	}
+	return rows, nil
+}
+
+// CurrentSession returns the name of the session the process runs in.
+func (c *Client) CurrentSession() (string, error) {
+	if !c.IsInsideTmux() {
+		return "", errors.New("not inside tmux")
+	}
+	output, err := c.runner.Run("display-message", "-p", "#{session_name}")
+	if err != nil {
+		return "", fmt.Errorf("failed to get the current session: %w", err)
+	}
+	return strings.TrimRight(string(output), "\r\n"), nil
+}
+
+func atoi(s string) int {
+	n, _ := strconv.Atoi(s)
+	return n
+}
+
+// unixTime parses the seconds since the epoch tmux formats times as, zero
+// when s is not a number.
+func unixTime(s string) time.Time {
+	secs, err := strconv.ParseInt(s, 10, 64)
+	if err != nil {
+		return time.Time{}
+	}
+	return time.Unix(secs, 0)
+}
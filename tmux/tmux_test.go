@@ -0,0 +1,203 @@
+package tmux
+
+import (
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+// fakeRunner answers tmux commands from canned output and records them.
+type fakeRunner struct {
+	output string
+	err    error
+	calls  [][]string
+}
+
+func (f *fakeRunner) Run(args ...string) ([]byte, error) {
+	f.calls = append(f.calls, args)
+	return []byte(f.output), f.err
+}
+
+func newClient(runner Runner, env map[string]string) *Client {
+	c := New(runner)
+	c.getenv = func(key string) string { return env[key] }
+	return c
+}
+
+func TestHasSession(t *testing.T) {
+	tests := []struct {
+		name    string
+		err     error
+		want    bool
+		wantErr bool
+	}{
+		{name: "exists", want: true},
+		{name: "missing", err: &ExitError{Code: 1, Stderr: "can't find session: api"}},
+		{name: "no server", err: &ExitError{Code: 1, Stderr: "no server running on /tmp/tmux-1000/default"}},
+		{name: "tmux not installed", err: errors.New(`exec: "tmux": executable file not found in $PATH`), wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			runner := &fakeRunner{err: tt.err}
+			got, err := newClient(runner, nil).HasSession("api")
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("HasSession() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Errorf("HasSession() = %v, want %v", got, tt.want)
+			}
+			want := []string{"has-session", "-t", "=api"}
+			if !reflect.DeepEqual(runner.calls[0], want) {
+				t.Errorf("ran %q, want %q", runner.calls[0], want)
+			}
+		})
+	}
+}
+
+func TestListSessions(t *testing.T) {
+	runner := &fakeRunner{output: strings.Join([]string{
+		"api\t1\t3\t1700000000\t1700000600\t\t/src/api\t/src/api",
+		// empty fields at the end must not shift the others
+		"scratch\t0\t1\t1700000100\t1700000100\t1\t/home/me\t",
+	}, "\n") + "\n"}
+
+	got, err := newClient(runner, nil).ListSessions("@project")
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []*Session{
+		{
+			Name:     "api",
+			Clients:  1,
+			Windows:  3,
+			Created:  time.Unix(1700000000, 0),
+			Activity: time.Unix(1700000600, 0),
+			Path:     "/src/api",
+			Options:  map[string]string{"@project": "/src/api"},
+		},
+		{
+			Name:     "scratch",
+			Windows:  1,
+			Created:  time.Unix(1700000100, 0),
+			Activity: time.Unix(1700000100, 0),
+			Alerted:  true,
+			Path:     "/home/me",
+			Options:  map[string]string{"@project": ""},
+		},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("ListSessions() = %+v, want %+v", got, want)
+	}
+	if !got[0].Attached() || got[1].Attached() {
+		t.Errorf("Attached() = %v, %v, want true, false", got[0].Attached(), got[1].Attached())
+	}
+	if format := runner.calls[0][2]; !strings.HasSuffix(format, "\t#{@project}") {
+		t.Errorf("format %q does not ask for the option", format)
+	}
+}
+
+func TestListSessionsErrors(t *testing.T) {
+	tests := []struct {
+		name    string
+		output  string
+		err     error
+		wantErr bool
+	}{
+		{name: "no server", err: &ExitError{Code: 1, Stderr: "no server running on /tmp/tmux-1000/default\n"}},
+		{name: "socket gone", err: &ExitError{Code: 1, Stderr: "error connecting to /tmp/tmux-1000/default (No such file or directory)\n"}},
+		{name: "no server in another language", err: &ExitError{Code: 1, Stderr: "kein Server auf /tmp/tmux-1000/default\n"}},
+		{name: "failure after output", output: "api\t1\t3\t1700000000\t1700000600\t\t/src/api\n", err: &ExitError{Code: 1, Stderr: "lost server"}, wantErr: true},
+		{name: "tmux not installed", err: errors.New(`exec: "tmux": executable file not found in $PATH`), wantErr: true},
+		{name: "malformed output", output: "api\t1\n", wantErr: true},
+		{name: "no sessions"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := newClient(&fakeRunner{output: tt.output, err: tt.err}, nil).ListSessions()
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("ListSessions() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if len(got) != 0 {
+				t.Errorf("ListSessions() = %+v, want no sessions", got)
+			}
+		})
+	}
+}
+
+func TestListWindows(t *testing.T) {
+	runner := &fakeRunner{output: "1\teditor\t1\n2\tserver\t0\n"}
+	got, err := newClient(runner, nil).ListWindows("api")
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []*Window{
+		{Index: 1, Name: "editor", Active: true},
+		{Index: 2, Name: "server"},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("ListWindows() = %+v, want %+v", got, want)
+	}
+	if target := runner.calls[0][2]; target != "=api:" {
+		t.Errorf("target = %q, want =api:", target)
+	}
+
+	if _, err := newClient(&fakeRunner{output: "1\teditor\n"}, nil).ListWindows("api"); err == nil {
+		t.Error("ListWindows() of malformed output succeeded")
+	}
+}
+
+func TestListPanes(t *testing.T) {
+	runner := &fakeRunner{output: "1\tnvim\n2\tgo\n2\tbash\n"}
+	got, err := newClient(runner, nil).ListPanes("api")
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []*Pane{
+		{WindowIndex: 1, Command: "nvim"},
+		{WindowIndex: 2, Command: "go"},
+		{WindowIndex: 2, Command: "bash"},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("ListPanes() = %+v, want %+v", got, want)
+	}
+}
+
+func TestCurrentSession(t *testing.T) {
+	runner := &fakeRunner{output: "api\n"}
+	got, err := newClient(runner, map[string]string{"TMUX": "/tmp/tmux-1000/default,123,0"}).CurrentSession()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got != "api" {
+		t.Errorf("CurrentSession() = %q, want api", got)
+	}
+
+	runner = &fakeRunner{output: "api\n"}
+	if _, err := newClient(runner, nil).CurrentSession(); err == nil {
+		t.Error("CurrentSession() outside tmux succeeded")
+	}
+	if len(runner.calls) != 0 {
+		t.Errorf("ran %q outside tmux", runner.calls)
+	}
+}
+
+func TestIsInsideTmux(t *testing.T) {
+	if newClient(&fakeRunner{}, nil).IsInsideTmux() {
+		t.Error("IsInsideTmux() without $TMUX = true")
+	}
+	if !newClient(&fakeRunner{}, map[string]string{"TMUX": "/tmp/tmux-1000/default,123,0"}).IsInsideTmux() {
+		t.Error("IsInsideTmux() with $TMUX = false")
+	}
+}
+
+func TestExitError(t *testing.T) {
+	err := &ExitError{Code: 1, Stderr: "no server running on /tmp/tmux-1000/default\n"}
+	if got, want := err.Error(), "tmux exited with status 1: no server running on /tmp/tmux-1000/default"; got != want {
+		t.Errorf("Error() = %q, want %q", got, want)
+	}
+	if got, want := (&ExitError{Code: 2}).Error(), "tmux exited with status 2"; got != want {
+		t.Errorf("Error() = %q, want %q", got, want)
+	}
+}
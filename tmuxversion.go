@@ -0,0 +1,95 @@
+package main
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"sync"
+)
+
+// tmuxVersion is a tmux release such as 3.3a, ignoring the letter suffix.
+type tmuxVersion struct {
+	Major, Minor int
+}
+
+func (v tmuxVersion) String() string {
+	return fmt.Sprintf("%d.%d", v.Major, v.Minor)
+}
+
+func (v tmuxVersion) atLeast(o tmuxVersion) bool {
+	return v.Major > o.Major || v.Major == o.Major && v.Minor >= o.Minor
+}
+
+// minTmuxVersion is the oldest tmux tmuxer works with, 1.9 added -c to
+// new-session.
+var minTmuxVersion = tmuxVersion{1, 9}
+
+// tmux features that depend on the installed version.
+const (
+	featureSessionEnv = "new-session -e"
+	featurePopup      = "display-popup"
+	featureHookIndex  = "hook arrays"
+	featurePaneTitle  = "select-pane -T"
+)
+
+var tmuxFeatures = map[string]tmuxVersion{
+	featureSessionEnv: {3, 2},
+	featurePopup:      {3, 2},
+	featureHookIndex:  {3, 0},
+	featurePaneTitle:  {2, 6},
+}
+
+var tmuxVersionRegex = regexp.MustCompile(`(\d+)\.(\d+)`)
+
+var (
+	tmuxVersionOnce   sync.Once
+	tmuxVersionCached tmuxVersion
+	tmuxVersionErr    error
+)
+
+// currentTmuxVersion probes the installed tmux with tmux -V once. Builds
+// from git, such as "tmux next-3.5" or "tmux master", count as the newest
+// release.
+func currentTmuxVersion() (tmuxVersion, error) {
+	tmuxVersionOnce.Do(func() {
+		output, err := tmuxOutput("-V")
+		if err != nil {
+			tmuxVersionErr = fmt.Errorf("failed to detect the tmux version: %w", err)
+			return
+		}
+		tmuxVersionCached = parseTmuxVersion(output)
+	})
+	return tmuxVersionCached, tmuxVersionErr
+}
+
+func parseTmuxVersion(s string) tmuxVersion {
+	m := tmuxVersionRegex.FindStringSubmatch(s)
+	if m == nil {
+		return tmuxVersion{Major: 1 << 16}
+	}
+	major, _ := strconv.Atoi(m[1])
+	minor, _ := strconv.Atoi(m[2])
+	return tmuxVersion{major, minor}
+}
+
+// tmuxSupports reports whether the installed tmux has feature. When the
+// version cannot be detected the feature is assumed to be there.
+func tmuxSupports(feature string) bool {
+	v, err := currentTmuxVersion()
+	if err != nil {
+		return true
+	}
+	return v.atLeast(tmuxFeatures[feature])
+}
+
+// checkTmuxVersion fails when the installed tmux is too old for tmuxer.
+func checkTmuxVersion() error {
+	v, err := currentTmuxVersion()
+	if err != nil {
+		return err
+	}
+	if !v.atLeast(minTmuxVersion) {
+		return fmt.Errorf("tmux %s is too old, tmuxer needs tmux %s or newer; upgrade tmux or use --no-tmux", v, minTmuxVersion)
+	}
+	return nil
+}
@@ -0,0 +1,197 @@
+package main
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"sync"
+)
+
+// trustedFile maps the project config files the user approved with tmuxer
+// trust to the sha256 of their contents.
+const trustedFile = "trusted.json"
+
+// runTrustCommand approves the .tmuxer.yaml and .tmuxer/config.yaml of the
+// project at dir, or around the working directory, so their settings are
+// used. Changing a file afterwards needs another tmuxer trust.
+func runTrustCommand(_ *Config, args []string) error {
+	files, err := localConfigFiles(args, "trust")
+	if err != nil {
+		return err
+	}
+	trusted, err := loadTrusted()
+	if err != nil {
+		return err
+	}
+	for _, f := range files {
+		data, err := os.ReadFile(f)
+		if err != nil {
+			return err
+		}
+		trusted[f] = contentHash(data)
+	}
+	if err := saveTrusted(trusted); err != nil {
+		return err
+	}
+	for _, f := range files {
+		fmt.Printf("Trusted %s\n", tildePath(f))
+	}
+	return nil
+}
+
+// runUntrustCommand withdraws the approval of tmuxer trust.
+func runUntrustCommand(_ *Config, args []string) error {
+	files, err := localConfigFiles(args, "untrust")
+	if err != nil {
+		return err
+	}
+	trusted, err := loadTrusted()
+	if err != nil {
+		return err
+	}
+	for _, f := range files {
+		delete(trusted, f)
+	}
+	if err := saveTrusted(trusted); err != nil {
+		return err
+	}
+	for _, f := range files {
+		fmt.Printf("Untrusted %s\n", tildePath(f))
+	}
+	return nil
+}
+
+// localConfigFiles returns the project config files of the directory in
+// args, or of the closest project around the working directory.
+func localConfigFiles(args []string, cmd string) ([]string, error) {
+	if len(args) > 1 {
+		return nil, fmt.Errorf("usage: tmuxer %s [dir]", cmd)
+	}
+	var dir string
+	if len(args) == 1 {
+		var err error
+		if dir, err = normalizePath(args[0]); err != nil {
+			return nil, err
+		}
+	} else {
+		wd, err := os.Getwd()
+		if err != nil {
+			return nil, err
+		}
+		var ok bool
+		if dir, _, ok = findProjectRoot(wd, []string{localConfigFile, localDir}); !ok {
+			return nil, fmt.Errorf("no %s or %s around %s", localConfigFile, localDir, wd)
+		}
+	}
+
+	var files []string
+	for _, f := range []string{filepath.Join(dir, localConfigFile), filepath.Join(dir, localDir, "config.yaml")} {
+		if _, err := os.Stat(f); err == nil {
+			files = append(files, f)
+		}
+	}
+	if len(files) == 0 {
+		return nil, fmt.Errorf("no %s or %s in %s", localConfigFile, filepath.Join(localDir, "config.yaml"), dir)
+	}
+	return files, nil
+}
+
+// untrustedWarned holds the files isTrusted warned about, so each is
+// reported once per run.
+var untrustedWarned sync.Map
+
+// isTrusted reports whether the project config file p with contents data
+// was approved with tmuxer trust, warning once when it was not. Project
+// config comes with the repository, so it could run anything in the
+// session otherwise.
+func isTrusted(p string, data []byte) bool {
+	trusted, err := loadTrusted()
+	if err != nil {
+		slog.Warn("failed to load trusted project configs", "err", err)
+		return false
+	}
+	hash, ok := trusted[p]
+	if ok && hash == contentHash(data) {
+		return true
+	}
+	if _, warned := untrustedWarned.LoadOrStore(p, true); !warned {
+		reason := "not trusted"
+		if ok {
+			reason = "changed since it was trusted"
+		}
+		slog.Warn("ignoring project config, run tmuxer trust in the project to use it", "file", tildePath(p), "reason", reason)
+	}
+	return false
+}
+
+func contentHash(data []byte) string {
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:])
+}
+
+func trustedPath() (string, error) {
+	dir, err := dataDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, trustedFile), nil
+}
+
+func loadTrusted() (map[string]string, error) {
+	p, err := trustedPath()
+	if err != nil {
+		return nil, err
+	}
+	trusted := make(map[string]string)
+	data, err := os.ReadFile(p)
+	if errors.Is(err, fs.ErrNotExist) {
+		return trusted, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	if err := json.Unmarshal(data, &trusted); err != nil {
+		return nil, fmt.Errorf("corrupt trusted configs file %s: %w", p, err)
+	}
+	return trusted, nil
+}
+
+func saveTrusted(trusted map[string]string) error {
+	p, err := trustedPath()
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(p), 0o755); err != nil {
+		return err
+	}
+	data, err := json.MarshalIndent(trusted, "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(p, data, 0o644)
+}
+
+// moveTrusted keeps the approval of the config files of a moved project.
+func moveTrusted(old, moved *Project) error {
+	trusted, err := loadTrusted()
+	if err != nil || len(trusted) == 0 {
+		return err
+	}
+	files := make([]string, 0, len(trusted))
+	for f := range trusted {
+		files = append(files, f)
+	}
+	for _, f := range files {
+		if p, ok := movedPath(f, old.FullPath, moved.FullPath); ok && p != f {
+			trusted[p] = trusted[f]
+			delete(trusted, f)
+		}
+	}
+	return saveTrusted(trusted)
+}
@@ -0,0 +1,202 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"net/url"
+	"os"
+	"os/exec"
+	"path"
+	"path/filepath"
+	"runtime"
+	"strings"
+)
+
+const urlScheme = "tmuxer"
+
+const urlHandlerDesktopEntry = `[Desktop Entry]
+Type=Application
+Name=tmuxer
+Comment=Open tmuxer:// links in a tmux session
+Exec=%s url %%u
+Terminal=true
+NoDisplay=true
+MimeType=x-scheme-handler/tmuxer;
+`
+
+// runURLCommand handles links of the form
+//
+//	tmuxer://open?path=~/code/tmuxer
+//	tmuxer://open?repo=https://github.com/k1ng440/tmuxer.git
+//
+// and `tmuxer url register`, which registers tmuxer as the handler for the
+// scheme with the desktop environment.
+func runURLCommand(cfg *Config, args []string) error {
+	if len(args) != 1 {
+		return errors.New("usage: tmuxer url <tmuxer://open?path=...|tmuxer://open?repo=...>")
+	}
+
+	if args[0] == "register" {
+		return registerURLHandler()
+	}
+
+	project, err := projectFromURL(cfg, args[0])
+	if err != nil {
+		return err
+	}
+
+	return runActions(cfg, project)
+}
+
+func projectFromURL(cfg *Config, raw string) (*Project, error) {
+	u, err := url.Parse(raw)
+	if err != nil {
+		return nil, fmt.Errorf("invalid url %q: %w", raw, err)
+	}
+
+	if u.Scheme != urlScheme {
+		return nil, fmt.Errorf("unsupported url scheme %q, expected %s://", u.Scheme, urlScheme)
+	}
+
+	action := u.Host
+	if action == "" {
+		action = strings.Trim(u.Opaque+u.Path, "/")
+	}
+	if action != "open" {
+		return nil, fmt.Errorf("unsupported url action %q", action)
+	}
+
+	query := u.Query()
+	switch {
+	case query.Get("path") != "":
+		return projectFromPath(query.Get("path"))
+	case query.Get("repo") != "":
+		return projectFromRepo(cfg, query.Get("repo"))
+	default:
+		return nil, errors.New("url must contain either a path or a repo parameter")
+	}
+}
+
+func projectFromPath(p string) (*Project, error) {
+	fullpath, err := normalizePath(p)
+	if err != nil {
+		return nil, err
+	}
+
+	info, err := os.Stat(fullpath)
+	if err != nil {
+		return nil, err
+	}
+	if !info.IsDir() {
+		return nil, fmt.Errorf("%s is not a directory", fullpath)
+	}
+
+	return newProject(filepath.Base(fullpath), fullpath)
+}
+
+// projectFromRepo looks for an already cloned checkout of repo among the
+// discovered projects, one with the same name whose origin is repo. When
+// there is none, the returned project points into a base chosen by the user
+// and is cloned by the ensure-clone action.
+func projectFromRepo(cfg *Config, repo string) (*Project, error) {
+	// the repo comes from a link anyone can craft, git would take it for
+	// an option
+	if strings.HasPrefix(repo, "-") {
+		return nil, fmt.Errorf("invalid repo %q", repo)
+	}
+	name := repoName(repo)
+	if name == "" {
+		return nil, fmt.Errorf("unable to determine project name from repo %q", repo)
+	}
+
+	projects, err := findProjectDirectories(cfg)
+	if err != nil {
+		return nil, err
+	}
+
+	want := normalizeRemoteURL(repo)
+	for _, p := range projects {
+		for _, checkout := range append([]*Project{p}, p.Clones...) {
+			if filepath.Base(checkout.FullPath) == name && normalizeRemoteURL(originURL(checkout.FullPath)) == want {
+				return checkout, nil
+			}
+		}
+	}
+
+	base, err := chooseBase(cfg, "clone", projects)
+	if err != nil {
+		return nil, err
+	}
+
+	dest := filepath.Join(base, name)
+	if _, err := os.Stat(dest); err == nil {
+		// ensure-clone would open it instead of cloning repo
+		origin := originURL(dest)
+		if origin == "" {
+			origin = "none"
+		}
+		return nil, fmt.Errorf("cannot clone %s into %s: it already exists and its origin is %s", repo, dest, origin)
+	}
+
+	project, err := newProject(name, dest)
+	if err != nil {
+		return nil, err
+	}
+	project.Repo = repo
+
+	return project, nil
+}
+
+func cloneRepo(repo, dest string) error {
+	fmt.Printf("Cloning %s into %s\n", repo, dest)
+	cmd := exec.Command("git", "clone", "--", repo, dest)
+	cmd.Stdin = os.Stdin
+	cmd.Stdout = os.Stdout
+	cmd.Stderr = os.Stderr
+	if err := runCommand(cmd); err != nil {
+		return fmt.Errorf("failed to clone %s: %w", repo, err)
+	}
+	return nil
+}
+
+// repoName returns the directory name git would use when cloning repo.
+func repoName(repo string) string {
+	repo = strings.TrimRight(repo, "/")
+	if i := strings.LastIndex(repo, ":"); i > strings.LastIndex(repo, "/") {
+		// scp-like syntax without a path separator, e.g. host:repo.git
+		repo = repo[i+1:]
+	}
+	return strings.TrimSuffix(path.Base(repo), ".git")
+}
+
+func registerURLHandler() error {
+	if runtime.GOOS != "linux" {
+		return fmt.Errorf("registering the %s:// handler is not supported on %s", urlScheme, runtime.GOOS)
+	}
+
+	exe, err := os.Executable()
+	if err != nil {
+		return err
+	}
+
+	dir, err := normalizePath("~/.local/share/applications")
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return err
+	}
+
+	desktopFile := filepath.Join(dir, "tmuxer-url.desktop")
+	if err := os.WriteFile(desktopFile, []byte(fmt.Sprintf(urlHandlerDesktopEntry, exe)), 0o644); err != nil {
+		return err
+	}
+
+	cmd := exec.Command("xdg-mime", "default", filepath.Base(desktopFile), "x-scheme-handler/"+urlScheme)
+	if output, err := commandCombinedOutput(cmd); err != nil {
+		return fmt.Errorf("failed to register url handler: %w: %s", err, output)
+	}
+
+	fmt.Printf("Registered %s as handler for %s:// links\n", exe, urlScheme)
+	return nil
+}
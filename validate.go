@@ -0,0 +1,526 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+	"time"
+
+	"gopkg.in/yaml.v3"
+)
+
+// ConfigError lists everything wrong with a config file.
+type ConfigError struct {
+	Path     string
+	Problems []string
+}
+
+func (e *ConfigError) Error() string {
+	return fmt.Sprintf("invalid config %s:\n  %s", e.Path, strings.Join(e.Problems, "\n  "))
+}
+
+// checkKnownFields reports mapping keys in node that do not correspond to a
+// yaml field of t, suggesting the closest known field. Keys starting with
+// x- at the top are left for anchors shared by the rest of the config.
+func checkKnownFields(node *yaml.Node, t reflect.Type, path string) []string {
+	return checkFields(node, t, path, make(map[*yaml.Node]bool))
+}
+
+// checkFields is checkKnownFields following aliases, checking the node of
+// an anchor only once.
+func checkFields(node *yaml.Node, t reflect.Type, path string, checked map[*yaml.Node]bool) []string {
+	for t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+	if node.Kind == yaml.AliasNode {
+		node = node.Alias
+	}
+	if checked[node] {
+		return nil
+	}
+	checked[node] = true
+
+	var problems []string
+	switch t.Kind() {
+	case reflect.Struct:
+		// structs such as Base also accept a scalar shorthand
+		if node.Kind != yaml.MappingNode {
+			return nil
+		}
+
+		fields := yamlFields(t)
+		names := make([]string, 0, len(fields))
+		for name := range fields {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+
+		for i := 0; i+1 < len(node.Content); i += 2 {
+			key, value := node.Content[i], node.Content[i+1]
+			if key.Value == "<<" {
+				// merge keys take the fields of one or more mappings
+				sources := []*yaml.Node{value}
+				if value.Kind == yaml.SequenceNode {
+					sources = value.Content
+				}
+				for _, src := range sources {
+					problems = append(problems, checkFields(src, t, path, checked)...)
+				}
+				continue
+			}
+			if path == "" && strings.HasPrefix(key.Value, "x-") {
+				continue
+			}
+			field, ok := fields[key.Value]
+			if !ok {
+				problem := fmt.Sprintf("line %d: unknown field %q in %s", key.Line, key.Value, describePath(path))
+				if suggestion := didYouMean(key.Value, names); suggestion != "" {
+					problem += fmt.Sprintf(", did you mean %q?", suggestion)
+				}
+				problems = append(problems, problem)
+				continue
+			}
+			problems = append(problems, checkFields(value, field, joinPath(path, key.Value), checked)...)
+		}
+	case reflect.Map:
+		if node.Kind != yaml.MappingNode {
+			return nil
+		}
+		for i := 0; i+1 < len(node.Content); i += 2 {
+			problems = append(problems, checkFields(node.Content[i+1], t.Elem(), joinPath(path, node.Content[i].Value), checked)...)
+		}
+	case reflect.Slice:
+		if node.Kind != yaml.SequenceNode {
+			return nil
+		}
+		for i, item := range node.Content {
+			problems = append(problems, checkFields(item, t.Elem(), fmt.Sprintf("%s[%d]", path, i), checked)...)
+		}
+	}
+	return problems
+}
+
+// yamlFields maps the yaml names of the fields of struct type t to their
+// types.
+func yamlFields(t reflect.Type) map[string]reflect.Type {
+	fields := make(map[string]reflect.Type)
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+		if !f.IsExported() {
+			continue
+		}
+
+		name, opts, _ := strings.Cut(f.Tag.Get("yaml"), ",")
+		if name == "-" {
+			continue
+		}
+		if strings.Contains(opts, "inline") {
+			for k, v := range yamlFields(f.Type) {
+				fields[k] = v
+			}
+			continue
+		}
+		if name == "" {
+			name = strings.ToLower(f.Name)
+		}
+		fields[name] = f.Type
+	}
+	return fields
+}
+
+func joinPath(path, key string) string {
+	if path == "" {
+		return key
+	}
+	return path + "." + key
+}
+
+func describePath(path string) string {
+	if path == "" {
+		return "config"
+	}
+	return path
+}
+
+// didYouMean returns the candidate closest to s, if any is close enough to
+// be a plausible typo.
+func didYouMean(s string, candidates []string) string {
+	best, bestDist := "", len(s)/3+2
+	for _, c := range candidates {
+		if d := levenshtein(s, c); d < bestDist {
+			best, bestDist = c, d
+		}
+	}
+	return best
+}
+
+func levenshtein(a, b string) int {
+	prev := make([]int, len(b)+1)
+	for j := range prev {
+		prev[j] = j
+	}
+	for i := 1; i <= len(a); i++ {
+		cur := make([]int, len(b)+1)
+		cur[0] = i
+		for j := 1; j <= len(b); j++ {
+			cost := 1
+			if a[i-1] == b[j-1] {
+				cost = 0
+			}
+			cur[j] = min(prev[j]+1, cur[j-1]+1, prev[j-1]+cost)
+		}
+		prev = cur
+	}
+	return prev[len(b)]
+}
+
+// validate checks references between config sections. root is the decoded
+// document, used to point at the offending lines.
+func (cfg *Config) validate(root *yaml.Node) []string {
+	var problems []string
+	report := func(format string, keys []string, args ...any) {
+		problem := fmt.Sprintf(format, args...)
+		if n := findNode(root, keys...); n != nil {
+			problem = fmt.Sprintf("line %d: %s", n.Line, problem)
+		}
+		problems = append(problems, problem)
+	}
+
+	checkChain := func(chain []string, keys ...string) {
+		for _, name := range chain {
+			if contains(actionNames(), name) {
+				continue
+			}
+			problem := "unknown action %q"
+			if suggestion := didYouMean(name, actionNames()); suggestion != "" {
+				problem += fmt.Sprintf(", did you mean %q?", suggestion)
+			}
+			report(problem, keys, name)
+		}
+	}
+	checkChain(cfg.Actions, "actions")
+	for _, name := range sortedKeys(cfg.Modes) {
+		checkChain(cfg.Modes[name], "modes", name)
+	}
+
+	if cfg.Layout != "" {
+		if _, ok := cfg.Layouts[cfg.Layout]; !ok {
+			problem := "layout %q is not defined under layouts"
+			if suggestion := didYouMean(cfg.Layout, sortedKeys(cfg.Layouts)); suggestion != "" {
+				problem += fmt.Sprintf(", did you mean %q?", suggestion)
+			}
+			report(problem, []string{"layout"}, cfg.Layout)
+		}
+	}
+
+	for _, name := range sortedKeys(cfg.Variants) {
+		v := cfg.Variants[name]
+		if v == nil || v.Layout == "" {
+			continue
+		}
+		if _, ok := cfg.Layouts[v.Layout]; !ok {
+			report("layout %q of variant %q is not defined under layouts", []string{"variants", name, "layout"}, v.Layout, name)
+		}
+	}
+
+	for _, name := range sortedKeys(cfg.Templates) {
+		t := cfg.Templates[name]
+		if t == nil || t.Command == "" {
+			report("template %q has no command", []string{"templates", name}, name)
+			continue
+		}
+		if _, ok := cfg.Layouts[t.Layout]; t.Layout != "" && !ok {
+			report("layout %q of template %q is not defined under layouts", []string{"templates", name, "layout"}, t.Layout, name)
+		}
+	}
+
+	for _, t := range sortedKeys(cfg.TypeLayouts) {
+		if !contains(projectTypeNames(), t) {
+			report("unknown project type %q, expected one of: %s", []string{"type_layouts"}, t, strings.Join(projectTypeNames(), ", "))
+			continue
+		}
+		if _, ok := cfg.Layouts[cfg.TypeLayouts[t]]; !ok {
+			report("layout %q of project type %q is not defined under layouts", []string{"type_layouts", t}, cfg.TypeLayouts[t], t)
+		}
+	}
+
+	for _, name := range sortedKeys(cfg.Repos) {
+		if cfg.Repos[name] == "" {
+			report("repo %q has no url", []string{"repos", name}, name)
+		}
+	}
+
+	for i, source := range cfg.Sources {
+		if source == nil {
+			report("source %d is empty", []string{"sources"}, i+1)
+			continue
+		}
+		if source.Type != "github" && source.Type != "gitlab" {
+			report("source %d has unknown type %q, expected github or gitlab", []string{"sources"}, i+1, source.Type)
+		}
+		if source.Owner == "" {
+			report("source %d has no owner", []string{"sources"}, i+1)
+		}
+	}
+
+	if cfg.IdleTimeout != "" {
+		if _, err := parseAge(cfg.IdleTimeout); err != nil {
+			report("%s", []string{"idle_timeout"}, err)
+		}
+	}
+
+	checkSecrets := func(env map[string]string, keys ...string) {
+		for _, name := range sortedKeys(env) {
+			ref, ok := strings.CutPrefix(env[name], secretPrefix)
+			if !ok || cfg.Secrets.Command != "" {
+				continue
+			}
+			known := false
+			for _, scheme := range []string{"op://", "pass:", "gopass:", "age:"} {
+				known = known || strings.HasPrefix(ref, scheme)
+			}
+			if !known {
+				report("secret %q of %s has no known scheme and secrets.command is not set", append(keys, name), ref, name)
+			}
+		}
+	}
+	checkSecrets(cfg.Env, "env")
+	for _, name := range sortedKeys(cfg.Projects) {
+		if p := cfg.Projects[name]; p != nil {
+			checkSecrets(p.Env, "projects", name, "env")
+		}
+	}
+	for _, name := range sortedKeys(cfg.Variants) {
+		if v := cfg.Variants[name]; v != nil {
+			checkSecrets(v.Env, "variants", name, "env")
+		}
+	}
+
+	if cfg.Hooks.Timeout != "" {
+		if _, err := time.ParseDuration(cfg.Hooks.Timeout); err != nil {
+			report("invalid hook timeout %q", []string{"hooks", "timeout"}, cfg.Hooks.Timeout)
+		}
+	}
+	hookKeys := []string{"on_create", "on_open"}
+	for key, hooks := range [][]Hook{cfg.Hooks.OnCreate, cfg.Hooks.OnOpen} {
+		for _, hook := range hooks {
+			if hook.Run == "" {
+				report("hook without run command", []string{"hooks", hookKeys[key]})
+			}
+			if hook.Timeout != "" {
+				if _, err := time.ParseDuration(hook.Timeout); err != nil {
+					report("invalid timeout %q of hook %q", []string{"hooks", hookKeys[key]}, hook.Timeout, hook.Run)
+				}
+			}
+		}
+	}
+
+	if cfg.HideStale != "" {
+		if _, err := parseAge(cfg.HideStale); err != nil {
+			report("%s", []string{"hide_stale"}, err)
+		}
+	}
+
+	if cfg.Sort != "" {
+		if _, ok := sortStrategies[cfg.Sort]; !ok {
+			report("unknown sort %q, expected one of: %s", []string{"sort"}, cfg.Sort, strings.Join(sortedKeys(sortStrategies), ", "))
+		}
+	}
+
+	for _, name := range cfg.Discovery {
+		if _, ok := projectSources[name]; !ok {
+			report("unknown project source %q, expected one of: %s", []string{"discovery"}, name, strings.Join(sortedKeys(projectSources), ", "))
+		}
+	}
+
+	for _, name := range sortedKeys(cfg.Layouts) {
+		if cfg.Layouts[name] == nil {
+			report("layout %s is empty", []string{"layouts", name}, name)
+			continue
+		}
+		for i, w := range cfg.Layouts[name].Windows {
+			if err := w.WaitFor.validate(); err != nil {
+				report("window %d of layout %q: %s", []string{"layouts", name}, i+1, name, err)
+			}
+			for j, p := range w.Panes {
+				if err := p.WaitFor.validate(); err != nil {
+					report("pane %d of window %d of layout %q: %s", []string{"layouts", name}, j+1, i+1, name, err)
+				}
+			}
+		}
+	}
+
+	if cfg.Monorepo.Depth < 0 {
+		report("monorepo depth must not be negative", []string{"monorepo", "depth"})
+	}
+
+	themeColors := map[string]string{
+		"name":    cfg.Theme.Name,
+		"path":    cfg.Theme.Path,
+		"marker":  cfg.Theme.Marker,
+		"session": cfg.Theme.Session,
+		"label":   cfg.Theme.Label,
+	}
+	for _, key := range sortedKeys(themeColors) {
+		if _, err := parseColor(themeColors[key]); err != nil {
+			report("%s", []string{"theme", key}, err)
+		}
+	}
+
+	if cfg.Backend != "" {
+		if _, ok := backends[cfg.Backend]; !ok {
+			report("unknown backend %q, expected one of: %s", []string{"backend"}, cfg.Backend, strings.Join(sortedKeys(backends), ", "))
+		}
+	}
+
+	if cfg.Symlinks != "" && cfg.Symlinks != symlinksFollow && cfg.Symlinks != symlinksSkip {
+		report("unknown symlinks %q, expected %s or %s", []string{"symlinks"}, cfg.Symlinks, symlinksFollow, symlinksSkip)
+	}
+
+	if cfg.ExistingSession != "" && !contains(existingBehaviors, cfg.ExistingSession) {
+		report("unknown existing_session %q, expected one of: %s", []string{"existing_session"}, cfg.ExistingSession, strings.Join(existingBehaviors, ", "))
+	}
+
+	if cfg.MaxResults < 0 {
+		report("max_results must not be negative", []string{"max_results"})
+	}
+
+	if cfg.Display != "" {
+		if _, err := parseDisplay(cfg.Display); err != nil {
+			report("%s", []string{"display"}, err)
+		}
+	}
+	if cfg.SessionNameTemplate != "" {
+		if _, err := parseSessionNameTemplate(cfg.SessionNameTemplate); err != nil {
+			report("%s", []string{"session_name_template"}, err)
+		}
+	}
+	if cfg.Terminal != "" {
+		if _, err := parseTerminal(cfg.Terminal); err != nil {
+			report("%s", []string{"terminal"}, err)
+		}
+	}
+	if cfg.StatusFormat != "" {
+		if _, err := parseStatusFormat(cfg.StatusFormat); err != nil {
+			report("%s", []string{"status_format"}, err)
+		}
+	}
+
+	for i, base := range cfg.ProjectBase {
+		if base == nil || base.Path == "" {
+			report("base entry %d has no path", []string{"base"}, i+1)
+			continue
+		}
+		if base.Watch && isRemoteBase(base.Path) {
+			report("remote base %s cannot be watched", []string{"base"}, base.Path)
+		}
+	}
+
+	for _, name := range sortedKeys(cfg.Aliases) {
+		if cfg.Aliases[name] == "" {
+			report("alias %s has no directory", []string{"alias", name}, name)
+		}
+	}
+
+	for _, key := range sortedKeys(cfg.PickerKeys) {
+		if err := checkPickerKey(key); err != nil {
+			report("%s", []string{"picker_keys"}, err)
+			continue
+		}
+		if _, _, err := parsePickerAction(cfg.PickerKeys[key]); err != nil {
+			report("%s", []string{"picker_keys", key}, err)
+		}
+	}
+
+	for _, task := range sortedKeys(cfg.Schedule) {
+		if _, ok := daemonTasks[task]; !ok {
+			report("unknown scheduled task %q", []string{"schedule"}, task)
+			continue
+		}
+		if _, err := parseSchedule(cfg.Schedule[task]); err != nil {
+			report("%s", []string{"schedule", task}, err)
+		}
+	}
+
+	for _, name := range sortedKeys(cfg.Workspaces) {
+		if len(cfg.Workspaces[name]) == 0 {
+			report("workspace %q has no projects", []string{"workspaces", name}, name)
+		}
+	}
+
+	return problems
+}
+
+// findNode returns the value node at the given mapping keys below root.
+func findNode(root *yaml.Node, keys ...string) *yaml.Node {
+	node := root
+	for _, key := range keys {
+		if node.Kind != yaml.MappingNode {
+			return nil
+		}
+		var next *yaml.Node
+		for i := 0; i+1 < len(node.Content); i += 2 {
+			if node.Content[i].Value == key {
+				next = node.Content[i+1]
+				break
+			}
+		}
+		if next == nil {
+			return nil
+		}
+		node = next
+	}
+	return node
+}
+
+func sortedKeys[V any](m map[string]V) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+const configUsage = "usage: tmuxer config validate [file] | migrate | edit | get <key> | set <key> [+] <value>"
+
+// runConfigCommand implements the config subcommands.
+func runConfigCommand(_ *Config, args []string) error {
+	if len(args) == 0 {
+		return errors.New(configUsage)
+	}
+
+	switch args[0] {
+	case "validate":
+		p := loadedConfigPath
+		if len(args) > 1 {
+			var err error
+			if p, err = resolveConfigPath(args[1]); err != nil {
+				return err
+			}
+		}
+		if _, err := loadConfig(p); err != nil {
+			return err
+		}
+		fmt.Printf("%s: ok\n", p)
+		return nil
+	case "migrate":
+		return migrateConfig()
+	case "edit":
+		return editConfig(loadedConfigPath)
+	case "get":
+		if len(args) != 2 {
+			return errors.New(configUsage)
+		}
+		return printConfigValue(loadedConfigPath, args[1])
+	case "set":
+		if len(args) < 3 || len(args) > 4 || (len(args) == 4 && args[2] != "+") {
+			return errors.New(configUsage)
+		}
+		return setConfigValue(loadedConfigPath, args[1], args[len(args)-1], len(args) == 4)
+	default:
+		return fmt.Errorf("unknown config command %q", args[0])
+	}
+}
@@ -0,0 +1,165 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"net"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// WaitFor holds back the command of a window or pane until its
+// prerequisites are ready, such as a dev server accepting connections.
+// All conditions given must hold.
+type WaitFor struct {
+	// Port is a port on localhost, or host:port, accepting connections.
+	Port string `yaml:"port,omitempty"`
+	// File must exist, relative to the directory of the pane.
+	File string `yaml:"file,omitempty"`
+	// Command must exit with 0.
+	Command string `yaml:"command,omitempty"`
+	// Timeout gives up waiting, 2m by default.
+	Timeout string `yaml:"timeout,omitempty"`
+}
+
+const (
+	defaultWaitTimeout = 2 * time.Minute
+	waitInterval       = 500 * time.Millisecond
+)
+
+func (w *WaitFor) empty() bool {
+	return w == nil || (w.Port == "" && w.File == "" && w.Command == "")
+}
+
+// args returns the conditions as arguments of tmuxer wait.
+func (w *WaitFor) args() []string {
+	var args []string
+	for _, c := range []struct{ key, value string }{
+		{"port", w.Port},
+		{"file", w.File},
+		{"command", w.Command},
+		{"timeout", w.Timeout},
+	} {
+		if c.value != "" {
+			args = append(args, c.key+"="+c.value)
+		}
+	}
+	return args
+}
+
+// wrap prefixes command with tmuxer wait, so the shell of the pane runs it
+// once the conditions hold without tmuxer blocking meanwhile.
+func (w *WaitFor) wrap(command string) (string, error) {
+	if w.empty() || command == "" {
+		return command, nil
+	}
+	exe, err := os.Executable()
+	if err != nil {
+		return "", err
+	}
+
+	words := []string{shellQuote(exe), "wait"}
+	for _, arg := range w.args() {
+		words = append(words, shellQuote(arg))
+	}
+	return strings.Join(words, " ") + " && " + command, nil
+}
+
+// validate checks the port and timeout of the conditions.
+func (w *WaitFor) validate() error {
+	if w == nil {
+		return nil
+	}
+	if w.Port != "" {
+		if _, err := waitAddress(w.Port); err != nil {
+			return err
+		}
+	}
+	if w.Timeout != "" {
+		if _, err := time.ParseDuration(w.Timeout); err != nil {
+			return fmt.Errorf("invalid timeout %q: %w", w.Timeout, err)
+		}
+	}
+	return nil
+}
+
+func waitAddress(port string) (string, error) {
+	addr := port
+	if !strings.Contains(addr, ":") {
+		addr = "localhost:" + addr
+	}
+	_, p, err := net.SplitHostPort(addr)
+	if err != nil {
+		return "", fmt.Errorf("invalid port %q: %w", port, err)
+	}
+	if n, err := strconv.Atoi(p); err != nil || n <= 0 || n > 65535 {
+		return "", fmt.Errorf("invalid port %q", port)
+	}
+	return addr, nil
+}
+
+// ready reports whether all conditions hold.
+func (w *WaitFor) ready() bool {
+	if w.Port != "" {
+		addr, _ := waitAddress(w.Port)
+		conn, err := net.DialTimeout("tcp", addr, waitInterval)
+		if err != nil {
+			return false
+		}
+		conn.Close()
+	}
+	if w.File != "" {
+		if _, err := os.Stat(w.File); err != nil {
+			return false
+		}
+	}
+	if w.Command != "" {
+		if err := runCommand(shellCommand(w.Command)); err != nil {
+			return false
+		}
+	}
+	return true
+}
+
+// runWaitCommand blocks until the conditions given as key=value arguments
+// hold, see WaitFor. It is run in panes by layouts using wait_for.
+func runWaitCommand(_ *Config, args []string) error {
+	if len(args) == 0 {
+		return errors.New("usage: tmuxer wait [port=<port>] [file=<path>] [command=<command>] [timeout=<duration>]")
+	}
+
+	w := &WaitFor{}
+	for _, arg := range args {
+		key, value, _ := strings.Cut(arg, "=")
+		switch key {
+		case "port":
+			w.Port = value
+		case "file":
+			w.File = value
+		case "command":
+			w.Command = value
+		case "timeout":
+			w.Timeout = value
+		default:
+			return fmt.Errorf("unknown condition %q", arg)
+		}
+	}
+	if err := w.validate(); err != nil {
+		return err
+	}
+	timeout := defaultWaitTimeout
+	if w.Timeout != "" {
+		timeout, _ = time.ParseDuration(w.Timeout)
+	}
+
+	deadline := time.Now().Add(timeout)
+	for !w.ready() {
+		if time.Now().After(deadline) {
+			return fmt.Errorf("gave up waiting for %s after %s", strings.Join(w.args(), " "), timeout)
+		}
+		time.Sleep(waitInterval)
+	}
+	return nil
+}
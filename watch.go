@@ -0,0 +1,175 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+)
+
+const watchedFile = "watched.json"
+
+// watchedBases returns the local bases with watch set.
+func (cfg *Config) watchedBases() []*Base {
+	var bases []*Base
+	for _, b := range cfg.ProjectBase {
+		if b.Watch && !isRemoteBase(b.Path) {
+			bases = append(bases, b)
+		}
+	}
+	return bases
+}
+
+// watchBases creates a detached session, with the layout picked for the
+// project as usual, for every project that appeared in a watched base since
+// the last check, such as one just cloned. The projects already there when
+// a base is first watched are only remembered.
+//
+// A new project is left alone until it settles, see settled, so a clone
+// still running is not opened half way. pending holds what settled saw of
+// such projects between the checks. Projects whose session could not be
+// created are not remembered either, so the next check tries again.
+func watchBases(cfg *Config, pending map[string]string) error {
+	bases := cfg.watchedBases()
+	if len(bases) == 0 {
+		return nil
+	}
+
+	known, err := loadWatched()
+	if err != nil {
+		return err
+	}
+
+	// a copy scanning only the watched bases, the daemon has no one to
+	// confirm new sessions
+	watchCfg := *cfg
+	watchCfg.ProjectBase = bases
+	watchCfg.ConfirmCreate = false
+	watchCfg.MaxResults = 0
+	found, err := discoverBases(&discovery{cfg: &watchCfg, projects: make(map[string]*Project)})
+	if err != nil {
+		return err
+	}
+
+	current := make(map[string][]string, len(bases))
+	for _, b := range bases {
+		current[b.Path] = []string{}
+	}
+	seen := make(map[string]bool, len(found))
+	var created []string
+	for _, project := range found {
+		base := project.Base.Path
+		seen[project.FullPath] = true
+		previous, watched := known[base]
+		if !watched || contains(previous, project.FullPath) {
+			current[base] = append(current[base], project.FullPath)
+			continue
+		}
+		if !settled(project.FullPath, pending) {
+			slog.Debug("waiting for new project to settle", "project", project.Name)
+			continue
+		}
+		delete(pending, project.FullPath)
+
+		slog.Info("new project in watched base", "project", project.Name, "path", project.FullPath)
+		if err := runActions(&watchCfg, project, actionAttach); err != nil {
+			slog.Error("failed to create session for new project", "project", project.Name, "err", err)
+			continue
+		}
+		current[base] = append(current[base], project.FullPath)
+		created = append(created, project.Session)
+	}
+	for p := range pending {
+		if !seen[p] {
+			delete(pending, p)
+		}
+	}
+	for _, paths := range current {
+		sort.Strings(paths)
+	}
+
+	if len(created) > 0 {
+		notify(cfg, "Created sessions for new projects %s", strings.Join(created, ", "))
+	}
+	return saveWatched(current)
+}
+
+// settled reports whether the new project at dir has stopped changing: its
+// .git/HEAD was last written more than a daemon tick ago, or dir looks the
+// same as on the previous check, which pending remembers.
+func settled(dir string, pending map[string]string) bool {
+	if info, err := os.Stat(filepath.Join(dir, ".git", "HEAD")); err == nil && time.Since(info.ModTime()) > daemonTick {
+		return true
+	}
+	state := projectState(dir)
+	if previous, ok := pending[dir]; ok && previous == state {
+		return true
+	}
+	pending[dir] = state
+	return false
+}
+
+// projectState describes dir by the modification times of the directory
+// and of what git writes while cloning into it.
+func projectState(dir string) string {
+	var b strings.Builder
+	for _, p := range []string{dir, filepath.Join(dir, ".git"), filepath.Join(dir, ".git", "HEAD"), filepath.Join(dir, ".git", "index")} {
+		if info, err := os.Stat(p); err == nil {
+			fmt.Fprintf(&b, "%d ", info.ModTime().UnixNano())
+		} else {
+			b.WriteString("- ")
+		}
+	}
+	return b.String()
+}
+
+func watchedPath() (string, error) {
+	dir, err := dataDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, watchedFile), nil
+}
+
+// loadWatched returns the project directories of each watched base seen by
+// the last check.
+func loadWatched() (map[string][]string, error) {
+	p, err := watchedPath()
+	if err != nil {
+		return nil, err
+	}
+	data, err := os.ReadFile(p)
+	if errors.Is(err, fs.ErrNotExist) {
+		return map[string][]string{}, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	known := make(map[string][]string)
+	if err := json.Unmarshal(data, &known); err != nil {
+		return nil, fmt.Errorf("corrupt watched projects file %s: %w", p, err)
+	}
+	return known, nil
+}
+
+func saveWatched(known map[string][]string) error {
+	p, err := watchedPath()
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(p), 0o755); err != nil {
+		return err
+	}
+	data, err := json.MarshalIndent(known, "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(p, data, 0o644)
+}
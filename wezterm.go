@@ -0,0 +1,160 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"os"
+	"strconv"
+)
+
+// weztermBackend opens each session as a wezterm workspace of the same name
+// through wezterm cli. Windows of a layout become tabs.
+type weztermBackend struct{}
+
+type weztermPane struct {
+	PaneID    int    `json:"pane_id"`
+	Workspace string `json:"workspace"`
+}
+
+func (weztermBackend) Command() string { return "wezterm" }
+
+// firstPane returns the id of the oldest pane of the workspace session, or
+// -1 when it does not exist.
+func (weztermBackend) firstPane(session string) (int, error) {
+	output, err := runBackend("wezterm", "cli", "list", "--format", "json")
+	if err != nil {
+		return -1, err
+	}
+	var panes []weztermPane
+	if err := json.Unmarshal([]byte(output), &panes); err != nil {
+		return -1, fmt.Errorf("failed to read wezterm panes: %w", err)
+	}
+	first := -1
+	for _, p := range panes {
+		if p.Workspace == session && (first == -1 || p.PaneID < first) {
+			first = p.PaneID
+		}
+	}
+	return first, nil
+}
+
+func (b weztermBackend) HasSession(name string) (bool, error) {
+	pane, err := b.firstPane(name)
+	return pane != -1, err
+}
+
+func (weztermBackend) NewSession(project *Project, dir string, command []string, env []string) (bool, error) {
+	args := []string{"cli", "spawn", "--new-window", "--workspace", project.Session, "--cwd", dir}
+	// wezterm cli spawn cannot set variables, so the first pane starts
+	// through env, and secrets through a file; panes created later on do
+	// not see them
+	public, secret := splitSecrets(env)
+	if len(env) > 0 || len(command) > 0 {
+		args = append(append(args, "--", "env"), public...)
+		switch {
+		case len(secret) > 0:
+			start, err := secretCommand(secret, command)
+			if err != nil {
+				return false, err
+			}
+			args = append(args, start...)
+		case len(command) > 0:
+			args = append(args, "sh", "-c", command[0])
+		default:
+			args = append(args, loginShell())
+		}
+	}
+	_, err := runBackend("wezterm", args...)
+	return err == nil, err
+}
+
+func (b weztermBackend) ApplyLayout(project *Project, layout *Layout) error {
+	first, err := b.firstPane(project.Session)
+	if err != nil {
+		return err
+	}
+	firstID := strconv.Itoa(first)
+
+	for i, w := range layout.Windows {
+		pane := firstID
+		if i == 0 {
+			if w.Dir != "" {
+				err = b.send(pane, "cd "+shellQuote(layoutDir(project, w.Dir)))
+			}
+		} else {
+			pane, err = runBackend("wezterm", "cli", "spawn", "--pane-id", firstID, "--cwd", layoutDir(project, w.Dir))
+		}
+		if err == nil && w.Name != "" {
+			_, err = runBackend("wezterm", "cli", "set-tab-title", "--pane-id", pane, w.Name)
+		}
+		if err != nil {
+			return err
+		}
+
+		command, err := w.WaitFor.wrap(w.Command)
+		if err != nil {
+			return err
+		}
+		if err := b.send(pane, command); err != nil {
+			return err
+		}
+
+		for _, p := range w.Panes {
+			split, err := runBackend("wezterm", "cli", "split-pane", "--pane-id", pane, "--cwd", layoutDir(project, p.Dir))
+			if err != nil {
+				return err
+			}
+			command, err := p.WaitFor.wrap(p.Command)
+			if err != nil {
+				return err
+			}
+			if err := b.send(split, command); err != nil {
+				return err
+			}
+		}
+		if w.Arrangement != "" {
+			slog.Debug("wezterm does not support arrangements", "window", w.Name, "arrangement", w.Arrangement)
+		}
+	}
+	return nil
+}
+
+func (weztermBackend) send(pane, command string) error {
+	if command == "" {
+		return nil
+	}
+	_, err := runBackend("wezterm", "cli", "send-text", "--pane-id", pane, "--no-paste", command+"\r")
+	return err
+}
+
+func (b weztermBackend) SendCommand(session, command string) error {
+	pane, err := b.firstPane(session)
+	if err != nil {
+		return err
+	}
+	return b.send(strconv.Itoa(pane), command)
+}
+
+// Attach focuses the first pane of the workspace. wezterm only shows the
+// windows of the active workspace, switching to it is left to wezterm's
+// SwitchToWorkspace key binding.
+func (b weztermBackend) Attach(session string) error {
+	pane, err := b.firstPane(session)
+	if err != nil {
+		return err
+	}
+	if pane == -1 {
+		return fmt.Errorf("workspace %s does not exist", session)
+	}
+	_, err = runBackend("wezterm", "cli", "activate-pane", "--pane-id", strconv.Itoa(pane))
+	return err
+}
+
+// loginShell returns the user's shell.
+func loginShell() string {
+	if shell := os.Getenv("SHELL"); shell != "" {
+		return shell
+	}
+	return "sh"
+}
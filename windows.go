@@ -0,0 +1,99 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/k1ng440/tmuxer/third_party/go-fuzzyfinder"
+)
+
+// tmuxWindow is a window of a running session.
+type tmuxWindow struct {
+	Session string
+	ID      string
+	Index   string
+	Name    string
+	Path    string
+}
+
+func (w *tmuxWindow) label() string {
+	return fmt.Sprintf("%s:%s %s", w.Session, w.Index, w.Name)
+}
+
+// listWindows returns the windows of session, or of all sessions when it is
+// empty.
+func listWindows(session string) ([]*tmuxWindow, error) {
+	args := []string{"-F", "#{session_name}\t#{window_id}\t#{window_index}\t#{window_name}\t#{pane_current_path}"}
+	if session == "" {
+		args = append(args, "-a")
+	} else {
+		args = append(args, "-t", session+":")
+	}
+	output, err := tmuxOutput("list-windows", args...)
+	if err != nil {
+		return nil, err
+	}
+
+	var windows []*tmuxWindow
+	for _, line := range strings.Split(output, "\n") {
+		fields := strings.SplitN(line, "\t", 5)
+		if len(fields) != 5 {
+			continue
+		}
+		windows = append(windows, &tmuxWindow{
+			Session: fields[0],
+			ID:      fields[1],
+			Index:   fields[2],
+			Name:    fields[3],
+			Path:    fields[4],
+		})
+	}
+	return windows, nil
+}
+
+// runWindowsCommand picks a window of the given session, or of any session,
+// and switches to it. The preview shows the content of the window's active
+// pane.
+func runWindowsCommand(_ *Config, args []string) error {
+	if len(args) > 1 {
+		return errors.New("usage: tmuxer windows [session]")
+	}
+	session := ""
+	if len(args) == 1 {
+		session = args[0]
+	}
+
+	windows, err := listWindows(session)
+	if err != nil {
+		return err
+	}
+	if len(windows) == 0 {
+		return errors.New("no windows found")
+	}
+
+	if !hasTerminal() {
+		return errNoTerminal
+	}
+	idx, err := fuzzyfinder.Find(
+		windows,
+		func(i int) string {
+			return windows[i].label()
+		},
+		fuzzyfinder.WithPreviewWindow(func(i, _, _ int) string {
+			if i == -1 {
+				return ""
+			}
+			content, _ := tmuxOutput("capture-pane", "-p", "-t", windows[i].ID)
+			return fmt.Sprintf("Path: %s\n\n%s", windows[i].Path, content)
+		}))
+	if err != nil {
+		return err
+	}
+
+	w := windows[idx]
+	if err := runTmuxCommand("select-window", "-t", w.ID); err != nil {
+		return err
+	}
+	return attachSession(w.Session)
+}
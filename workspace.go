@@ -0,0 +1,94 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+// runWorkspaceCommand opens every project of a workspace defined in the
+// config and switches to the first one. Without arguments it lists the
+// configured workspaces.
+func runWorkspaceCommand(cfg *Config, args []string) error {
+	if len(args) == 0 {
+		names := make([]string, 0, len(cfg.Workspaces))
+		for name := range cfg.Workspaces {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+		for _, name := range names {
+			fmt.Printf("%s: %s\n", name, strings.Join(cfg.Workspaces[name], ", "))
+		}
+		return nil
+	}
+
+	if len(args) != 1 {
+		return errors.New("usage: tmuxer workspace <name>")
+	}
+	if cfg.usesTmux() && withoutTmux() {
+		return errors.New("workspaces need tmux")
+	}
+
+	members, ok := cfg.Workspaces[args[0]]
+	if !ok {
+		return fmt.Errorf("workspace %q is not defined", args[0])
+	}
+	if len(members) == 0 {
+		return fmt.Errorf("workspace %q has no projects", args[0])
+	}
+
+	projects, err := findProjectDirectories(cfg)
+	if err != nil {
+		return err
+	}
+
+	workspace := make([]*Project, len(members))
+	for i, name := range members {
+		project := findProjectByName(projects, name)
+		if project == nil {
+			return fmt.Errorf("project %q of workspace %q not found", name, args[0])
+		}
+		workspace[i] = project
+	}
+
+	for _, project := range workspace {
+		if err := runActions(cfg, project, actionAttach); err != nil {
+			return fmt.Errorf("failed to start session for %s: %w", project.Name, err)
+		}
+	}
+
+	chain, err := cfg.actionChain()
+	if err != nil {
+		return err
+	}
+	if !contains(chain, actionAttach) || *detach {
+		notify(cfg, "Started workspace %s with %d projects", args[0], len(workspace))
+		return nil
+	}
+	return cfg.backend().Attach(workspace[0].Session)
+}
+
+// findProjectByName returns the project whose name, name behind its base
+// prefix or directory name equals name, in that order of preference. Among
+// projects matching equally, the one of the heaviest base wins.
+func findProjectByName(projects []*Project, name string) *Project {
+	matches := []func(p *Project) bool{
+		func(p *Project) bool { return p.Name == name },
+		func(p *Project) bool { return p.basePrefix() != "" && p.basePrefix()+p.Name == name },
+		func(p *Project) bool { return filepath.Base(p.FullPath) == name },
+	}
+	for _, match := range matches {
+		var found *Project
+		for _, p := range projects {
+			if match(p) && (found == nil || p.baseWeight() > found.baseWeight()) {
+				found = p
+			}
+		}
+		if found != nil {
+			return found
+		}
+	}
+	return nil
+}
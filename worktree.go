@@ -0,0 +1,133 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+)
+
+// runWorktreeCommand creates a git worktree of a project for a branch and
+// opens a session for it. The branch is created from HEAD unless it exists
+// locally or on a remote. A worktree that already exists is just opened, as
+// is another worktree the branch is checked out in.
+func runWorktreeCommand(cfg *Config, args []string) error {
+	if len(args) != 2 {
+		return errors.New("usage: tmuxer worktree <project> <branch>")
+	}
+
+	projects, err := findProjectDirectories(cfg)
+	if err != nil {
+		return err
+	}
+	project := findProjectByName(projects, args[0])
+	if project == nil {
+		return fmt.Errorf("project %q not found", args[0])
+	}
+	if project.Remote != nil || project.Repo == project.FullPath {
+		return fmt.Errorf("project %q is not checked out locally", args[0])
+	}
+
+	branch := args[1]
+	dest, err := cfg.worktreePath(project, branch)
+	if err != nil {
+		return err
+	}
+	if _, err := os.Stat(dest); err != nil {
+		// git checks a branch out in one worktree only
+		where, err := checkedOutIn(project.FullPath, branch)
+		if err != nil {
+			return err
+		}
+		switch {
+		case where == "":
+			if err := addWorktree(project.FullPath, dest, branch); err != nil {
+				return err
+			}
+		case samePath(where, project.FullPath):
+			return fmt.Errorf("branch %s is checked out in %s itself, open the project instead", branch, tildePath(project.FullPath))
+		default:
+			fmt.Printf("Branch %s is already checked out in %s\n", branch, tildePath(where))
+			dest = where
+		}
+	}
+
+	worktree, err := newProject(filepath.Base(dest), dest)
+	if err != nil {
+		return err
+	}
+	worktree.Base = project.Base
+	worktree.Parent = project
+	worktree.Markers = []string{worktreeMarker}
+	return runActions(cfg, worktree)
+}
+
+// worktreePath is where the worktree of project for branch goes: next to
+// the project, or in worktree_dir when set, named after the project and the
+// branch.
+func (cfg *Config) worktreePath(project *Project, branch string) (string, error) {
+	name := filepath.Base(project.FullPath) + "-" + strings.ReplaceAll(branch, "/", "-")
+	if cfg.WorktreeDir == "" {
+		return filepath.Join(filepath.Dir(project.FullPath), name), nil
+	}
+	dir, err := normalizePath(cfg.WorktreeDir)
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, name), nil
+}
+
+// checkedOutIn returns the worktree of the repository in dir that has branch
+// checked out, the main one included, or "" when none has.
+func checkedOutIn(dir, branch string) (string, error) {
+	output, err := commandOutput(exec.Command("git", "-C", dir, "worktree", "list", "--porcelain"))
+	if err != nil {
+		return "", fmt.Errorf("failed to list the worktrees of %s: %w", dir, err)
+	}
+	var worktree string
+	for _, line := range strings.Split(string(output), "\n") {
+		if p, ok := strings.CutPrefix(line, "worktree "); ok {
+			worktree = p
+		} else if line == "branch refs/heads/"+branch {
+			return worktree, nil
+		}
+	}
+	return "", nil
+}
+
+// samePath reports whether a and b are the same directory, following
+// symlinks.
+func samePath(a, b string) bool {
+	if ra, err := filepath.EvalSymlinks(a); err == nil {
+		a = ra
+	}
+	if rb, err := filepath.EvalSymlinks(b); err == nil {
+		b = rb
+	}
+	return filepath.Clean(a) == filepath.Clean(b)
+}
+
+// addWorktree adds a worktree of the repository in dir at dest checking out
+// branch, which is created when neither a local nor a remote branch of that
+// name exists. git tracks the remote branch when only that exists.
+func addWorktree(dir, dest, branch string) error {
+	refs, err := commandOutput(exec.Command("git", "-C", dir, "for-each-ref", "--format=%(refname)",
+		"refs/heads/"+branch, "refs/remotes/*/"+branch))
+	if err != nil {
+		return fmt.Errorf("failed to look up branch %s: %w", branch, err)
+	}
+
+	args := []string{"-C", dir, "worktree", "add", dest, branch}
+	if len(strings.TrimSpace(string(refs))) == 0 {
+		args = []string{"-C", dir, "worktree", "add", "-b", branch, dest}
+	}
+	fmt.Printf("Creating worktree %s on branch %s\n", dest, branch)
+	cmd := exec.Command("git", args...)
+	cmd.Stdout, cmd.Stderr = os.Stdout, os.Stderr
+	if err := runCommand(cmd); err != nil {
+		return fmt.Errorf("failed to create worktree: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,91 @@
+package main
+
+import (
+	"os/exec"
+	"runtime"
+	"strings"
+)
+
+// tmuxInWSL is set when tmux runs inside the default WSL distribution
+// rather than next to tmuxer, see useWSL.
+var tmuxInWSL bool
+
+// useWSL reports whether a windows build of tmuxer runs tmux through
+// wsl.exe: when the config asks for it, or when there is no tmux in the
+// PATH, as MSYS2 and Cygwin provide, but WSL is installed.
+func useWSL(cfg *Config) bool {
+	if runtime.GOOS != "windows" {
+		return false
+	}
+	if cfg.WSL {
+		return true
+	}
+	if _, err := exec.LookPath("tmux"); err == nil {
+		return false
+	}
+	_, err := exec.LookPath("wsl.exe")
+	return err == nil
+}
+
+// tmuxCommand returns the command running tmux with args on the selected
+// server, see tmuxSocketArgs. The directory of -c options is translated into
+// one tmux understands.
+func tmuxCommand(args ...string) *exec.Cmd {
+	args = append([]string(nil), args...)
+	for i := 1; i < len(args); i++ {
+		if args[i-1] == "-c" {
+			args[i] = tmuxPath(args[i])
+		}
+	}
+	args = append(tmuxSocketArgs(), args...)
+	if tmuxInWSL {
+		return exec.CommandContext(appContext, "wsl.exe", append([]string{"--exec", "tmux"}, args...)...)
+	}
+	return exec.CommandContext(appContext, "tmux", args...)
+}
+
+// tmuxPath translates the windows path p into the form tmux expects:
+// C:\src becomes /mnt/c/src inside WSL and C:/src for MSYS2 and Cygwin,
+// which accept windows paths with forward slashes. Other paths are returned
+// as is.
+func tmuxPath(p string) string {
+	if runtime.GOOS != "windows" {
+		return p
+	}
+	p = strings.ReplaceAll(p, `\`, "/")
+	if tmuxInWSL && hasDriveLetter(p) {
+		return "/mnt/" + strings.ToLower(p[:1]) + p[2:]
+	}
+	return p
+}
+
+// hostPath is the reverse of tmuxPath for paths reported by tmux, such as
+// #{pane_current_path}, turning /mnt/c/src, /cygdrive/c/src and the MSYS2
+// /c/src back into C:\src.
+func hostPath(p string) string {
+	if runtime.GOOS != "windows" {
+		return p
+	}
+	for _, prefix := range []string{"/mnt/", "/cygdrive/", "/"} {
+		rest, ok := strings.CutPrefix(p, prefix)
+		if !ok || len(rest) == 0 || !isDriveLetter(rest[0]) {
+			continue
+		}
+		drive := strings.ToUpper(rest[:1]) + ":"
+		if len(rest) == 1 {
+			return drive + `\`
+		}
+		if rest[1] == '/' {
+			return drive + strings.ReplaceAll(rest[1:], "/", `\`)
+		}
+	}
+	return p
+}
+
+func hasDriveLetter(p string) bool {
+	return len(p) >= 2 && isDriveLetter(p[0]) && p[1] == ':'
+}
+
+func isDriveLetter(c byte) bool {
+	return 'a' <= c && c <= 'z' || 'A' <= c && c <= 'Z'
+}
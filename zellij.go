@@ -0,0 +1,188 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"log/slog"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+)
+
+// zellijBackend opens sessions in zellij. Windows of a layout become tabs.
+type zellijBackend struct{}
+
+func (zellijBackend) Command() string { return "zellij" }
+
+func (zellijBackend) HasSession(name string) (bool, error) {
+	slog.Debug("running zellij", "args", []string{"list-sessions", "--short"})
+	output, err := commandOutput(exec.Command("zellij", "list-sessions", "--short"))
+	var exit *exec.ExitError
+	if errors.As(err, &exit) && len(strings.TrimSpace(string(output))) == 0 {
+		// zellij fails listing no sessions at all
+		return false, nil
+	}
+	if exit != nil && len(exit.Stderr) > 0 {
+		return false, fmt.Errorf("failed to list sessions: %s: %w", strings.TrimSpace(string(exit.Stderr)), err)
+	}
+	if err != nil {
+		return false, fmt.Errorf("failed to list sessions: %w", err)
+	}
+	for _, line := range strings.Split(string(output), "\n") {
+		if strings.TrimSpace(line) == name {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+func (b zellijBackend) NewSession(project *Project, dir string, command []string, env []string) (bool, error) {
+	slog.Debug("running zellij", "args", []string{"attach", "--create-background", project.Session}, "dir", dir)
+	cmd := exec.Command("zellij", "attach", "--create-background", project.Session)
+	cmd.Dir = dir
+	cmd.Env = append(os.Environ(), env...)
+	if output, err := commandCombinedOutput(cmd); err != nil {
+		return false, fmt.Errorf("failed to create session: %s: %w", strings.TrimSpace(string(output)), err)
+	}
+	if len(command) > 0 {
+		return true, b.SendCommand(project.Session, command[0])
+	}
+	return true, nil
+}
+
+// action runs a zellij action in session, which applies to its focused tab
+// and pane.
+func (zellijBackend) action(session string, args ...string) error {
+	_, err := runBackend("zellij", append([]string{"--session", session, "action"}, args...)...)
+	return err
+}
+
+// ApplyLayout does nothing, layouts are passed to zellij when the session
+// is created, see NewLayoutSession.
+func (zellijBackend) ApplyLayout(*Project, *Layout) error { return nil }
+
+// NewLayoutSession creates the session with layout converted to a zellij
+// KDL layout, or without one when layout is nil.
+func (b zellijBackend) NewLayoutSession(project *Project, dir string, env []string, layout *Layout) (bool, error) {
+	if layout == nil {
+		return b.NewSession(project, dir, nil, env)
+	}
+	kdl, err := zellijLayout(project, layout)
+	if err != nil {
+		return false, err
+	}
+	p := filepath.Join(runtimeDir(), "zellij-"+strings.ReplaceAll(project.Session, "/", "_")+".kdl")
+	if err := os.MkdirAll(runtimeDir(), 0o700); err != nil {
+		return false, err
+	}
+	if err := os.WriteFile(p, []byte(kdl), 0o600); err != nil {
+		return false, err
+	}
+
+	args := []string{"attach", "--create-background", project.Session, "options", "--default-layout", p, "--default-cwd", dir}
+	slog.Debug("running zellij", "args", args)
+	cmd := exec.Command("zellij", args...)
+	cmd.Dir = dir
+	cmd.Env = append(os.Environ(), env...)
+	if output, err := commandCombinedOutput(cmd); err != nil {
+		return false, fmt.Errorf("failed to create session: %s: %w", strings.TrimSpace(string(output)), err)
+	}
+	return true, nil
+}
+
+// zellijSplits maps tmux arrangements to the split direction of zellij
+// tabs. Other arrangements keep zellij's default.
+var zellijSplits = map[string]string{
+	"even-horizontal": "vertical",
+	"even-vertical":   "horizontal",
+}
+
+// zellijLayout returns layout as a zellij KDL layout with one tab per window.
+// Commands run in a shell that stays open once they exit, like in tmux.
+func zellijLayout(project *Project, layout *Layout) (string, error) {
+	var b strings.Builder
+	pane := func(name, dir, command string, waitFor *WaitFor) error {
+		command, err := waitFor.wrap(command)
+		if err != nil {
+			return err
+		}
+		fmt.Fprintf(&b, "        pane cwd=%s", kdlString(layoutDir(project, dir)))
+		if name != "" {
+			fmt.Fprintf(&b, " name=%s", kdlString(name))
+		}
+		if command == "" {
+			b.WriteString("\n")
+			return nil
+		}
+		fmt.Fprintf(&b, " command=\"sh\" {\n            args \"-c\" %s\n        }\n", kdlString(command+`; exec "${SHELL:-sh}"`))
+		return nil
+	}
+
+	b.WriteString("layout {\n")
+	b.WriteString("    default_tab_template {\n")
+	b.WriteString("        pane size=1 borderless=true {\n            plugin location=\"zellij:tab-bar\"\n        }\n")
+	b.WriteString("        children\n")
+	b.WriteString("        pane size=2 borderless=true {\n            plugin location=\"zellij:status-bar\"\n        }\n")
+	b.WriteString("    }\n")
+	for i, w := range layout.Windows {
+		b.WriteString("    tab")
+		if w.Name != "" {
+			fmt.Fprintf(&b, " name=%s", kdlString(w.Name))
+		}
+		if i == 0 {
+			b.WriteString(" focus=true")
+		}
+		if split, ok := zellijSplits[w.Arrangement]; ok {
+			fmt.Fprintf(&b, " split_direction=%q", split)
+		} else if w.Arrangement != "" {
+			slog.Debug("zellij does not support arrangement", "window", w.Name, "arrangement", w.Arrangement)
+		}
+		b.WriteString(" {\n")
+		if err := pane("", w.Dir, w.Command, w.WaitFor); err != nil {
+			return "", err
+		}
+		for _, p := range w.Panes {
+			if err := pane(p.Name, p.Dir, p.Command, p.WaitFor); err != nil {
+				return "", err
+			}
+		}
+		b.WriteString("    }\n")
+	}
+	b.WriteString("}\n")
+	return b.String(), nil
+}
+
+// kdlString quotes s as a KDL string.
+func kdlString(s string) string {
+	return `"` + strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`, "\t", `\t`).Replace(s) + `"`
+}
+
+// SendCommand types command into the focused pane of session and presses
+// enter.
+func (b zellijBackend) SendCommand(session, command string) error {
+	if command == "" {
+		return nil
+	}
+	if err := b.action(session, "write-chars", command); err != nil {
+		return err
+	}
+	return b.action(session, "write", "13")
+}
+
+// Attach attaches to session. Inside a session zellij switches to it
+// instead, which needs a zellij with the switch-session action; with older
+// ones the session is left for the session manager to switch to.
+func (zellijBackend) Attach(session string) error {
+	if os.Getenv("ZELLIJ") != "" {
+		if _, err := runBackend("zellij", "action", "switch-session", session); err != nil {
+			slog.Debug("failed to switch session", "session", session, "err", err)
+			fmt.Printf("zellij cannot switch sessions from the command line, switch to %s with the session manager (Ctrl o w)\n", session)
+		}
+		return nil
+	}
+	slog.Debug("running zellij", "args", []string{"attach", session})
+	cmd := exec.Command("zellij", "attach", session)
+	cmd.Stdin, cmd.Stdout, cmd.Stderr = os.Stdin, os.Stdout, os.Stderr
+	return runCommand(cmd)
+}